@@ -2,12 +2,19 @@ package main
 
 import (
 	"context"
+	"log"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/kubaliski/habit-tracker/backend/api"
+	"github.com/kubaliski/habit-tracker/backend/backup"
 	"github.com/kubaliski/habit-tracker/backend/database"
+	"github.com/kubaliski/habit-tracker/backend/models"
+	"github.com/kubaliski/habit-tracker/backend/reminders"
+	"github.com/kubaliski/habit-tracker/backend/server"
 	_ "github.com/mattn/go-sqlite3"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // App estructura principal de la aplicación
@@ -17,7 +24,15 @@ type App struct {
 	moodAPI     *api.MoodController
 	caffeineAPI *api.CaffeineController
 	statsAPI    *api.StatsController
+	exportAPI   *api.ExportController
+	backupAPI   *api.BackupController
+	serverAPI   *api.ServerController
+	settingsAPI *api.SettingsController
 	repository  database.Repository
+	scheduler   *reminders.Scheduler
+	backups     *backup.Manager
+	restServer  *server.Server
+	serverMu    sync.Mutex // Protege restServer frente a cambios de configuración concurrentes
 }
 
 // NewApp crea una nueva instancia de App
@@ -42,6 +57,13 @@ func NewApp() *App {
 	moodAPI := api.NewMoodController(repository)
 	caffeineAPI := api.NewCaffeineController(repository)
 	statsAPI := api.NewStatsController(repository)
+	exportAPI := api.NewExportController(repository, filepath.Join(dbDir, "exports"))
+
+	// Copias de seguridad en ~/.habit-tracker/backups
+	backups := backup.NewManager(repository, filepath.Join(dbDir, "backups"))
+	backupAPI := api.NewBackupController(backups)
+	serverAPI := api.NewServerController(repository)
+	settingsAPI := api.NewSettingsController(repository)
 
 	return &App{
 		repository:  repository,
@@ -49,23 +71,96 @@ func NewApp() *App {
 		moodAPI:     moodAPI,
 		caffeineAPI: caffeineAPI,
 		statsAPI:    statsAPI,
+		exportAPI:   exportAPI,
+		backupAPI:   backupAPI,
+		serverAPI:   serverAPI,
+		settingsAPI: settingsAPI,
+		backups:     backups,
 	}
 }
 
 // Startup se ejecuta cuando la aplicación arranca
 func (a *App) Startup(ctx context.Context) {
 	a.ctx = ctx
-	// Aquí puedes realizar otras inicializaciones
+
+	// Permitir que los controladores emitan eventos hacia el frontend
+	emitter := wailsEmitter{ctx: ctx}
+	a.caffeineAPI.Events = emitter
+	a.backupAPI.Events = emitter
+
+	// Arrancar el planificador de recordatorios
+	a.scheduler = reminders.NewScheduler(a.repository, reminders.NotifierFunc(func(n models.ReminderNotification) error {
+		emitter.Emit(api.EventHabitReminder, n)
+		return nil
+	}))
+	a.scheduler.Start()
+
+	// Copias de seguridad diarias con rotación diaria, semanal y mensual
+	a.backups.Start()
+
+	// Servidor REST local, si está habilitado en los ajustes
+	a.serverAPI.OnChange = a.applyServerSettings
+	settings, err := a.repository.GetServerSettings()
+	if err != nil {
+		log.Printf("Error al leer la configuración del servidor REST: %v", err)
+	} else if err := a.applyServerSettings(settings); err != nil {
+		log.Printf("Error al arrancar el servidor REST: %v", err)
+	}
 }
 
 // Shutdown se ejecuta cuando la aplicación se cierra
 func (a *App) Shutdown(ctx context.Context) {
+	// Detener los procesos en segundo plano antes de cerrar la base de datos
+	if a.scheduler != nil {
+		a.scheduler.Stop()
+	}
+	if a.backups != nil {
+		a.backups.Stop()
+	}
+	a.serverMu.Lock()
+	if a.restServer != nil {
+		a.restServer.Stop(ctx)
+	}
+	a.serverMu.Unlock()
+
 	// Cerrar la conexión a la base de datos
 	if a.repository != nil {
 		a.repository.Close()
 	}
 }
 
+// applyServerSettings detiene el servidor REST en marcha y lo vuelve a arrancar si está habilitado
+func (a *App) applyServerSettings(settings models.ServerSettings) error {
+	a.serverMu.Lock()
+	defer a.serverMu.Unlock()
+
+	if a.restServer != nil {
+		if err := a.restServer.Stop(a.ctx); err != nil {
+			return err
+		}
+		a.restServer = nil
+	}
+
+	if !settings.Enabled {
+		return nil
+	}
+
+	// Se reutilizan los controladores de la aplicación para que también emitan sus eventos
+	restServer := server.New(server.Controllers{
+		Habits:   a.habitsAPI,
+		Mood:     a.moodAPI,
+		Caffeine: a.caffeineAPI,
+		Stats:    a.statsAPI,
+	}, settings.Address, settings.Token)
+
+	if err := restServer.Start(); err != nil {
+		return err
+	}
+
+	a.restServer = restServer
+	return nil
+}
+
 // Método de ejemplo que podría ser llamado desde el frontend
 func (a *App) GetAppInfo() map[string]interface{} {
 	return map[string]interface{}{
@@ -74,3 +169,13 @@ func (a *App) GetAppInfo() map[string]interface{} {
 		"description": "Aplicación para seguimiento de hábitos, estado de ánimo y consumo de cafeína",
 	}
 }
+
+// wailsEmitter publica eventos mediante el runtime de Wails
+type wailsEmitter struct {
+	ctx context.Context
+}
+
+// Emit envía un evento al frontend
+func (e wailsEmitter) Emit(name string, data ...interface{}) {
+	runtime.EventsEmit(e.ctx, name, data...)
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/kubaliski/habit-tracker/backend/backup"
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+// BackupController maneja las copias de seguridad y la restauración de la base de datos
+type BackupController struct {
+	Backups *backup.Manager
+	Events  EventEmitter // Opcional, se asigna al arrancar la aplicación
+}
+
+// NewBackupController crea un nuevo controlador de copias de seguridad
+func NewBackupController(backups *backup.Manager) *BackupController {
+	return &BackupController{
+		Backups: backups,
+	}
+}
+
+// GetBackups obtiene las copias de seguridad disponibles, de la más reciente a la más antigua
+func (c *BackupController) GetBackups() ([]models.BackupInfo, error) {
+	return c.Backups.List()
+}
+
+// CreateBackup crea una copia de seguridad inmediata y aplica la rotación
+func (c *BackupController) CreateBackup() (models.BackupInfo, error) {
+	info, err := c.Backups.Snapshot()
+	if err != nil {
+		return models.BackupInfo{}, err
+	}
+
+	if _, err := c.Backups.Prune(); err != nil {
+		return models.BackupInfo{}, err
+	}
+
+	return info, nil
+}
+
+// RestoreBackup restaura la base de datos desde una copia de seguridad
+func (c *BackupController) RestoreBackup(path string) (models.RestoreResult, error) {
+	if path == "" {
+		return models.RestoreResult{}, errors.New("la ruta de la copia de seguridad es obligatoria")
+	}
+
+	result, err := c.Backups.Restore(path)
+	if err != nil {
+		return models.RestoreResult{}, err
+	}
+
+	// Los datos han cambiado por completo: el frontend debe recargarlos
+	emit(c.Events, EventDataRestored, result)
+
+	return result, nil
+}
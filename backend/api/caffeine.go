@@ -10,7 +10,8 @@ import (
 
 // CaffeineController maneja las operaciones relacionadas con el consumo de cafeína
 type CaffeineController struct {
-	Repo database.Repository
+	Repo   database.Repository
+	Events EventEmitter // Opcional, se asigna al arrancar la aplicación
 }
 
 // NewCaffeineController crea un nuevo controlador de consumo de cafeína
@@ -113,10 +114,10 @@ func (c *CaffeineController) GetCaffeineIntakeByDay(date string) ([]models.Caffe
 func (c *CaffeineController) GetCaffeineIntakeRange(startDate string, endDate string) ([]models.CaffeineIntake, error) {
 	// Si no se proporcionan fechas, usar valores predeterminados
 	if startDate == "" {
-		startDate = time.Now().AddDate(0, 0, -7).Format("2006-01-02")
+		startDate = logicalToday(c.Repo).AddDate(0, 0, -7).Format("2006-01-02")
 	}
 	if endDate == "" {
-		endDate = time.Now().Format("2006-01-02")
+		endDate = c.Repo.Today()
 	}
 
 	// Validar fechas
@@ -152,21 +153,143 @@ func (c *CaffeineController) GetDailyCaffeineTotal(date string) (map[string]inte
 	}, nil
 }
 
+// GetCaffeineSettings obtiene los parámetros del modelo de metabolismo de la cafeína
+func (c *CaffeineController) GetCaffeineSettings() (models.CaffeineSettings, error) {
+	return c.Repo.GetCaffeineSettings()
+}
+
+// UpdateCaffeineSettings actualiza los parámetros del modelo de metabolismo de la cafeína
+func (c *CaffeineController) UpdateCaffeineSettings(input models.UpdateCaffeineSettingsInput) (models.CaffeineSettings, error) {
+	if input.HalfLifeHours < 0 || input.HalfLifeHours > 24 {
+		return models.CaffeineSettings{}, errors.New("la vida media debe estar entre 0 y 24 horas")
+	}
+
+	if input.AbsorptionMinutes != nil && (*input.AbsorptionMinutes < 0 || *input.AbsorptionMinutes > 240) {
+		return models.CaffeineSettings{}, errors.New("el tiempo de absorción debe estar entre 0 y 240 minutos")
+	}
+
+	if input.Bedtime != "" {
+		_, err := time.Parse("15:04", input.Bedtime)
+		if err != nil {
+			return models.CaffeineSettings{}, errors.New("formato de hora de dormir inválido. Usar HH:MM")
+		}
+	}
+
+	if input.BedtimeThreshold < 0 {
+		return models.CaffeineSettings{}, errors.New("el umbral residual no puede ser negativo")
+	}
+
+	if err := c.Repo.UpdateCaffeineSettings(input); err != nil {
+		return models.CaffeineSettings{}, err
+	}
+
+	return c.Repo.GetCaffeineSettings()
+}
+
+// GetCaffeineLevel estima los mg de cafeína activos en un instante (ahora si no se indica)
+func (c *CaffeineController) GetCaffeineLevel(timestamp string) (map[string]interface{}, error) {
+	at := time.Now()
+	if timestamp != "" {
+		parsed, err := time.Parse(time.RFC3339, timestamp)
+		if err != nil {
+			return nil, errors.New("formato de timestamp inválido. Usar ISO 8601 (YYYY-MM-DDTHH:MM:SSZ)")
+		}
+		at = parsed
+	}
+
+	level, err := c.Repo.GetCaffeineLevelAt(at)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"timestamp": at.Format(time.RFC3339),
+		"level":     level,
+	}, nil
+}
+
+// GetCaffeineCurve obtiene la curva de cafeína activa de un día con una resolución en minutos
+func (c *CaffeineController) GetCaffeineCurve(date string, resolutionMinutes int) (models.CaffeineCurve, error) {
+	// Si no se proporciona una fecha, usar la fecha actual
+	if date == "" {
+		date = c.Repo.Today()
+	}
+
+	// Validar fecha
+	_, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return models.CaffeineCurve{}, errors.New("formato de fecha inválido. Usar YYYY-MM-DD")
+	}
+
+	if resolutionMinutes <= 0 {
+		resolutionMinutes = 15 // Valor por defecto
+	}
+
+	if resolutionMinutes > 240 {
+		return models.CaffeineCurve{}, errors.New("la resolución no puede superar los 240 minutos")
+	}
+
+	return c.Repo.GetCaffeineCurve(date, resolutionMinutes)
+}
+
+// GetTimeUntilCaffeineBelow estima cuánto falta para que la cafeína activa baje de un umbral en mg
+func (c *CaffeineController) GetTimeUntilCaffeineBelow(threshold float64) (models.CaffeineThresholdEstimate, error) {
+	if threshold <= 0 {
+		return models.CaffeineThresholdEstimate{}, errors.New("el umbral debe ser mayor que cero")
+	}
+
+	return c.Repo.EstimateCaffeineBelow(threshold, time.Now())
+}
+
+// PreviewCaffeineIntake proyecta la cafeína residual a la hora de dormir si se registrara este consumo.
+// Pensado para llamarse antes de CreateCaffeineIntake.
+func (c *CaffeineController) PreviewCaffeineIntake(input models.NewCaffeineIntakeInput) (models.BedtimeCaffeineProjection, error) {
+	// Validar campos requeridos
+	if input.BeverageID <= 0 {
+		return models.BedtimeCaffeineProjection{}, errors.New("el ID de bebida es obligatorio")
+	}
+
+	// Verificar que la bebida existe
+	_, err := c.Repo.GetCaffeineBeverage(input.BeverageID)
+	if err != nil {
+		return models.BedtimeCaffeineProjection{}, errors.New("la bebida especificada no existe")
+	}
+
+	if input.Amount <= 0 {
+		return models.BedtimeCaffeineProjection{}, errors.New("la cantidad debe ser mayor que cero")
+	}
+
+	// Validar formato de timestamp si se proporciona
+	if input.Timestamp != "" {
+		_, err := time.Parse(time.RFC3339, input.Timestamp)
+		if err != nil {
+			return models.BedtimeCaffeineProjection{}, errors.New("formato de timestamp inválido. Usar ISO 8601 (YYYY-MM-DDTHH:MM:SSZ)")
+		}
+	}
+
+	return c.Repo.ProjectBedtimeCaffeine(&input)
+}
+
+// GetBedtimeCaffeineStatus proyecta la cafeína residual a la hora de dormir con los consumos ya registrados
+func (c *CaffeineController) GetBedtimeCaffeineStatus() (models.BedtimeCaffeineProjection, error) {
+	return c.Repo.ProjectBedtimeCaffeine(nil)
+}
+
 // CreateCaffeineIntake crea un nuevo registro de consumo de cafeína
-func (c *CaffeineController) CreateCaffeineIntake(input models.NewCaffeineIntakeInput) (models.CaffeineIntake, error) {
+func (c *CaffeineController) CreateCaffeineIntake(input models.NewCaffeineIntakeInput) (models.CaffeineIntakeResult, error) {
 	// Validar campos requeridos
 	if input.BeverageID <= 0 {
-		return models.CaffeineIntake{}, errors.New("el ID de bebida es obligatorio")
+		return models.CaffeineIntakeResult{}, errors.New("el ID de bebida es obligatorio")
 	}
 
 	// Verificar que la bebida existe
 	_, err := c.Repo.GetCaffeineBeverage(input.BeverageID)
 	if err != nil {
-		return models.CaffeineIntake{}, errors.New("la bebida especificada no existe")
+		return models.CaffeineIntakeResult{}, errors.New("la bebida especificada no existe")
 	}
 
 	if input.Amount <= 0 {
-		return models.CaffeineIntake{}, errors.New("la cantidad debe ser mayor que cero")
+		return models.CaffeineIntakeResult{}, errors.New("la cantidad debe ser mayor que cero")
 	}
 
 	// Si no se proporciona una marca de tiempo, usar el momento actual
@@ -176,32 +299,48 @@ func (c *CaffeineController) CreateCaffeineIntake(input models.NewCaffeineIntake
 		// Validar formato de timestamp
 		_, err := time.Parse(time.RFC3339, input.Timestamp)
 		if err != nil {
-			return models.CaffeineIntake{}, errors.New("formato de timestamp inválido. Usar ISO 8601 (YYYY-MM-DDTHH:MM:SSZ)")
+			return models.CaffeineIntakeResult{}, errors.New("formato de timestamp inválido. Usar ISO 8601 (YYYY-MM-DDTHH:MM:SSZ)")
 		}
 	}
 
+	// Presupuesto antes del consumo para detectar límites superados
+	date, err := c.intakeDate(input.Timestamp)
+	if err != nil {
+		return models.CaffeineIntakeResult{}, err
+	}
+
+	before, err := c.Repo.GetCaffeineBudget(date)
+	if err != nil {
+		return models.CaffeineIntakeResult{}, err
+	}
+
 	id, err := c.Repo.CreateCaffeineIntake(input)
 	if err != nil {
-		return models.CaffeineIntake{}, err
+		return models.CaffeineIntakeResult{}, err
 	}
 
 	// Obtener el registro creado
-	return c.Repo.GetCaffeineIntake(id)
+	intake, err := c.Repo.GetCaffeineIntake(id)
+	if err != nil {
+		return models.CaffeineIntakeResult{}, err
+	}
+
+	return c.evaluateLimits(intake, 0, before)
 }
 
 // UpdateCaffeineIntake actualiza un registro de consumo de cafeína existente
-func (c *CaffeineController) UpdateCaffeineIntake(id int, input models.UpdateCaffeineIntakeInput) (models.CaffeineIntake, error) {
+func (c *CaffeineController) UpdateCaffeineIntake(id int, input models.UpdateCaffeineIntakeInput) (models.CaffeineIntakeResult, error) {
 	// Verificar que el registro existe
-	_, err := c.Repo.GetCaffeineIntake(id)
+	current, err := c.Repo.GetCaffeineIntake(id)
 	if err != nil {
-		return models.CaffeineIntake{}, errors.New("registro de consumo de cafeína no encontrado")
+		return models.CaffeineIntakeResult{}, errors.New("registro de consumo de cafeína no encontrado")
 	}
 
 	// Validar beverage_id si se proporciona
 	if input.BeverageID > 0 {
 		_, err := c.Repo.GetCaffeineBeverage(input.BeverageID)
 		if err != nil {
-			return models.CaffeineIntake{}, errors.New("la bebida especificada no existe")
+			return models.CaffeineIntakeResult{}, errors.New("la bebida especificada no existe")
 		}
 	}
 
@@ -209,16 +348,37 @@ func (c *CaffeineController) UpdateCaffeineIntake(id int, input models.UpdateCaf
 	if input.Timestamp != "" {
 		_, err := time.Parse(time.RFC3339, input.Timestamp)
 		if err != nil {
-			return models.CaffeineIntake{}, errors.New("formato de timestamp inválido. Usar ISO 8601 (YYYY-MM-DDTHH:MM:SSZ)")
+			return models.CaffeineIntakeResult{}, errors.New("formato de timestamp inválido. Usar ISO 8601 (YYYY-MM-DDTHH:MM:SSZ)")
 		}
 	}
 
+	// Presupuesto antes del cambio en la fecha final del consumo
+	timestamp := input.Timestamp
+	if timestamp == "" {
+		timestamp = current.Timestamp.Format(time.RFC3339)
+	}
+
+	date, err := c.intakeDate(timestamp)
+	if err != nil {
+		return models.CaffeineIntakeResult{}, err
+	}
+
+	before, err := c.Repo.GetCaffeineBudget(date)
+	if err != nil {
+		return models.CaffeineIntakeResult{}, err
+	}
+
 	if err := c.Repo.UpdateCaffeineIntake(id, input); err != nil {
-		return models.CaffeineIntake{}, err
+		return models.CaffeineIntakeResult{}, err
 	}
 
 	// Obtener el registro actualizado
-	return c.Repo.GetCaffeineIntake(id)
+	intake, err := c.Repo.GetCaffeineIntake(id)
+	if err != nil {
+		return models.CaffeineIntakeResult{}, err
+	}
+
+	return c.evaluateLimits(intake, current.TotalCaffeine, before)
 }
 
 // DeleteCaffeineIntake elimina un registro de consumo de cafeína
@@ -231,3 +391,107 @@ func (c *CaffeineController) DeleteCaffeineIntake(id int) error {
 
 	return c.Repo.DeleteCaffeineIntake(id)
 }
+
+// GetCaffeineLimits obtiene los límites de consumo de cafeína
+func (c *CaffeineController) GetCaffeineLimits() (models.CaffeineLimits, error) {
+	return c.Repo.GetCaffeineLimits()
+}
+
+// UpdateCaffeineLimits actualiza los límites de consumo de cafeína (0 desactiva un límite)
+func (c *CaffeineController) UpdateCaffeineLimits(input models.UpdateCaffeineLimitsInput) (models.CaffeineLimits, error) {
+	for _, limit := range []*float64{input.DailyLimit, input.IntakeLimit, input.WeeklyLimit} {
+		if limit != nil && *limit < 0 {
+			return models.CaffeineLimits{}, errors.New("los límites de cafeína no pueden ser negativos")
+		}
+	}
+
+	if err := c.Repo.UpdateCaffeineLimits(input); err != nil {
+		return models.CaffeineLimits{}, err
+	}
+
+	return c.Repo.GetCaffeineLimits()
+}
+
+// GetCaffeineBudget obtiene el consumo restante frente a los límites para una fecha
+func (c *CaffeineController) GetCaffeineBudget(date string) (models.CaffeineBudget, error) {
+	// Si no se proporciona una fecha, usar la fecha actual
+	if date == "" {
+		date = c.Repo.Today()
+	}
+
+	// Validar fecha
+	_, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return models.CaffeineBudget{}, errors.New("formato de fecha inválido. Usar YYYY-MM-DD")
+	}
+
+	return c.Repo.GetCaffeineBudget(date)
+}
+
+// evaluateLimits compara el presupuesto antes y después de un consumo y emite
+// un evento por cada límite que se ha cruzado con él. previous es la cafeína que
+// tenía el consumo antes del cambio (0 si es nuevo).
+func (c *CaffeineController) evaluateLimits(intake models.CaffeineIntake, previous float64, before models.CaffeineBudget) (models.CaffeineIntakeResult, error) {
+	after, err := c.Repo.GetCaffeineBudget(before.Date)
+	if err != nil {
+		return models.CaffeineIntakeResult{}, err
+	}
+
+	result := models.CaffeineIntakeResult{
+		CaffeineIntake: intake,
+		Budget:         after,
+		LimitsCrossed:  []string{},
+	}
+
+	for _, event := range crossedLimits(after.Limits, previous, intake.TotalCaffeine, before, after) {
+		event.Date = after.Date
+		event.IntakeID = intake.ID
+		result.LimitsCrossed = append(result.LimitsCrossed, event.Limit)
+		emit(c.Events, EventCaffeineLimitExceeded, event)
+	}
+
+	return result, nil
+}
+
+// crossedLimits devuelve los límites que pasan de respetarse a superarse con un cambio:
+// el de la toma compara su cafeína antes y después, y el diario y el semanal los totales
+// del presupuesto. Un límite que ya estaba superado no vuelve a avisar.
+func crossedLimits(limits models.CaffeineLimits, previous, total float64, before, after models.CaffeineBudget) []models.CaffeineLimitEvent {
+	crossed := []models.CaffeineLimitEvent{}
+
+	if limits.IntakeLimit > 0 && previous <= limits.IntakeLimit && total > limits.IntakeLimit {
+		crossed = append(crossed, models.CaffeineLimitEvent{
+			Limit: models.CaffeineLimitIntake,
+			Value: limits.IntakeLimit,
+			Total: total,
+		})
+	}
+
+	if limits.DailyLimit > 0 && before.DailyTotal <= limits.DailyLimit && after.DailyTotal > limits.DailyLimit {
+		crossed = append(crossed, models.CaffeineLimitEvent{
+			Limit: models.CaffeineLimitDaily,
+			Value: limits.DailyLimit,
+			Total: after.DailyTotal,
+		})
+	}
+
+	if limits.WeeklyLimit > 0 && before.WeeklyTotal <= limits.WeeklyLimit && after.WeeklyTotal > limits.WeeklyLimit {
+		crossed = append(crossed, models.CaffeineLimitEvent{
+			Limit: models.CaffeineLimitWeekly,
+			Value: limits.WeeklyLimit,
+			Total: after.WeeklyTotal,
+		})
+	}
+
+	return crossed
+}
+
+// intakeDate devuelve la fecha (YYYY-MM-DD) a la que se asigna un consumo
+func (c *CaffeineController) intakeDate(timestamp string) (string, error) {
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return "", errors.New("formato de timestamp inválido. Usar ISO 8601 (YYYY-MM-DDTHH:MM:SSZ)")
+	}
+
+	return c.Repo.DayOf(t), nil
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+func TestCrossedLimits(t *testing.T) {
+	limits := models.CaffeineLimits{IntakeLimit: 200, DailyLimit: 400, WeeklyLimit: 2000}
+	budget := func(daily, weekly float64) models.CaffeineBudget {
+		return models.CaffeineBudget{DailyTotal: daily, WeeklyTotal: weekly}
+	}
+
+	tests := []struct {
+		name     string
+		disabled bool
+		previous float64
+		total    float64
+		before   models.CaffeineBudget
+		after    models.CaffeineBudget
+		want     []string
+	}{
+		{
+			name:  "toma nueva dentro de los límites",
+			total: 150, before: budget(100, 500), after: budget(250, 650),
+		},
+		{
+			name:  "toma nueva que supera el límite por toma",
+			total: 250, before: budget(0, 0), after: budget(250, 250),
+			want: []string{models.CaffeineLimitIntake},
+		},
+		{
+			name:  "toma nueva justo en el límite",
+			total: 200, before: budget(200, 200), after: budget(400, 400),
+		},
+		{
+			name:     "editar una toma que ya superaba el límite no vuelve a avisar",
+			previous: 250, total: 300, before: budget(250, 250), after: budget(300, 300),
+		},
+		{
+			name:     "editar una toma para que cruce el límite",
+			previous: 150, total: 250, before: budget(150, 150), after: budget(250, 250),
+			want: []string{models.CaffeineLimitIntake},
+		},
+		{
+			name:     "bajar una toma por debajo del límite",
+			previous: 250, total: 150, before: budget(250, 250), after: budget(150, 150),
+		},
+		{
+			name:  "cruza el diario y el semanal a la vez",
+			total: 100, before: budget(350, 1950), after: budget(450, 2050),
+			want: []string{models.CaffeineLimitDaily, models.CaffeineLimitWeekly},
+		},
+		{
+			name:  "el diario ya estaba superado",
+			total: 100, before: budget(450, 900), after: budget(550, 1000),
+		},
+		{
+			name:  "las tres a la vez",
+			total: 250, before: budget(300, 1900), after: budget(550, 2150),
+			want: []string{models.CaffeineLimitIntake, models.CaffeineLimitDaily, models.CaffeineLimitWeekly},
+		},
+		{
+			name:     "límites desactivados",
+			disabled: true,
+			total:    1000, before: budget(0, 0), after: budget(1000, 1000),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			active := limits
+			if tt.disabled {
+				active = models.CaffeineLimits{}
+			}
+
+			var got []string
+			for _, event := range crossedLimits(active, tt.previous, tt.total, tt.before, tt.after) {
+				got = append(got, event.Limit)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("crossedLimits() = %v, se esperaba %v", got, tt.want)
+			}
+		})
+	}
+}
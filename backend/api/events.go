@@ -0,0 +1,20 @@
+package api
+
+// Nombres de los eventos emitidos hacia el frontend
+const (
+	EventCaffeineLimitExceeded = "caffeine:limit-exceeded"
+	EventHabitReminder         = "habit:reminder"
+	EventDataRestored          = "data:restored"
+)
+
+// EventEmitter publica eventos hacia el frontend (por ejemplo, eventos de runtime de Wails)
+type EventEmitter interface {
+	Emit(name string, data ...interface{})
+}
+
+// emit publica un evento si hay un emisor configurado
+func emit(emitter EventEmitter, name string, data ...interface{}) {
+	if emitter != nil {
+		emitter.Emit(name, data...)
+	}
+}
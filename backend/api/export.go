@@ -0,0 +1,130 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/database"
+	"github.com/kubaliski/habit-tracker/backend/export"
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+// ExportController maneja la exportación e importación de datos
+type ExportController struct {
+	Repo      database.Repository
+	ExportDir string // Carpeta predeterminada para las exportaciones
+}
+
+// NewExportController crea un nuevo controlador de exportación
+func NewExportController(repo database.Repository, exportDir string) *ExportController {
+	return &ExportController{
+		Repo:      repo,
+		ExportDir: exportDir,
+	}
+}
+
+// ExportData exporta los datos a un archivo JSON o a un zip de CSV
+func (c *ExportController) ExportData(options models.ExportOptions) (models.ExportResult, error) {
+	if err := validateExportOptions(&options); err != nil {
+		return models.ExportResult{}, err
+	}
+
+	// Si no se proporciona una ruta, usar la carpeta de exportaciones
+	if options.Path == "" {
+		if err := os.MkdirAll(c.ExportDir, 0755); err != nil {
+			return models.ExportResult{}, fmt.Errorf("error al crear la carpeta de exportaciones: %w", err)
+		}
+		options.Path = filepath.Join(c.ExportDir, export.FileName(options.Format, time.Now()))
+	}
+
+	doc, err := export.NewService(c.Repo).Collect(options)
+	if err != nil {
+		return models.ExportResult{}, err
+	}
+
+	file, err := os.Create(options.Path)
+	if err != nil {
+		return models.ExportResult{}, fmt.Errorf("error al crear el archivo de exportación: %w", err)
+	}
+	defer file.Close()
+
+	if options.Format == models.ExportFormatCSV {
+		err = export.WriteCSVZip(file, doc)
+	} else {
+		err = export.WriteJSON(file, doc)
+	}
+	if err != nil {
+		return models.ExportResult{}, err
+	}
+
+	if err := file.Close(); err != nil {
+		return models.ExportResult{}, fmt.Errorf("error al cerrar el archivo de exportación: %w", err)
+	}
+
+	return models.ExportResult{
+		Path:   options.Path,
+		Format: options.Format,
+		Counts: export.Counts(doc),
+	}, nil
+}
+
+// ImportData importa un archivo generado por ExportData resolviendo los conflictos con la
+// estrategia indicada. En modo de prueba devuelve el informe sin guardar cambios.
+func (c *ExportController) ImportData(options models.ImportOptions) (models.ImportReport, error) {
+	if options.Path == "" {
+		return models.ImportReport{}, errors.New("la ruta del archivo de importación es obligatoria")
+	}
+
+	if options.Strategy == "" {
+		options.Strategy = models.ImportStrategySkip // Valor por defecto
+	}
+
+	switch options.Strategy {
+	case models.ImportStrategySkip, models.ImportStrategyOverwrite, models.ImportStrategyMerge:
+	default:
+		return models.ImportReport{}, errors.New("estrategia de importación inválida. Usar skip, overwrite o merge")
+	}
+
+	doc, err := export.ReadFile(options.Path)
+	if err != nil {
+		return models.ImportReport{}, err
+	}
+
+	return c.Repo.ImportDocument(doc, options.Strategy, options.DryRun)
+}
+
+// validateExportOptions valida los filtros de exportación y aplica valores predeterminados
+func validateExportOptions(options *models.ExportOptions) error {
+	if options.Format == "" {
+		options.Format = models.ExportFormatJSON // Valor por defecto
+	}
+
+	if options.Format != models.ExportFormatJSON && options.Format != models.ExportFormatCSV {
+		return errors.New("formato de exportación inválido. Usar json o csv")
+	}
+
+	if options.StartDate != "" {
+		if _, err := time.Parse("2006-01-02", options.StartDate); err != nil {
+			return errors.New("formato de fecha inicial inválido. Usar YYYY-MM-DD")
+		}
+	}
+
+	if options.EndDate != "" {
+		if _, err := time.Parse("2006-01-02", options.EndDate); err != nil {
+			return errors.New("formato de fecha final inválido. Usar YYYY-MM-DD")
+		}
+	}
+
+	for _, domain := range options.Domains {
+		switch domain {
+		case models.ExportDomainHabits, models.ExportDomainMood, models.ExportDomainCaffeine:
+		default:
+			return fmt.Errorf("dominio de exportación desconocido: %s", domain)
+		}
+	}
+
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/kubaliski/habit-tracker/backend/database"
 	"github.com/kubaliski/habit-tracker/backend/models"
+	"github.com/kubaliski/habit-tracker/backend/schedule"
 )
 
 // HabitController maneja las operaciones relacionadas con hábitos
@@ -41,7 +42,7 @@ func (c *HabitController) CreateHabit(input models.NewHabitInput) (models.Habit,
 		return models.Habit{}, errors.New("el nombre es obligatorio")
 	}
 
-	if input.Frequency == "" {
+	if input.Frequency == "" && input.Schedule == nil {
 		return models.Habit{}, errors.New("la frecuencia es obligatoria")
 	}
 
@@ -50,6 +51,31 @@ func (c *HabitController) CreateHabit(input models.NewHabitInput) (models.Habit,
 		input.Goal = 1 // Valor por defecto
 	}
 
+	if input.Measure == "" {
+		input.Measure = models.MeasureBoolean // Valor por defecto
+	}
+
+	habit := models.Habit{Measure: input.Measure, Unit: input.Unit, Target: input.Target, Avoid: input.Avoid}
+	if err := validateHabitMeasure(&habit); err != nil {
+		return models.Habit{}, err
+	}
+	input.Unit, input.Target = habit.Unit, habit.Target
+
+	// Sin calendario, se deriva de la frecuencia. Los intervalos empiezan hoy por defecto.
+	habit.Frequency, habit.Goal = input.Frequency, input.Goal
+	if input.Schedule != nil {
+		habit.Schedule = *input.Schedule
+		if habit.Schedule.Type == models.ScheduleInterval && habit.Schedule.Anchor == "" {
+			habit.Schedule.Anchor = c.Repo.Today()
+		}
+	} else {
+		habit.Schedule = schedule.FromFrequency(input.Frequency, input.Goal)
+	}
+	if err := validateHabitSchedule(&habit); err != nil {
+		return models.Habit{}, err
+	}
+	input.Frequency, input.Goal, input.Schedule = habit.Frequency, habit.Goal, &habit.Schedule
+
 	id, err := c.Repo.CreateHabit(input)
 	if err != nil {
 		return models.Habit{}, err
@@ -59,13 +85,79 @@ func (c *HabitController) CreateHabit(input models.NewHabitInput) (models.Habit,
 	return c.Repo.GetHabit(id)
 }
 
-// UpdateHabit actualiza un hábito existente
+// UpdateHabit actualiza un hábito existente. Los cambios de meta rigen desde input.EffectiveDate
+// (hoy por defecto): los días anteriores se siguen evaluando con la meta que tenían.
 func (c *HabitController) UpdateHabit(id int, input models.UpdateHabitInput) (models.Habit, error) {
-	// Verificar que el hábito existe
-	_, err := c.Repo.GetHabit(id)
+	// Validar la fecha de efecto de los cambios de meta
+	if input.EffectiveDate == "" {
+		input.EffectiveDate = c.Repo.Today()
+	}
+	if _, err := time.Parse("2006-01-02", input.EffectiveDate); err != nil {
+		return models.Habit{}, errors.New("formato de fecha de efecto inválido. Usar YYYY-MM-DD")
+	}
+	if input.EffectiveDate > c.Repo.Today() {
+		return models.Habit{}, errors.New("los cambios de meta no pueden regir desde una fecha futura")
+	}
+
+	// Verificar que el hábito existe, con la meta vigente en la fecha de efecto
+	habit, err := c.Repo.GetHabitAt(id, input.EffectiveDate)
 	if err != nil {
 		return models.Habit{}, errors.New("hábito no encontrado")
 	}
+	if input.EffectiveDate < c.Repo.DayOf(habit.CreatedAt) {
+		return models.Habit{}, errors.New("los cambios de meta no pueden regir desde antes de crear el hábito")
+	}
+
+	if input.Target < 0 {
+		return models.Habit{}, errors.New("el objetivo no puede ser negativo")
+	}
+
+	// Validar la medición resultante de combinar los cambios con el hábito actual
+	if input.Measure != "" || input.Unit != "" || input.Target > 0 || input.Avoid != nil {
+		if input.Measure != "" {
+			habit.Measure = input.Measure
+		}
+		if input.Unit != "" {
+			habit.Unit = input.Unit
+		} else if input.Measure == models.MeasureDuration {
+			habit.Unit = models.DurationUnit
+		}
+		if input.Target > 0 {
+			habit.Target = input.Target
+		}
+		if input.Avoid != nil {
+			habit.Avoid = *input.Avoid
+		}
+		if err := validateHabitMeasure(&habit); err != nil {
+			return models.Habit{}, err
+		}
+		input.Unit, input.Target = habit.Unit, habit.Target
+	}
+
+	// Validar el calendario resultante: uno nuevo, el derivado de la nueva frecuencia o el
+	// actual con la nueva cuota
+	switch {
+	case input.Schedule != nil:
+		habit.Schedule = *input.Schedule
+		if input.Goal > 0 && habit.Schedule.Times == 0 {
+			habit.Schedule.Times = input.Goal
+		}
+	case input.Frequency != "" && input.Frequency != habit.Frequency:
+		goal := habit.Goal
+		if input.Goal > 0 {
+			goal = input.Goal
+		}
+		habit.Schedule = schedule.FromFrequency(input.Frequency, goal)
+	case input.Goal > 0 && schedule.IsQuota(habit.Schedule):
+		habit.Schedule.Times = input.Goal
+	}
+	if input.Goal > 0 {
+		habit.Goal = input.Goal
+	}
+	if err := validateHabitSchedule(&habit); err != nil {
+		return models.Habit{}, err
+	}
+	input.Frequency, input.Goal, input.Schedule = habit.Frequency, habit.Goal, &habit.Schedule
 
 	if err := c.Repo.UpdateHabit(id, input); err != nil {
 		return models.Habit{}, err
@@ -75,6 +167,17 @@ func (c *HabitController) UpdateHabit(id int, input models.UpdateHabitInput) (mo
 	return c.Repo.GetHabit(id)
 }
 
+// GetHabitGoalHistory obtiene el historial de metas de un hábito, de la más antigua a la actual
+func (c *HabitController) GetHabitGoalHistory(habitID int) ([]models.HabitGoalVersion, error) {
+	// Verificar que el hábito existe
+	_, err := c.Repo.GetHabit(habitID)
+	if err != nil {
+		return nil, errors.New("hábito no encontrado")
+	}
+
+	return c.Repo.GetHabitGoalVersions(habitID)
+}
+
 // DeleteHabit elimina un hábito
 func (c *HabitController) DeleteHabit(id int) error {
 	// Verificar que el hábito existe
@@ -86,17 +189,103 @@ func (c *HabitController) DeleteHabit(id int) error {
 	return c.Repo.DeleteHabit(id)
 }
 
+// errAvoidHabitLog se devuelve al registrar o marcar un hábito a evitar, que solo admite recaídas
+var errAvoidHabitLog = errors.New("los hábitos a evitar no se completan: se registran sus recaídas")
+
+// validateHabitMeasure comprueba el tipo de medición de un hábito y completa su unidad. Los
+// hábitos cuantitativos necesitan un objetivo diario; los de duración se miden en minutos. Los
+// hábitos a evitar solo registran recaídas, así que son siempre de sí/no.
+func validateHabitMeasure(habit *models.Habit) error {
+	if habit.Avoid && habit.Measure != models.MeasureBoolean {
+		return errors.New("los hábitos a evitar no admiten otro tipo de medición que boolean")
+	}
+
+	switch habit.Measure {
+	case models.MeasureBoolean, models.MeasureCount:
+		// El objetivo de estos hábitos es Goal (veces)
+		habit.Target = 0
+		return nil
+	case models.MeasureQuantity:
+		if habit.Unit == "" {
+			return errors.New("los hábitos de cantidad necesitan una unidad")
+		}
+	case models.MeasureDuration:
+		if habit.Unit != "" && habit.Unit != models.DurationUnit {
+			return errors.New("los hábitos de duración se miden en minutos (min)")
+		}
+		habit.Unit = models.DurationUnit
+	default:
+		return errors.New("tipo de medición inválido. Usar boolean, count, quantity o duration")
+	}
+
+	if habit.Target <= 0 {
+		return errors.New("los hábitos cuantitativos necesitan un objetivo diario mayor que 0")
+	}
+
+	return nil
+}
+
+// validateHabitSchedule comprueba el calendario de un hábito y deriva de él la frecuencia. En
+// las cuotas el número de días es el objetivo del hábito; los hábitos a evitar se evalúan cada
+// día, así que su calendario es siempre diario.
+func validateHabitSchedule(habit *models.Habit) error {
+	if schedule.IsQuota(habit.Schedule) && habit.Schedule.Times == 0 {
+		habit.Schedule.Times = habit.Goal
+	}
+
+	if err := schedule.Validate(habit.Schedule); err != nil {
+		return err
+	}
+
+	if habit.Avoid && habit.Schedule.Type != models.ScheduleDaily {
+		return errors.New("los hábitos a evitar solo admiten el calendario diario")
+	}
+
+	habit.Frequency = schedule.Frequency(habit.Schedule)
+	if schedule.IsQuota(habit.Schedule) {
+		habit.Goal = habit.Schedule.Times
+	}
+
+	return nil
+}
+
+// normalizeHabitLog completa un registro según la medición del hábito: en los cuantitativos
+// el valor decide si se cumple el día; en los de recuento se cumple al llegar al objetivo de
+// veces. Fuera de los cuantitativos el valor es siempre el número de veces.
+func normalizeHabitLog(habit models.Habit, input *models.NewHabitLogInput) error {
+	if habit.Avoid {
+		return errAvoidHabitLog
+	}
+
+	if input.Count < 0 || input.Value < 0 {
+		return errors.New("el registro no puede tener valores negativos")
+	}
+
+	if !habit.Quantitative() {
+		if input.Count == 0 && input.Value > 0 {
+			input.Count = int(input.Value)
+		}
+		input.Value = float64(input.Count)
+	}
+
+	if met, decided := habit.MeetsGoal(input.Count, input.Value); decided {
+		input.Completed = met
+	}
+
+	return nil
+}
+
 // LogHabit registra una entrada para un hábito
 func (c *HabitController) LogHabit(habitID int, input models.NewHabitLogInput) error {
 	// Verificar que el hábito existe
-	_, err := c.Repo.GetHabit(habitID)
+	habit, err := c.Repo.GetHabit(habitID)
 	if err != nil {
 		return errors.New("hábito no encontrado")
 	}
 
 	// Si no se proporciona una fecha, usar la fecha actual
 	if input.Date == "" {
-		input.Date = time.Now().Format("2006-01-02")
+		input.Date = c.Repo.Today()
 	}
 
 	// Validar fecha
@@ -105,6 +294,16 @@ func (c *HabitController) LogHabit(habitID int, input models.NewHabitLogInput) e
 		return errors.New("formato de fecha inválido. Usar YYYY-MM-DD")
 	}
 
+	// El registro se evalúa con la meta vigente ese día
+	habit, err = c.Repo.GetHabitAt(habitID, input.Date)
+	if err != nil {
+		return err
+	}
+
+	if err := normalizeHabitLog(habit, &input); err != nil {
+		return err
+	}
+
 	return c.Repo.LogHabit(habitID, input)
 }
 
@@ -118,10 +317,10 @@ func (c *HabitController) GetHabitLogs(habitID int, startDate string, endDate st
 
 	// Si no se proporcionan fechas, usar valores predeterminados
 	if startDate == "" {
-		startDate = time.Now().AddDate(0, 0, -30).Format("2006-01-02")
+		startDate = logicalToday(c.Repo).AddDate(0, 0, -30).Format("2006-01-02")
 	}
 	if endDate == "" {
-		endDate = time.Now().Format("2006-01-02")
+		endDate = c.Repo.Today()
 	}
 
 	// Validar fechas
@@ -148,7 +347,7 @@ func (c *HabitController) CompleteHabit(habitID int, date string) error {
 
 	// Si no se proporciona una fecha, usar la fecha actual
 	if date == "" {
-		date = time.Now().Format("2006-01-02")
+		date = c.Repo.Today()
 	}
 
 	// Validar fecha
@@ -157,13 +356,27 @@ func (c *HabitController) CompleteHabit(habitID int, date string) error {
 		return errors.New("formato de fecha inválido. Usar YYYY-MM-DD")
 	}
 
-	// Crear entrada de registro
+	// La meta es la vigente ese día
+	habit, err = c.Repo.GetHabitAt(habitID, date)
+	if err != nil {
+		return err
+	}
+
+	// Crear entrada de registro. Los hábitos cuantitativos se completan con el objetivo diario.
 	logEntry := models.NewHabitLogInput{
 		Date:      date,
 		Completed: true,
 		Count:     habit.Goal,
 		Notes:     "",
 	}
+	if habit.Quantitative() {
+		logEntry.Count = 0
+		logEntry.Value = habit.Target
+	}
+
+	if err := normalizeHabitLog(habit, &logEntry); err != nil {
+		return err
+	}
 
 	return c.Repo.LogHabit(habitID, logEntry)
 }
@@ -171,14 +384,17 @@ func (c *HabitController) CompleteHabit(habitID int, date string) error {
 // UncompleteHabit marca un hábito como no completado para una fecha específica
 func (c *HabitController) UncompleteHabit(habitID int, date string) error {
 	// Verificar que el hábito existe
-	_, err := c.Repo.GetHabit(habitID)
+	habit, err := c.Repo.GetHabit(habitID)
 	if err != nil {
 		return errors.New("hábito no encontrado")
 	}
+	if habit.Avoid {
+		return errAvoidHabitLog
+	}
 
 	// Si no se proporciona una fecha, usar la fecha actual
 	if date == "" {
-		date = time.Now().Format("2006-01-02")
+		date = c.Repo.Today()
 	}
 
 	// Validar fecha
@@ -192,8 +408,328 @@ func (c *HabitController) UncompleteHabit(habitID int, date string) error {
 		Date:      date,
 		Completed: false,
 		Count:     0,
+		Value:     0,
 		Notes:     "",
 	}
 
 	return c.Repo.LogHabit(habitID, logEntry)
 }
+
+// GetDueHabits obtiene los hábitos activos que tocan en una fecha (hoy si se omite) según su
+// calendario, con lo registrado ese día. En las cuotas un hábito deja de tocar cuando ya se ha
+// cumplido el número de días del período; el día que la completa sigue apareciendo, cumplido.
+// Los hábitos a evitar tocan todos los días y se cumplen si no hay recaídas. Un día justificado
+// no toca ningún hábito al que afecte.
+func (c *HabitController) GetDueHabits(date string) ([]models.DueHabit, error) {
+	// Si no se proporciona una fecha, usar la fecha actual
+	if date == "" {
+		date = c.Repo.Today()
+	}
+
+	// Validar fecha
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return nil, errors.New("formato de fecha inválido. Usar YYYY-MM-DD")
+	}
+
+	habits, err := c.Repo.GetAllHabits()
+	if err != nil {
+		return nil, err
+	}
+
+	due := []models.DueHabit{}
+	for _, habit := range habits {
+		entry, isDue, err := c.Repo.GetDueHabit(habit.ID, date)
+		if err != nil {
+			return nil, err
+		}
+		if isDue {
+			due = append(due, entry)
+		}
+	}
+
+	return due, nil
+}
+
+// RecordSlip registra una recaída en un hábito a evitar
+func (c *HabitController) RecordSlip(habitID int, input models.NewHabitSlipInput) (models.HabitSlip, error) {
+	// Verificar que el hábito existe
+	habit, err := c.Repo.GetHabit(habitID)
+	if err != nil {
+		return models.HabitSlip{}, errors.New("hábito no encontrado")
+	}
+	if !habit.Avoid {
+		return models.HabitSlip{}, errors.New("solo se registran recaídas en hábitos a evitar")
+	}
+
+	// Si no se proporciona una marca de tiempo, usar el momento actual
+	if input.Timestamp == "" {
+		input.Timestamp = time.Now().Format(time.RFC3339)
+	} else {
+		// Validar formato de timestamp
+		timestamp, err := time.Parse(time.RFC3339, input.Timestamp)
+		if err != nil {
+			return models.HabitSlip{}, errors.New("formato de timestamp inválido. Usar ISO 8601 (YYYY-MM-DDTHH:MM:SSZ)")
+		}
+		if timestamp.After(time.Now()) {
+			return models.HabitSlip{}, errors.New("no se puede registrar una recaída en el futuro")
+		}
+	}
+
+	id, err := c.Repo.CreateHabitSlip(habitID, input)
+	if err != nil {
+		return models.HabitSlip{}, err
+	}
+
+	// Obtener la recaída creada
+	return c.Repo.GetHabitSlip(id)
+}
+
+// GetHabitSlips obtiene las recaídas de un hábito en un rango de fechas
+func (c *HabitController) GetHabitSlips(habitID int, startDate string, endDate string) ([]models.HabitSlip, error) {
+	// Verificar que el hábito existe
+	_, err := c.Repo.GetHabit(habitID)
+	if err != nil {
+		return nil, errors.New("hábito no encontrado")
+	}
+
+	// Si no se proporcionan fechas, usar valores predeterminados
+	if startDate == "" {
+		startDate = logicalToday(c.Repo).AddDate(0, 0, -30).Format("2006-01-02")
+	}
+	if endDate == "" {
+		endDate = c.Repo.Today()
+	}
+
+	// Validar fechas
+	_, err = time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, errors.New("formato de fecha inicial inválido. Usar YYYY-MM-DD")
+	}
+
+	_, err = time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil, errors.New("formato de fecha final inválido. Usar YYYY-MM-DD")
+	}
+
+	return c.Repo.GetHabitSlips(habitID, startDate, endDate)
+}
+
+// DeleteHabitSlip elimina una recaída
+func (c *HabitController) DeleteHabitSlip(id int) error {
+	// Verificar que la recaída existe
+	_, err := c.Repo.GetHabitSlip(id)
+	if err != nil {
+		return errors.New("recaída no encontrada")
+	}
+
+	return c.Repo.DeleteHabitSlip(id)
+}
+
+// CreateExcuse justifica un día o un rango de días, para un hábito o para todos si no se indica
+// ninguno. Los días justificados no rompen las rachas ni cuentan en el cumplimiento.
+func (c *HabitController) CreateExcuse(input models.NewExcuseInput) (models.Excuse, error) {
+	// Verificar que el hábito existe
+	if input.HabitID != nil {
+		if _, err := c.Repo.GetHabit(*input.HabitID); err != nil {
+			return models.Excuse{}, errors.New("hábito no encontrado")
+		}
+	}
+
+	// Validar fechas (un solo día si no se indica el final)
+	if input.EndDate == "" {
+		input.EndDate = input.StartDate
+	}
+
+	_, err := time.Parse("2006-01-02", input.StartDate)
+	if err != nil {
+		return models.Excuse{}, errors.New("formato de fecha inicial inválido. Usar YYYY-MM-DD")
+	}
+
+	_, err = time.Parse("2006-01-02", input.EndDate)
+	if err != nil {
+		return models.Excuse{}, errors.New("formato de fecha final inválido. Usar YYYY-MM-DD")
+	}
+
+	if input.EndDate < input.StartDate {
+		return models.Excuse{}, errors.New("la fecha final no puede ser anterior a la inicial")
+	}
+
+	switch input.Reason {
+	case "":
+		input.Reason = models.ExcuseOther // Valor por defecto
+	case models.ExcuseVacation, models.ExcuseSick, models.ExcuseRest, models.ExcuseOther:
+	default:
+		return models.Excuse{}, errors.New("motivo inválido. Usar vacation, sick, rest u other")
+	}
+
+	id, err := c.Repo.CreateExcuse(input)
+	if err != nil {
+		return models.Excuse{}, err
+	}
+
+	// Obtener el día justificado creado
+	return c.Repo.GetExcuse(id)
+}
+
+// GetExcuses obtiene los días justificados que se solapan con un rango de fechas. Con un
+// hábito (habitID > 0) incluye solo los suyos y los que afectan a todos los hábitos.
+func (c *HabitController) GetExcuses(habitID int, startDate string, endDate string) ([]models.Excuse, error) {
+	// Verificar que el hábito existe
+	if habitID > 0 {
+		if _, err := c.Repo.GetHabit(habitID); err != nil {
+			return nil, errors.New("hábito no encontrado")
+		}
+	}
+
+	// Si no se proporcionan fechas, usar valores predeterminados
+	if startDate == "" {
+		startDate = logicalToday(c.Repo).AddDate(0, 0, -30).Format("2006-01-02")
+	}
+	if endDate == "" {
+		endDate = logicalToday(c.Repo).AddDate(0, 0, 30).Format("2006-01-02")
+	}
+
+	// Validar fechas
+	_, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, errors.New("formato de fecha inicial inválido. Usar YYYY-MM-DD")
+	}
+
+	_, err = time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil, errors.New("formato de fecha final inválido. Usar YYYY-MM-DD")
+	}
+
+	return c.Repo.GetExcuses(habitID, startDate, endDate)
+}
+
+// DeleteExcuse elimina un día justificado
+func (c *HabitController) DeleteExcuse(id int) error {
+	// Verificar que el día justificado existe
+	_, err := c.Repo.GetExcuse(id)
+	if err != nil {
+		return errors.New("día justificado no encontrado")
+	}
+
+	return c.Repo.DeleteExcuse(id)
+}
+
+// GetHabitReminders obtiene los recordatorios de un hábito
+func (c *HabitController) GetHabitReminders(habitID int) ([]models.HabitReminder, error) {
+	// Verificar que el hábito existe
+	_, err := c.Repo.GetHabit(habitID)
+	if err != nil {
+		return nil, errors.New("hábito no encontrado")
+	}
+
+	return c.Repo.GetHabitReminders(habitID)
+}
+
+// CreateHabitReminder crea un recordatorio para un hábito
+func (c *HabitController) CreateHabitReminder(habitID int, input models.NewHabitReminderInput) (models.HabitReminder, error) {
+	// Verificar que el hábito existe
+	habit, err := c.Repo.GetHabit(habitID)
+	if err != nil {
+		return models.HabitReminder{}, errors.New("hábito no encontrado")
+	}
+	// Un hábito a evitar no tiene nada que completar que recordar
+	if habit.Avoid {
+		return models.HabitReminder{}, errors.New("los hábitos a evitar no admiten recordatorios")
+	}
+
+	// Validar hora
+	_, err = time.Parse("15:04", input.Time)
+	if err != nil {
+		return models.HabitReminder{}, errors.New("formato de hora inválido. Usar HH:MM")
+	}
+
+	if input.Weekdays < 0 || input.Weekdays > models.AllWeekdays {
+		return models.HabitReminder{}, errors.New("máscara de días de la semana inválida")
+	}
+
+	id, err := c.Repo.CreateHabitReminder(habitID, input)
+	if err != nil {
+		return models.HabitReminder{}, err
+	}
+
+	// Obtener el recordatorio creado
+	return c.Repo.GetHabitReminder(id)
+}
+
+// UpdateHabitReminder actualiza un recordatorio existente
+func (c *HabitController) UpdateHabitReminder(id int, input models.UpdateHabitReminderInput) (models.HabitReminder, error) {
+	// Verificar que el recordatorio existe
+	_, err := c.Repo.GetHabitReminder(id)
+	if err != nil {
+		return models.HabitReminder{}, errors.New("recordatorio no encontrado")
+	}
+
+	// Validar hora si se proporciona
+	if input.Time != "" {
+		_, err := time.Parse("15:04", input.Time)
+		if err != nil {
+			return models.HabitReminder{}, errors.New("formato de hora inválido. Usar HH:MM")
+		}
+	}
+
+	if input.Weekdays < 0 || input.Weekdays > models.AllWeekdays {
+		return models.HabitReminder{}, errors.New("máscara de días de la semana inválida")
+	}
+
+	if err := c.Repo.UpdateHabitReminder(id, input); err != nil {
+		return models.HabitReminder{}, err
+	}
+
+	// Obtener el recordatorio actualizado
+	return c.Repo.GetHabitReminder(id)
+}
+
+// DeleteHabitReminder elimina un recordatorio
+func (c *HabitController) DeleteHabitReminder(id int) error {
+	// Verificar que el recordatorio existe
+	_, err := c.Repo.GetHabitReminder(id)
+	if err != nil {
+		return errors.New("recordatorio no encontrado")
+	}
+
+	return c.Repo.DeleteHabitReminder(id)
+}
+
+// SnoozeReminder aplaza un recordatorio el número de minutos indicado
+func (c *HabitController) SnoozeReminder(id int, minutes int) (models.HabitReminder, error) {
+	// Verificar que el recordatorio existe
+	_, err := c.Repo.GetHabitReminder(id)
+	if err != nil {
+		return models.HabitReminder{}, errors.New("recordatorio no encontrado")
+	}
+
+	if minutes <= 0 {
+		minutes = 10 // Valor por defecto
+	}
+
+	if minutes > 24*60 {
+		return models.HabitReminder{}, errors.New("no se puede aplazar un recordatorio más de 24 horas")
+	}
+
+	until := time.Now().Add(time.Duration(minutes) * time.Minute)
+	if err := c.Repo.SnoozeHabitReminder(id, until); err != nil {
+		return models.HabitReminder{}, err
+	}
+
+	return c.Repo.GetHabitReminder(id)
+}
+
+// DismissReminder descarta un recordatorio durante el resto del día
+func (c *HabitController) DismissReminder(id int) (models.HabitReminder, error) {
+	// Verificar que el recordatorio existe
+	_, err := c.Repo.GetHabitReminder(id)
+	if err != nil {
+		return models.HabitReminder{}, errors.New("recordatorio no encontrado")
+	}
+
+	if err := c.Repo.DismissHabitReminder(id, c.Repo.Today()); err != nil {
+		return models.HabitReminder{}, err
+	}
+
+	return c.Repo.GetHabitReminder(id)
+}
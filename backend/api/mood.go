@@ -2,6 +2,8 @@ package api
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/kubaliski/habit-tracker/backend/database"
@@ -24,10 +26,10 @@ func NewMoodController(repo database.Repository) *MoodController {
 func (c *MoodController) GetAllMoodEntries(startDate string, endDate string) ([]models.MoodEntry, error) {
 	// Si no se proporcionan fechas, usar valores predeterminados
 	if startDate == "" {
-		startDate = time.Now().AddDate(0, 0, -30).Format("2006-01-02")
+		startDate = logicalToday(c.Repo).AddDate(0, 0, -30).Format("2006-01-02")
 	}
 	if endDate == "" {
-		endDate = time.Now().Format("2006-01-02")
+		endDate = c.Repo.Today()
 	}
 
 	// Validar fechas
@@ -53,7 +55,8 @@ func (c *MoodController) GetMoodEntry(id int) (models.MoodEntry, error) {
 	return entry, nil
 }
 
-// GetMoodEntryByDate busca un registro de estado de ánimo por fecha
+// GetMoodEntryByDate busca un registro de estado de ánimo por fecha. Si el día tiene varios,
+// devuelve el último.
 func (c *MoodController) GetMoodEntryByDate(date string) (models.MoodEntry, error) {
 	// Validar fecha
 	_, err := time.Parse("2006-01-02", date)
@@ -73,12 +76,27 @@ func (c *MoodController) GetMoodEntryByDate(date string) (models.MoodEntry, erro
 	return entries[0], nil
 }
 
-// CreateMoodEntry crea un nuevo registro de estado de ánimo
+// CreateMoodEntry crea un nuevo registro de estado de ánimo. Un día admite varios registros,
+// cada uno con su hora (timestamp), su franja del día (slot) o ninguna de las dos.
 func (c *MoodController) CreateMoodEntry(input models.NewMoodEntryInput) (models.MoodEntry, error) {
+	// Con hora, la fecha es la de su día lógico
+	if input.Timestamp != "" {
+		timestamp, err := time.Parse(time.RFC3339, input.Timestamp)
+		if err != nil {
+			return models.MoodEntry{}, errors.New("formato de timestamp inválido. Usar ISO 8601 (YYYY-MM-DDTHH:MM:SSZ)")
+		}
+
+		day := c.Repo.DayOf(timestamp)
+		if input.Date != "" && input.Date != day {
+			return models.MoodEntry{}, fmt.Errorf("la fecha %s no coincide con el día del timestamp (%s)", input.Date, day)
+		}
+		input.Date = day
+	}
+
 	// Validar campos requeridos
 	if input.Date == "" {
 		// Si no se proporciona una fecha, usar la fecha actual
-		input.Date = time.Now().Format("2006-01-02")
+		input.Date = c.Repo.Today()
 	}
 
 	// Validar fecha
@@ -87,6 +105,11 @@ func (c *MoodController) CreateMoodEntry(input models.NewMoodEntryInput) (models
 		return models.MoodEntry{}, errors.New("formato de fecha inválido. Usar YYYY-MM-DD")
 	}
 
+	// Validar franja del día
+	if input.Slot != "" && !validMoodSlot(input.Slot) {
+		return models.MoodEntry{}, fmt.Errorf("franja del día inválida. Usar %s", strings.Join(models.MoodSlots, ", "))
+	}
+
 	// Validar puntuación de estado de ánimo
 	if input.MoodScore < 1 || input.MoodScore > 10 {
 		return models.MoodEntry{}, errors.New("la puntuación de estado de ánimo debe estar entre 1 y 10")
@@ -157,3 +180,92 @@ func (c *MoodController) DeleteMoodEntry(id int) error {
 
 	return c.Repo.DeleteMoodEntry(id)
 }
+
+// CreateMoodCheckIn registra el estado de ánimo en un momento del día. Sin hora ni franja,
+// usa el momento actual.
+func (c *MoodController) CreateMoodCheckIn(input models.NewMoodEntryInput) (models.MoodEntry, error) {
+	if input.Timestamp == "" && input.Slot == "" {
+		input.Timestamp = time.Now().Format(time.RFC3339)
+	}
+
+	return c.CreateMoodEntry(input)
+}
+
+// GetMoodCheckIns obtiene los registros de estado de ánimo de un día en orden cronológico
+func (c *MoodController) GetMoodCheckIns(date string) ([]models.MoodEntry, error) {
+	if date == "" {
+		date = c.Repo.Today()
+	}
+
+	// Validar fecha
+	_, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, errors.New("formato de fecha inválido. Usar YYYY-MM-DD")
+	}
+
+	entries, err := c.Repo.GetAllMoodEntries(date, date)
+	if err != nil {
+		return nil, err
+	}
+
+	// El repositorio devuelve primero el más reciente
+	checkIns := make([]models.MoodEntry, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		checkIns = append(checkIns, entries[i])
+	}
+
+	return checkIns, nil
+}
+
+// GetDailyMoods obtiene el estado de ánimo agregado (media, mínimo, máximo y último valor) de
+// cada día con registros en un rango de fechas
+func (c *MoodController) GetDailyMoods(startDate string, endDate string) ([]models.DailyMood, error) {
+	// Si no se proporcionan fechas, usar valores predeterminados
+	if startDate == "" {
+		startDate = logicalToday(c.Repo).AddDate(0, 0, -30).Format("2006-01-02")
+	}
+	if endDate == "" {
+		endDate = c.Repo.Today()
+	}
+
+	// Validar fechas
+	_, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, errors.New("formato de fecha inicial inválido. Usar YYYY-MM-DD")
+	}
+
+	_, err = time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil, errors.New("formato de fecha final inválido. Usar YYYY-MM-DD")
+	}
+
+	return c.Repo.GetDailyMoods(startDate, endDate)
+}
+
+// GetDailyMood obtiene el estado de ánimo agregado de un día
+func (c *MoodController) GetDailyMood(date string) (models.DailyMood, error) {
+	if date == "" {
+		date = c.Repo.Today()
+	}
+
+	days, err := c.GetDailyMoods(date, date)
+	if err != nil {
+		return models.DailyMood{}, err
+	}
+
+	if len(days) == 0 {
+		return models.DailyMood{}, errors.New("no hay registro de estado de ánimo para esta fecha")
+	}
+
+	return days[0], nil
+}
+
+// validMoodSlot indica si la franja del día es una de las admitidas
+func validMoodSlot(slot string) bool {
+	for _, valid := range models.MoodSlots {
+		if slot == valid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"errors"
+	"net"
+
+	"github.com/kubaliski/habit-tracker/backend/database"
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+// ServerController maneja la configuración del servidor REST local
+type ServerController struct {
+	Repo database.Repository
+
+	// OnChange aplica la nueva configuración (arrancar, detener o reiniciar el servidor).
+	// Opcional, se asigna al arrancar la aplicación.
+	OnChange func(settings models.ServerSettings) error
+}
+
+// NewServerController crea un nuevo controlador de configuración del servidor REST
+func NewServerController(repo database.Repository) *ServerController {
+	return &ServerController{
+		Repo: repo,
+	}
+}
+
+// GetServerSettings obtiene la configuración del servidor REST, incluido el token de acceso
+func (c *ServerController) GetServerSettings() (models.ServerSettings, error) {
+	return c.Repo.GetServerSettings()
+}
+
+// UpdateServerSettings actualiza la configuración del servidor REST y la aplica de inmediato
+func (c *ServerController) UpdateServerSettings(input models.UpdateServerSettingsInput) (models.ServerSettings, error) {
+	if input.Address != "" {
+		if _, _, err := net.SplitHostPort(input.Address); err != nil {
+			return models.ServerSettings{}, errors.New("dirección inválida. Usar host:puerto, por ejemplo 127.0.0.1:8765")
+		}
+	}
+
+	if err := c.Repo.UpdateServerSettings(input); err != nil {
+		return models.ServerSettings{}, err
+	}
+
+	settings, err := c.Repo.GetServerSettings()
+	if err != nil {
+		return models.ServerSettings{}, err
+	}
+
+	if c.OnChange != nil {
+		if err := c.OnChange(settings); err != nil {
+			return settings, err
+		}
+	}
+
+	return settings, nil
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/database"
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+// SettingsController maneja los ajustes generales de la aplicación
+type SettingsController struct {
+	Repo database.Repository
+}
+
+// NewSettingsController crea un nuevo controlador de ajustes generales
+func NewSettingsController(repo database.Repository) *SettingsController {
+	return &SettingsController{
+		Repo: repo,
+	}
+}
+
+// GetTimeSettings obtiene la zona horaria configurada y la que se está usando
+func (c *SettingsController) GetTimeSettings() (models.TimeSettings, error) {
+	return c.Repo.GetTimeSettings()
+}
+
+// UpdateTimeSettings cambia la zona horaria (nombre IANA, p. ej. Europe/Madrid; vacío es la
+// del sistema) o la hora de cambio de día. Los registros con hora ya guardados se reasignan a
+// su nuevo día: consumos de cafeína (caffeine_intake), recaídas (habit_slips) y registros de
+// ánimo (mood_entries).
+func (c *SettingsController) UpdateTimeSettings(input models.UpdateTimeSettingsInput) (models.TimeSettings, error) {
+	if input.Timezone != nil {
+		name := strings.TrimSpace(*input.Timezone)
+		if name != "" {
+			if _, err := time.LoadLocation(name); err != nil {
+				return models.TimeSettings{}, fmt.Errorf("zona horaria desconocida: %s", name)
+			}
+		}
+		input.Timezone = &name
+	}
+
+	if input.RolloverHour != nil && (*input.RolloverHour < 0 || *input.RolloverHour >= models.MaxRolloverHour) {
+		return models.TimeSettings{}, fmt.Errorf("la hora de cambio de día debe estar entre 0 y %d", models.MaxRolloverHour-1)
+	}
+
+	if err := c.Repo.UpdateTimeSettings(input); err != nil {
+		return models.TimeSettings{}, err
+	}
+
+	return c.Repo.GetTimeSettings()
+}
+
+// logicalToday devuelve el día lógico actual del usuario, que tras medianoche y antes de la
+// hora de cambio de día sigue siendo el anterior
+func logicalToday(repo database.Repository) time.Time {
+	today, err := time.Parse("2006-01-02", repo.Today())
+	if err != nil {
+		return time.Now()
+	}
+	return today
+}
@@ -2,8 +2,11 @@ package api
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/kubaliski/habit-tracker/backend/database"
+	"github.com/kubaliski/habit-tracker/backend/models"
+	"github.com/kubaliski/habit-tracker/backend/period"
 )
 
 // StatsController maneja las operaciones relacionadas con estadísticas
@@ -18,43 +21,119 @@ func NewStatsController(repo database.Repository) *StatsController {
 	}
 }
 
-// GetHabitStats obtiene estadísticas para un hábito específico
-func (c *StatsController) GetHabitStats(id int, period string) (map[string]interface{}, error) {
+// GetHabitStats obtiene estadísticas para un hábito específico. El período admite ventanas
+// móviles (week, 30d), períodos naturales (this-week, last-month, 2025, 2025-Q3) y rangos
+// explícitos (2025-01-01..2025-03-31); vacío equivale a "month".
+func (c *StatsController) GetHabitStats(id int, spec string) (models.HabitStats, error) {
 	// Verificar que el hábito existe
 	_, err := c.Repo.GetHabit(id)
 	if err != nil {
-		return nil, errors.New("hábito no encontrado")
+		return models.HabitStats{}, errors.New("hábito no encontrado")
 	}
 
-	// Validar que el período es válido
-	if period != "week" && period != "month" && period != "year" {
-		period = "month" // Usar valor predeterminado
+	// Resolver el período
+	dateRange, err := period.Resolve(spec, logicalToday(c.Repo))
+	if err != nil {
+		return models.HabitStats{}, err
+	}
+
+	return c.Repo.GetHabitStats(id, dateRange)
+}
+
+// GetHabitStreaks obtiene la racha actual, la más larga y el historial de rachas de un hábito
+func (c *StatsController) GetHabitStreaks(id int) (models.HabitStreaks, error) {
+	// Verificar que el hábito existe
+	_, err := c.Repo.GetHabit(id)
+	if err != nil {
+		return models.HabitStreaks{}, errors.New("hábito no encontrado")
 	}
 
-	return c.Repo.GetHabitStats(id, period)
+	return c.Repo.GetHabitStreaks(id)
 }
 
-// GetMoodStats obtiene estadísticas de estado de ánimo
-func (c *StatsController) GetMoodStats(period string) (map[string]interface{}, error) {
-	// Validar que el período es válido
-	if period != "week" && period != "month" && period != "year" {
-		period = "month" // Usar valor predeterminado
+// GetMoodStats obtiene estadísticas de estado de ánimo en un período (ver GetHabitStats)
+func (c *StatsController) GetMoodStats(spec string) (models.MoodStats, error) {
+	dateRange, err := period.Resolve(spec, logicalToday(c.Repo))
+	if err != nil {
+		return models.MoodStats{}, err
 	}
 
-	return c.Repo.GetMoodStats(period)
+	return c.Repo.GetMoodStats(dateRange)
 }
 
-// GetCaffeineStats obtiene estadísticas de consumo de cafeína
-func (c *StatsController) GetCaffeineStats(period string) (map[string]interface{}, error) {
-	// Validar que el período es válido
-	if period != "week" && period != "month" && period != "year" {
-		period = "month" // Usar valor predeterminado
+// GetCaffeineStats obtiene estadísticas de consumo de cafeína en un período (ver GetHabitStats)
+func (c *StatsController) GetCaffeineStats(spec string) (models.CaffeineStats, error) {
+	dateRange, err := period.Resolve(spec, logicalToday(c.Repo))
+	if err != nil {
+		return models.CaffeineStats{}, err
 	}
 
-	return c.Repo.GetCaffeineStats(period)
+	return c.Repo.GetCaffeineStats(dateRange)
+}
+
+// ResolvePeriod devuelve las fechas que abarca una especificación de período
+func (c *StatsController) ResolvePeriod(spec string) (models.DateRange, error) {
+	return period.Resolve(spec, logicalToday(c.Repo))
+}
+
+// correlationWindow convierte una ventana de análisis en días (0 = por defecto) en un período
+func (c *StatsController) correlationWindow(windowDays int) (models.DateRange, error) {
+	if windowDays < 0 || windowDays > 3650 {
+		return models.DateRange{}, errors.New("la ventana de análisis debe estar entre 1 y 3650 días")
+	}
+
+	if windowDays == 0 {
+		windowDays = models.DefaultCorrelationWindowDays
+	}
+
+	return period.LastDays(windowDays, logicalToday(c.Repo)), nil
 }
 
 // GetCorrelationStats obtiene estadísticas de correlación entre hábitos, estado de ánimo y consumo de cafeína
-func (c *StatsController) GetCorrelationStats() (map[string]interface{}, error) {
-	return c.Repo.GetCorrelationStats()
+// en los últimos windowDays días (90 por defecto)
+func (c *StatsController) GetCorrelationStats(windowDays int) (models.CorrelationStats, error) {
+	dateRange, err := c.correlationWindow(windowDays)
+	if err != nil {
+		return models.CorrelationStats{}, err
+	}
+
+	return c.Repo.GetCorrelationStats(dateRange)
+}
+
+// GetHabitMoodCorrelations compara el estado de ánimo de los días en que se completó cada hábito
+// activo con el de los días en que no, en los últimos windowDays días (90 por defecto)
+func (c *StatsController) GetHabitMoodCorrelations(windowDays int) (models.HabitMoodAnalysis, error) {
+	dateRange, err := c.correlationWindow(windowDays)
+	if err != nil {
+		return models.HabitMoodAnalysis{}, err
+	}
+
+	return c.Repo.GetHabitMoodCorrelations(dateRange)
+}
+
+// GetLaggedCorrelations correlaciona la cafeína y las compleciones de hábitos de un día con el
+// estado de ánimo de ese mismo día y de los siguientes (desfases de 0 a MaxLag días)
+func (c *StatsController) GetLaggedCorrelations(options models.LaggedCorrelationOptions) (models.LaggedCorrelationMatrix, error) {
+	dateRange, err := c.correlationWindow(options.WindowDays)
+	if err != nil {
+		return models.LaggedCorrelationMatrix{}, err
+	}
+
+	maxLag := models.DefaultCorrelationMaxLag
+	if options.MaxLag != nil {
+		maxLag = *options.MaxLag
+	}
+	if maxLag < 0 || maxLag > models.MaxCorrelationLag {
+		return models.LaggedCorrelationMatrix{}, fmt.Errorf("el desfase máximo debe estar entre 0 y %d días", models.MaxCorrelationLag)
+	}
+
+	cutoffHour := models.DefaultCaffeineCutoff
+	if options.CutoffHour != nil {
+		cutoffHour = *options.CutoffHour
+	}
+	if cutoffHour < 0 || cutoffHour > 23 {
+		return models.LaggedCorrelationMatrix{}, errors.New("la hora de corte debe estar entre 0 y 23")
+	}
+
+	return c.Repo.GetLaggedCorrelations(dateRange, maxLag, cutoffHour)
 }
@@ -0,0 +1,315 @@
+// Package backup crea copias de seguridad periódicas de la base de datos y aplica su rotación
+package backup
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/database"
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+// DefaultInterval es el tiempo mínimo entre dos copias programadas
+const DefaultInterval = 24 * time.Hour
+
+// checkInterval es la frecuencia con la que se comprueba si toca una copia nueva
+const checkInterval = time.Hour
+
+// Formato de los nombres de archivo de las copias: habits-20060102-150405.db. Las copias
+// previas a una restauración usan su propio prefijo para que la rotación no las elimine.
+const (
+	filePrefix   = "habits-"
+	safetyPrefix = "pre-restore-"
+	fileSuffix   = ".db"
+	fileLayout   = "20060102-150405"
+)
+
+// DefaultRetention conserva una semana de copias diarias, un mes de semanales y un año de mensuales
+var DefaultRetention = models.BackupRetention{Daily: 7, Weekly: 4, Monthly: 12}
+
+// Manager crea, rota y restaura copias de seguridad en una carpeta
+type Manager struct {
+	Repo      database.Repository
+	Dir       string
+	Retention models.BackupRetention
+	Interval  time.Duration
+	Now       func() time.Time // Reemplazable para pruebas
+
+	mu      sync.Mutex // Serializa copias, rotaciones y restauraciones
+	loopMu  sync.Mutex
+	stop    chan struct{}
+	done    chan struct{}
+	running bool
+}
+
+// NewManager crea un gestor de copias de seguridad con la retención predeterminada
+func NewManager(repo database.Repository, dir string) *Manager {
+	return &Manager{
+		Repo:      repo,
+		Dir:       dir,
+		Retention: DefaultRetention,
+		Interval:  DefaultInterval,
+		Now:       time.Now,
+	}
+}
+
+// Start arranca la goroutine de copias programadas. Llamarlo varias veces no tiene efecto.
+func (m *Manager) Start() {
+	m.loopMu.Lock()
+	defer m.loopMu.Unlock()
+
+	if m.running {
+		return
+	}
+
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+	m.running = true
+
+	go m.loop(m.stop, m.done)
+}
+
+// Stop detiene las copias programadas y espera a que termine la copia en curso
+func (m *Manager) Stop() {
+	m.loopMu.Lock()
+	if !m.running {
+		m.loopMu.Unlock()
+		return
+	}
+	close(m.stop)
+	done := m.done
+	m.running = false
+	m.loopMu.Unlock()
+
+	<-done
+}
+
+// loop crea una copia cuando la última es más antigua que el intervalo configurado
+func (m *Manager) loop(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	m.RunIfDue()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.RunIfDue()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// RunIfDue crea una copia y aplica la rotación si ha pasado el intervalo desde la última
+func (m *Manager) RunIfDue() {
+	backups, err := m.List()
+	if err != nil {
+		log.Printf("Error al listar copias de seguridad: %v", err)
+		return
+	}
+
+	if len(backups) > 0 && m.Now().Sub(backups[0].CreatedAt) < m.Interval {
+		return
+	}
+
+	info, err := m.Snapshot()
+	if err != nil {
+		log.Printf("Error al crear copia de seguridad: %v", err)
+		return
+	}
+	log.Printf("Copia de seguridad creada en %s", info.Path)
+
+	if _, err := m.Prune(); err != nil {
+		log.Printf("Error al rotar copias de seguridad: %v", err)
+	}
+}
+
+// Snapshot crea una copia de seguridad consistente de la base de datos
+func (m *Manager) Snapshot() (models.BackupInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.snapshot(filePrefix)
+}
+
+// snapshot crea una copia con el prefijo indicado; el llamador debe tener m.mu
+func (m *Manager) snapshot(prefix string) (models.BackupInfo, error) {
+	if err := os.MkdirAll(m.Dir, 0755); err != nil {
+		return models.BackupInfo{}, fmt.Errorf("error al crear la carpeta de copias de seguridad: %w", err)
+	}
+
+	// Los nombres tienen resolución de segundos: avanzar si ya existe una copia en ese segundo
+	createdAt := m.Now().Truncate(time.Second)
+	name := prefix + createdAt.Format(fileLayout) + fileSuffix
+	for {
+		if _, err := os.Stat(filepath.Join(m.Dir, name)); os.IsNotExist(err) {
+			break
+		}
+		createdAt = createdAt.Add(time.Second)
+		name = prefix + createdAt.Format(fileLayout) + fileSuffix
+	}
+	path := filepath.Join(m.Dir, name)
+
+	if err := m.Repo.BackupTo(path); err != nil {
+		return models.BackupInfo{}, err
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return models.BackupInfo{}, fmt.Errorf("error al leer la copia de seguridad: %w", err)
+	}
+
+	return models.BackupInfo{
+		Name:      name,
+		Path:      path,
+		CreatedAt: createdAt,
+		Size:      stat.Size(),
+	}, nil
+}
+
+// List devuelve las copias de seguridad de la carpeta, de la más reciente a la más antigua
+func (m *Manager) List() ([]models.BackupInfo, error) {
+	entries, err := os.ReadDir(m.Dir)
+	if os.IsNotExist(err) {
+		return []models.BackupInfo{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al leer la carpeta de copias de seguridad: %w", err)
+	}
+
+	backups := []models.BackupInfo{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+
+		// Los archivos con otro formato de nombre no son copias gestionadas
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), fileSuffix)
+		createdAt, err := time.ParseInLocation(fileLayout, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+
+		stat, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("error al leer la copia %s: %w", name, err)
+		}
+
+		backups = append(backups, models.BackupInfo{
+			Name:      name,
+			Path:      filepath.Join(m.Dir, name),
+			CreatedAt: createdAt,
+			Size:      stat.Size(),
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+
+	return backups, nil
+}
+
+// Prune elimina las copias que no conserva la política de retención y devuelve sus rutas
+func (m *Manager) Prune() ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	backups, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+
+	keep := retained(backups, m.Retention)
+
+	removed := []string{}
+	for _, b := range backups {
+		if keep[b.Path] {
+			continue
+		}
+		if err := os.Remove(b.Path); err != nil {
+			return removed, fmt.Errorf("error al eliminar la copia %s: %w", b.Name, err)
+		}
+		removed = append(removed, b.Path)
+	}
+
+	return removed, nil
+}
+
+// Restore valida una copia y la restaura sobre la base de datos en uso. Antes de
+// restaurar se guarda una copia del estado actual para poder deshacer la operación;
+// lleva el prefijo pre-restore- y, como List no la incluye, Prune nunca la elimina.
+func (m *Manager) Restore(path string) (models.RestoreResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	version, err := database.ValidateSnapshot(path)
+	if err != nil {
+		return models.RestoreResult{}, err
+	}
+
+	safety, err := m.snapshot(safetyPrefix)
+	if err != nil {
+		return models.RestoreResult{}, fmt.Errorf("error al guardar el estado actual antes de restaurar: %w", err)
+	}
+
+	if err := m.Repo.RestoreFrom(path); err != nil {
+		return models.RestoreResult{}, err
+	}
+
+	return models.RestoreResult{
+		RestoredFrom:  path,
+		SafetyBackup:  safety.Path,
+		SchemaVersion: version,
+	}, nil
+}
+
+// retained calcula qué copias conserva la rotación: la más reciente de cada día, semana
+// ISO y mes dentro de los límites configurados. La copia más reciente se conserva siempre.
+// Las copias deben estar ordenadas de la más reciente a la más antigua.
+func retained(backups []models.BackupInfo, retention models.BackupRetention) map[string]bool {
+	keep := make(map[string]bool)
+	if len(backups) == 0 {
+		return keep
+	}
+	keep[backups[0].Path] = true
+
+	buckets := []struct {
+		limit int
+		key   func(t time.Time) string
+	}{
+		{retention.Daily, func(t time.Time) string { return t.Format("2006-01-02") }},
+		{retention.Weekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}},
+		{retention.Monthly, func(t time.Time) string { return t.Format("2006-01") }},
+	}
+
+	for _, bucket := range buckets {
+		seen := make(map[string]bool)
+		for _, b := range backups {
+			key := bucket.key(b.CreatedAt)
+			if seen[key] {
+				continue
+			}
+			if len(seen) >= bucket.limit {
+				break
+			}
+			seen[key] = true
+			keep[b.Path] = true
+		}
+	}
+
+	return keep
+}
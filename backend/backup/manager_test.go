@@ -0,0 +1,142 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/database"
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+// testBackups crea copias ficticias con las marcas de tiempo indicadas, de la más reciente a la más antigua
+func testBackups(t *testing.T, stamps ...string) []models.BackupInfo {
+	t.Helper()
+	backups := make([]models.BackupInfo, 0, len(stamps))
+	for _, stamp := range stamps {
+		createdAt, err := time.ParseInLocation("2006-01-02 15:04", stamp, time.Local)
+		if err != nil {
+			t.Fatalf("marca inválida %q: %v", stamp, err)
+		}
+		backups = append(backups, models.BackupInfo{Path: stamp, CreatedAt: createdAt})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+	return backups
+}
+
+func TestRetained(t *testing.T) {
+	tests := []struct {
+		name      string
+		stamps    []string
+		retention models.BackupRetention
+		want      []string
+	}{
+		{
+			name:      "sin copias",
+			retention: DefaultRetention,
+		},
+		{
+			name:      "la más reciente se conserva aunque no haya retención",
+			stamps:    []string{"2026-10-18 09:00", "2026-10-17 09:00"},
+			retention: models.BackupRetention{},
+			want:      []string{"2026-10-18 09:00"},
+		},
+		{
+			name:      "la última de cada día",
+			stamps:    []string{"2026-10-18 20:00", "2026-10-18 09:00", "2026-10-17 20:00", "2026-10-17 09:00", "2026-10-16 09:00"},
+			retention: models.BackupRetention{Daily: 2},
+			want:      []string{"2026-10-18 20:00", "2026-10-17 20:00"},
+		},
+		{
+			name: "la última de cada semana ISO",
+			// El lunes 12 comparte semana con el domingo 18; el 11 y el 4 son domingos
+			stamps:    []string{"2026-10-18 09:00", "2026-10-12 09:00", "2026-10-11 09:00", "2026-10-04 09:00", "2026-09-27 09:00"},
+			retention: models.BackupRetention{Weekly: 3},
+			want:      []string{"2026-10-18 09:00", "2026-10-11 09:00", "2026-10-04 09:00"},
+		},
+		{
+			name:      "la última de cada mes",
+			stamps:    []string{"2026-10-02 09:00", "2026-09-30 09:00", "2026-09-01 09:00", "2026-08-15 09:00", "2026-07-15 09:00"},
+			retention: models.BackupRetention{Monthly: 2},
+			want:      []string{"2026-10-02 09:00", "2026-09-30 09:00"},
+		},
+		{
+			name: "los niveles se suman",
+			stamps: []string{
+				"2026-10-18 09:00", "2026-10-17 09:00", "2026-10-16 09:00",
+				"2026-10-04 09:00", "2026-09-27 09:00", "2026-08-30 09:00", "2026-06-30 09:00",
+			},
+			retention: models.BackupRetention{Daily: 2, Weekly: 3, Monthly: 3},
+			want: []string{
+				"2026-10-18 09:00", "2026-10-17 09:00",
+				"2026-10-04 09:00", "2026-09-27 09:00", "2026-08-30 09:00",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keep := retained(testBackups(t, tt.stamps...), tt.retention)
+
+			var got []string
+			for path := range keep {
+				got = append(got, path)
+			}
+			sort.Sort(sort.Reverse(sort.StringSlice(got)))
+
+			if strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+				t.Errorf("retained() = %v, se esperaba %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestoreKeepsSafetyBackupOutOfRotation(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := database.NewSQLiteRepo(filepath.Join(dir, "habits.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteRepo: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+
+	now := time.Date(2026, time.October, 18, 9, 0, 0, 0, time.Local)
+	manager := NewManager(repo, filepath.Join(dir, "backups"))
+	manager.Retention = models.BackupRetention{Daily: 1}
+	manager.Now = func() time.Time { return now }
+
+	backup, err := manager.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	result, err := manager.Restore(backup.Path)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if !strings.HasPrefix(filepath.Base(result.SafetyBackup), safetyPrefix) {
+		t.Errorf("copia previa %s sin el prefijo %s", result.SafetyBackup, safetyPrefix)
+	}
+
+	// Una copia posterior del mismo día deja fuera de la retención a la anterior
+	now = now.Add(time.Hour)
+	if _, err := manager.Snapshot(); err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	removed, err := manager.Prune()
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+
+	if len(removed) != 1 || removed[0] != backup.Path {
+		t.Errorf("Prune() eliminó %v, se esperaba solo %s", removed, backup.Path)
+	}
+	if _, err := os.Stat(result.SafetyBackup); err != nil {
+		t.Errorf("la copia previa a la restauración ya no existe: %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// ==================== COPIAS DE SEGURIDAD ====================
+
+// requiredTables son las tablas que debe contener una copia válida
+var requiredTables = []string{"habits", "habit_logs", "mood_entries", "caffeine_beverages", "caffeine_intake"}
+
+// BackupTo copia la base de datos a destPath con la API de copia en línea de SQLite.
+// La copia es consistente aunque la aplicación siga escribiendo durante el proceso.
+func (r *SQLiteRepo) BackupTo(destPath string) error {
+	if _, err := os.Stat(destPath); err == nil {
+		return fmt.Errorf("ya existe un archivo en %s", destPath)
+	}
+
+	dest, err := sql.Open("sqlite3", destPath)
+	if err != nil {
+		return fmt.Errorf("error al abrir la copia de seguridad: %w", err)
+	}
+	defer dest.Close()
+
+	if err := onlineBackup(dest, r.db); err != nil {
+		os.Remove(destPath)
+		return fmt.Errorf("error al crear copia de seguridad: %w", err)
+	}
+
+	return nil
+}
+
+// RestoreFrom sustituye el contenido de la base de datos por el de una copia válida.
+//
+// El pool de conexiones no se cierra ni se reabre: el servidor, el programador de
+// recordatorios y las copias programadas comparten este repositorio y leen r.db sin
+// bloqueo, así que cambiarlo en caliente no sería seguro. En su lugar la copia se migra
+// primero en un archivo temporal y después se vuelca sobre la base de datos en uso con la
+// API de copia en línea, que escribe todas las páginas en una única transacción. Las
+// lecturas concurrentes ven el estado anterior completo o el restaurado ya migrado, nunca
+// una mezcla ni un esquema antiguo; las demás conexiones del pool detectan el cambio por
+// sí solas y no hay sentencias preparadas que invalidar.
+func (r *SQLiteRepo) RestoreFrom(srcPath string) error {
+	if _, err := ValidateSnapshot(srcPath); err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "habits-restore-")
+	if err != nil {
+		return fmt.Errorf("error al preparar la restauración: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	staged, err := sql.Open("sqlite3", filepath.Join(dir, "habits.db"))
+	if err != nil {
+		return fmt.Errorf("error al preparar la restauración: %w", err)
+	}
+	defer staged.Close()
+
+	src, err := sql.Open("sqlite3", srcPath)
+	if err != nil {
+		return fmt.Errorf("error al abrir la copia de seguridad: %w", err)
+	}
+	defer src.Close()
+
+	if err := onlineBackup(staged, src); err != nil {
+		return fmt.Errorf("error al leer la copia de seguridad: %w", err)
+	}
+
+	// Sin ruta no se crea la copia previa a la migración: el original ya es esa copia
+	stagedRepo := &SQLiteRepo{db: staged}
+	if err := stagedRepo.migrate(); err != nil {
+		return fmt.Errorf("error al migrar la copia restaurada: %w", err)
+	}
+
+	if err := onlineBackup(r.db, staged); err != nil {
+		return fmt.Errorf("error al restaurar copia de seguridad: %w", err)
+	}
+
+	// La zona horaria y la hora de cambio de día vienen con la copia
+	if err := r.loadDayClock(); err != nil {
+		return fmt.Errorf("error al cargar la configuración horaria restaurada: %w", err)
+	}
+
+	return nil
+}
+
+// ValidateSnapshot comprueba que un archivo es una copia íntegra de la aplicación y que
+// su esquema no es más reciente que el de este binario. Devuelve la versión del esquema.
+func ValidateSnapshot(path string) (int, error) {
+	if _, err := os.Stat(path); err != nil {
+		return 0, fmt.Errorf("no se encuentra la copia de seguridad: %w", err)
+	}
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return 0, fmt.Errorf("error al abrir la copia de seguridad: %w", err)
+	}
+	defer db.Close()
+
+	var integrity string
+	if err := db.QueryRow("PRAGMA integrity_check").Scan(&integrity); err != nil {
+		return 0, fmt.Errorf("la copia de seguridad no es una base de datos válida: %w", err)
+	}
+	if integrity != "ok" {
+		return 0, fmt.Errorf("la copia de seguridad está dañada: %s", integrity)
+	}
+
+	for _, table := range requiredTables {
+		exists, err := tableExists(db, table)
+		if err != nil {
+			return 0, fmt.Errorf("error al inspeccionar la copia de seguridad: %w", err)
+		}
+		if !exists {
+			return 0, fmt.Errorf("la copia de seguridad no contiene la tabla %s", table)
+		}
+	}
+
+	// Las bases de datos anteriores al control de versiones no tienen schema_version
+	version := 0
+	versioned, err := tableExists(db, "schema_version")
+	if err != nil {
+		return 0, fmt.Errorf("error al inspeccionar la copia de seguridad: %w", err)
+	}
+	if versioned {
+		if err := db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_version").Scan(&version); err != nil {
+			return 0, fmt.Errorf("error al obtener versión del esquema: %w", err)
+		}
+	}
+
+	if latest := latestSchemaVersion(); version > latest {
+		return 0, fmt.Errorf("%w (esquema v%d, soportado hasta v%d)", ErrDatabaseTooNew, version, latest)
+	}
+
+	return version, nil
+}
+
+// onlineBackup copia la base de datos principal de src en dest página a página
+func onlineBackup(dest, src *sql.DB) error {
+	ctx := context.Background()
+
+	destConn, err := dest.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer destConn.Close()
+
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+
+	return destConn.Raw(func(destDriver interface{}) error {
+		return srcConn.Raw(func(srcDriver interface{}) error {
+			destSQLite, ok := destDriver.(*sqlite3.SQLiteConn)
+			if !ok {
+				return errors.New("la conexión de destino no es de SQLite")
+			}
+			srcSQLite, ok := srcDriver.(*sqlite3.SQLiteConn)
+			if !ok {
+				return errors.New("la conexión de origen no es de SQLite")
+			}
+
+			backup, err := destSQLite.Backup("main", srcSQLite, "main")
+			if err != nil {
+				return err
+			}
+
+			// -1 copia todas las páginas en un solo paso
+			if _, err := backup.Step(-1); err != nil {
+				backup.Finish()
+				return err
+			}
+
+			return backup.Finish()
+		})
+	})
+}
+
+// tableExists indica si la base de datos contiene la tabla indicada
+func tableExists(db *sql.DB, table string) (bool, error) {
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
@@ -0,0 +1,165 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+// createTestHabits crea n hábitos diarios
+func createTestHabits(t *testing.T, repo *SQLiteRepo, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		_, err := repo.CreateHabit(models.NewHabitInput{
+			Name: fmt.Sprintf("Hábito %d", i+1), Frequency: models.FrequencyDaily, Goal: 1, Measure: models.MeasureCount,
+		})
+		if err != nil {
+			t.Fatalf("CreateHabit: %v", err)
+		}
+	}
+}
+
+// downgradeSnapshot deja una copia como si fuera del esquema anterior a las versiones de meta
+func downgradeSnapshot(t *testing.T, path string) {
+	t.Helper()
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, stmt := range []string{
+		"DROP TABLE habit_goal_versions",
+		fmt.Sprintf("DELETE FROM schema_version WHERE version = %d", latestSchemaVersion()),
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+}
+
+func TestRestoreFromMigratesOlderSnapshot(t *testing.T) {
+	repo := newTestRepo(t)
+	createTestHabits(t, repo, 1)
+
+	snapshot := filepath.Join(t.TempDir(), "snapshot.db")
+	if err := repo.BackupTo(snapshot); err != nil {
+		t.Fatalf("BackupTo: %v", err)
+	}
+	downgradeSnapshot(t, snapshot)
+
+	createTestHabits(t, repo, 2)
+
+	if err := repo.RestoreFrom(snapshot); err != nil {
+		t.Fatalf("RestoreFrom: %v", err)
+	}
+
+	version, err := repo.GetSchemaVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != latestSchemaVersion() {
+		t.Errorf("esquema tras restaurar = v%d, se esperaba v%d", version, latestSchemaVersion())
+	}
+
+	habits, err := repo.GetAllHabits()
+	if err != nil {
+		t.Fatalf("GetAllHabits: %v", err)
+	}
+	if len(habits) != 1 {
+		t.Fatalf("hábitos tras restaurar = %d, se esperaba 1", len(habits))
+	}
+	versions, err := repo.GetHabitGoalVersions(habits[0].ID)
+	if err != nil || len(versions) != 1 {
+		t.Errorf("versiones de la meta tras restaurar = %v (%v), se esperaba 1", versions, err)
+	}
+
+	// La copia original no se toca: su migración ocurre en un archivo temporal
+	if version, err := ValidateSnapshot(snapshot); err != nil || version != latestSchemaVersion()-1 {
+		t.Errorf("ValidateSnapshot(copia) = v%d (%v), se esperaba v%d", version, err, latestSchemaVersion()-1)
+	}
+}
+
+func TestRestoreFromConcurrentReadersSeeConsistentData(t *testing.T) {
+	repo := newTestRepo(t)
+
+	// Estado restaurado: tres hábitos en una copia de esquema anterior
+	other := newTestRepo(t)
+	createTestHabits(t, other, 3)
+	snapshot := filepath.Join(t.TempDir(), "snapshot.db")
+	if err := other.BackupTo(snapshot); err != nil {
+		t.Fatalf("BackupTo: %v", err)
+	}
+	downgradeSnapshot(t, snapshot)
+
+	// Estado actual: un hábito
+	createTestHabits(t, repo, 1)
+
+	// Cada lectura cuenta hábitos y versiones de la meta en una misma transacción
+	read := func() (habits, versions int, err error) {
+		tx, err := repo.db.Begin()
+		if err != nil {
+			return 0, 0, err
+		}
+		defer tx.Rollback()
+
+		if err := tx.QueryRow("SELECT COUNT(*) FROM habits").Scan(&habits); err != nil {
+			return 0, 0, err
+		}
+		if err := tx.QueryRow("SELECT COUNT(*) FROM habit_goal_versions").Scan(&versions); err != nil {
+			return 0, 0, err
+		}
+		return habits, versions, nil
+	}
+
+	done := make(chan struct{})
+	errs := make(chan error, 4)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				habits, versions, err := read()
+				if err != nil {
+					errs <- err
+					return
+				}
+				if (habits != 1 || versions != 1) && (habits != 3 || versions != 3) {
+					errs <- fmt.Errorf("lectura inconsistente: %d hábitos y %d versiones", habits, versions)
+					return
+				}
+			}
+		}()
+	}
+
+	err := repo.RestoreFrom(snapshot)
+	close(done)
+	wg.Wait()
+	close(errs)
+
+	if err != nil {
+		t.Fatalf("RestoreFrom: %v", err)
+	}
+	for err := range errs {
+		t.Error(err)
+	}
+
+	// Todas las conexiones del pool ven ya los datos restaurados
+	for i := 0; i < 8; i++ {
+		habits, versions, err := read()
+		if err != nil || habits != 3 || versions != 3 {
+			t.Fatalf("lectura tras restaurar = %d hábitos y %d versiones (%v), se esperaban 3 y 3", habits, versions, err)
+		}
+	}
+}
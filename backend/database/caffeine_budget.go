@@ -0,0 +1,67 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+// ==================== PRESUPUESTO DE CAFEÍNA ====================
+
+// GetCaffeineBudget calcula el consumo del día y de la semana ISO frente a los límites configurados
+func (r *SQLiteRepo) GetCaffeineBudget(date string) (models.CaffeineBudget, error) {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return models.CaffeineBudget{}, fmt.Errorf("error al parsear fecha: %w", err)
+	}
+
+	limits, err := r.GetCaffeineLimits()
+	if err != nil {
+		return models.CaffeineBudget{}, err
+	}
+
+	dailyTotal, err := r.GetDailyCaffeineTotal(date)
+	if err != nil {
+		return models.CaffeineBudget{}, err
+	}
+
+	weekStart := periodStart(day, "week")
+	weekEnd := weekStart.AddDate(0, 0, 6)
+
+	intakes, err := r.GetCaffeineIntakeRange(weekStart.Format("2006-01-02"), weekEnd.Format("2006-01-02"))
+	if err != nil {
+		return models.CaffeineBudget{}, err
+	}
+
+	var weeklyTotal float64
+	for _, intake := range intakes {
+		weeklyTotal += intake.TotalCaffeine
+	}
+
+	budget := models.CaffeineBudget{
+		Date:        date,
+		Limits:      limits,
+		DailyTotal:  dailyTotal,
+		WeeklyTotal: weeklyTotal,
+		WeekStart:   weekStart.Format("2006-01-02"),
+		WeekEnd:     weekEnd.Format("2006-01-02"),
+		Exceeded:    []string{},
+	}
+
+	if limits.DailyLimit > 0 {
+		budget.DailyRemaining = limits.DailyLimit - dailyTotal
+		if dailyTotal > limits.DailyLimit {
+			budget.Exceeded = append(budget.Exceeded, models.CaffeineLimitDaily)
+		}
+	}
+
+	if limits.WeeklyLimit > 0 {
+		budget.WeeklyRemaining = limits.WeeklyLimit - weeklyTotal
+		if weeklyTotal > limits.WeeklyLimit {
+			budget.Exceeded = append(budget.Exceeded, models.CaffeineLimitWeekly)
+		}
+	}
+
+	return budget, nil
+}
@@ -0,0 +1,256 @@
+package database
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+// ==================== MODELO FARMACOCINÉTICO DE CAFEÍNA ====================
+
+// caffeineLevelAt estima los mg de cafeína activos en el organismo en el instante t.
+// Cada dosis se absorbe linealmente durante el tiempo de absorción y a partir del pico
+// se elimina de forma exponencial según la vida media configurada.
+func caffeineLevelAt(intakes []models.CaffeineIntake, t time.Time, settings models.CaffeineSettings) float64 {
+	absorption := time.Duration(settings.AbsorptionMinutes) * time.Minute
+	level := 0.0
+
+	for _, intake := range intakes {
+		elapsed := t.Sub(intake.Timestamp)
+		if elapsed < 0 {
+			continue // Consumo posterior al instante evaluado
+		}
+
+		if elapsed < absorption {
+			level += intake.TotalCaffeine * float64(elapsed) / float64(absorption)
+			continue
+		}
+
+		decay := (elapsed - absorption).Hours() / settings.HalfLifeHours
+		level += intake.TotalCaffeine * math.Pow(0.5, decay)
+	}
+
+	return level
+}
+
+// caffeineLookback devuelve cuánto tiempo hacia atrás influye una dosis (unas 10 vidas medias)
+func caffeineLookback(settings models.CaffeineSettings) time.Duration {
+	lookback := time.Duration(settings.HalfLifeHours*10*float64(time.Hour)) +
+		time.Duration(settings.AbsorptionMinutes)*time.Minute
+	if lookback < 48*time.Hour {
+		lookback = 48 * time.Hour
+	}
+	return lookback
+}
+
+// caffeineIntakesBetween obtiene los consumos que pueden influir en el nivel entre from y to
+func (r *SQLiteRepo) caffeineIntakesBetween(from, to time.Time, settings models.CaffeineSettings) ([]models.CaffeineIntake, error) {
+	start := from.Add(-caffeineLookback(settings))
+
+	intakes, err := r.GetCaffeineIntakeRange(r.DayOf(start), r.DayOf(to))
+	if err != nil {
+		return nil, err
+	}
+
+	return intakes, nil
+}
+
+// GetCaffeineLevelAt estima los mg de cafeína activos en un instante concreto
+func (r *SQLiteRepo) GetCaffeineLevelAt(at time.Time) (float64, error) {
+	settings, err := r.GetCaffeineSettings()
+	if err != nil {
+		return 0, err
+	}
+
+	intakes, err := r.caffeineIntakesBetween(at, at, settings)
+	if err != nil {
+		return 0, fmt.Errorf("error al obtener consumos de cafeína: %w", err)
+	}
+
+	return caffeineLevelAt(intakes, at, settings), nil
+}
+
+// GetCaffeineCurve calcula la curva de cafeína activa de un día con la resolución indicada en minutos
+func (r *SQLiteRepo) GetCaffeineCurve(date string, resolutionMinutes int) (models.CaffeineCurve, error) {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return models.CaffeineCurve{}, fmt.Errorf("error al parsear fecha: %w", err)
+	}
+
+	// El día lógico va de una hora de cambio de día a la siguiente
+	clock := r.dayClock()
+	dayStart := clock.start(day)
+	dayEnd := clock.start(day.AddDate(0, 0, 1))
+
+	settings, err := r.GetCaffeineSettings()
+	if err != nil {
+		return models.CaffeineCurve{}, err
+	}
+
+	intakes, err := r.caffeineIntakesBetween(dayStart, dayEnd, settings)
+	if err != nil {
+		return models.CaffeineCurve{}, fmt.Errorf("error al obtener consumos de cafeína: %w", err)
+	}
+
+	curve := models.CaffeineCurve{
+		Date:              date,
+		ResolutionMinutes: resolutionMinutes,
+		Settings:          settings,
+		Points:            []models.CaffeineLevelPoint{},
+	}
+
+	step := time.Duration(resolutionMinutes) * time.Minute
+	for t := dayStart; !t.After(dayEnd); t = t.Add(step) {
+		level := caffeineLevelAt(intakes, t, settings)
+		curve.Points = append(curve.Points, models.CaffeineLevelPoint{Time: t, Level: level})
+
+		if level > curve.PeakLevel {
+			curve.PeakLevel = level
+			curve.PeakTime = t
+		}
+	}
+
+	return curve, nil
+}
+
+// EstimateCaffeineBelow calcula cuándo la cafeína activa quedará por debajo del umbral
+// de forma definitiva, teniendo en cuenta dosis aún en fase de absorción.
+func (r *SQLiteRepo) EstimateCaffeineBelow(threshold float64, from time.Time) (models.CaffeineThresholdEstimate, error) {
+	settings, err := r.GetCaffeineSettings()
+	if err != nil {
+		return models.CaffeineThresholdEstimate{}, err
+	}
+
+	horizon := caffeineLookback(settings)
+
+	intakes, err := r.caffeineIntakesBetween(from, from.Add(horizon), settings)
+	if err != nil {
+		return models.CaffeineThresholdEstimate{}, fmt.Errorf("error al obtener consumos de cafeína: %w", err)
+	}
+
+	return estimateCaffeineBelow(intakes, threshold, from, horizon, settings), nil
+}
+
+// estimateCaffeineBelow busca, minuto a minuto hasta el horizonte, el primer instante a partir
+// del cual el nivel ya no vuelve a alcanzar el umbral
+func estimateCaffeineBelow(intakes []models.CaffeineIntake, threshold float64, from time.Time, horizon time.Duration, settings models.CaffeineSettings) models.CaffeineThresholdEstimate {
+	estimate := models.CaffeineThresholdEstimate{
+		Threshold:    threshold,
+		CurrentLevel: caffeineLevelAt(intakes, from, settings),
+		BelowAt:      from,
+	}
+
+	// Buscar el último minuto del horizonte en que el nivel alcanza el umbral
+	for t := from; !t.After(from.Add(horizon)); t = t.Add(time.Minute) {
+		if caffeineLevelAt(intakes, t, settings) >= threshold {
+			estimate.BelowAt = t.Add(time.Minute)
+		}
+	}
+
+	estimate.MinutesUntil = int(estimate.BelowAt.Sub(from).Minutes())
+
+	return estimate
+}
+
+// nextBedtime devuelve la próxima hora de dormir (HH:MM) igual o posterior a from, en la zona loc
+func nextBedtime(bedtime string, from time.Time, loc *time.Location) (time.Time, error) {
+	clock, err := time.Parse("15:04", bedtime)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("hora de dormir inválida %q: %w", bedtime, err)
+	}
+
+	local := from.In(loc)
+	candidate := time.Date(local.Year(), local.Month(), local.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+	if candidate.Before(from) {
+		candidate = candidate.AddDate(0, 0, 1)
+	}
+
+	return candidate, nil
+}
+
+// bedtimeWarningLevel clasifica la cafeína residual respecto al umbral configurado
+func bedtimeWarningLevel(residual, threshold float64) string {
+	switch {
+	case residual >= threshold*2:
+		return models.CaffeineWarningCritical
+	case residual >= threshold:
+		return models.CaffeineWarningHigh
+	case residual >= threshold/2:
+		return models.CaffeineWarningLow
+	default:
+		return models.CaffeineWarningNone
+	}
+}
+
+// ProjectBedtimeCaffeine proyecta la cafeína residual en la próxima hora de dormir.
+// Si se indica un consumo candidato, se evalúa como si ya se hubiera registrado; si no,
+// se evalúa el estado actual a partir de ahora.
+func (r *SQLiteRepo) ProjectBedtimeCaffeine(candidate *models.NewCaffeineIntakeInput) (models.BedtimeCaffeineProjection, error) {
+	settings, err := r.GetCaffeineSettings()
+	if err != nil {
+		return models.BedtimeCaffeineProjection{}, err
+	}
+
+	from := r.Now()
+	var extra *models.CaffeineIntake
+
+	if candidate != nil {
+		if candidate.Timestamp != "" {
+			from, err = time.Parse(time.RFC3339, candidate.Timestamp)
+			if err != nil {
+				return models.BedtimeCaffeineProjection{}, fmt.Errorf("error al parsear timestamp: %w", err)
+			}
+		}
+
+		beverage, err := r.GetCaffeineBeverage(candidate.BeverageID)
+		if err != nil {
+			return models.BedtimeCaffeineProjection{}, fmt.Errorf("error al obtener información de la bebida: %w", err)
+		}
+
+		extra = &models.CaffeineIntake{
+			Timestamp:     from,
+			BeverageID:    beverage.ID,
+			BeverageName:  beverage.Name,
+			TotalCaffeine: intakeCaffeine(*candidate, beverage),
+		}
+	}
+
+	bedtime, err := nextBedtime(settings.Bedtime, from, r.Location())
+	if err != nil {
+		return models.BedtimeCaffeineProjection{}, err
+	}
+
+	intakes, err := r.caffeineIntakesBetween(bedtime, bedtime, settings)
+	if err != nil {
+		return models.BedtimeCaffeineProjection{}, fmt.Errorf("error al obtener consumos de cafeína: %w", err)
+	}
+
+	projection := models.BedtimeCaffeineProjection{
+		Bedtime:         bedtime,
+		Threshold:       settings.BedtimeThreshold,
+		ResidualWithout: caffeineLevelAt(intakes, bedtime, settings),
+	}
+	projection.ResidualAtBedtime = projection.ResidualWithout
+
+	if extra != nil {
+		projection.IntakeCaffeine = extra.TotalCaffeine
+		projection.ResidualAtBedtime = caffeineLevelAt(append(intakes, *extra), bedtime, settings)
+	}
+
+	projection.WarningLevel = bedtimeWarningLevel(projection.ResidualAtBedtime, settings.BedtimeThreshold)
+
+	switch projection.WarningLevel {
+	case models.CaffeineWarningCritical:
+		projection.Message = fmt.Sprintf("Se estiman %.0f mg de cafeína a las %s, más del doble del umbral de %.0f mg", projection.ResidualAtBedtime, settings.Bedtime, settings.BedtimeThreshold)
+	case models.CaffeineWarningHigh:
+		projection.Message = fmt.Sprintf("Se estiman %.0f mg de cafeína a las %s, por encima del umbral de %.0f mg", projection.ResidualAtBedtime, settings.Bedtime, settings.BedtimeThreshold)
+	case models.CaffeineWarningLow:
+		projection.Message = fmt.Sprintf("Se estiman %.0f mg de cafeína a las %s, cerca del umbral de %.0f mg", projection.ResidualAtBedtime, settings.Bedtime, settings.BedtimeThreshold)
+	default:
+		projection.Message = fmt.Sprintf("Se estiman %.0f mg de cafeína a las %s", projection.ResidualAtBedtime, settings.Bedtime)
+	}
+
+	return projection, nil
+}
@@ -0,0 +1,168 @@
+package database
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+// testCaffeineSettings usa una vida media de 5 horas y una hora de absorción
+var testCaffeineSettings = models.CaffeineSettings{HalfLifeHours: 5, AbsorptionMinutes: 60}
+
+// testAt devuelve la hora indicada del 1 de octubre de 2026
+func testAt(hour, minute int) time.Time {
+	return time.Date(2026, time.October, 1, hour, minute, 0, 0, time.UTC)
+}
+
+// testIntake crea un consumo de mg a la hora indicada del 1 de octubre de 2026
+func testIntake(hour, minute int, mg float64) models.CaffeineIntake {
+	return models.CaffeineIntake{Timestamp: testAt(hour, minute), TotalCaffeine: mg}
+}
+
+func TestCaffeineLevelAt(t *testing.T) {
+	coffee := []models.CaffeineIntake{testIntake(8, 0, 100)}
+	twoCoffees := []models.CaffeineIntake{testIntake(8, 0, 100), testIntake(14, 0, 100)}
+
+	tests := []struct {
+		name    string
+		intakes []models.CaffeineIntake
+		at      time.Time
+		want    float64
+	}{
+		{name: "antes del consumo", intakes: coffee, at: testAt(7, 59), want: 0},
+		{name: "en el instante del consumo", intakes: coffee, at: testAt(8, 0), want: 0},
+		{name: "a mitad de la absorción", intakes: coffee, at: testAt(8, 30), want: 50},
+		{name: "en el pico", intakes: coffee, at: testAt(9, 0), want: 100},
+		{name: "una vida media después del pico", intakes: coffee, at: testAt(14, 0), want: 50},
+		{name: "dos vidas medias después del pico", intakes: coffee, at: testAt(19, 0), want: 25},
+		{name: "las dosis se suman", intakes: twoCoffees, at: testAt(15, 0), want: 100 + 100*math.Pow(0.5, 6.0/5)},
+		{name: "la segunda dosis aún absorbiéndose", intakes: twoCoffees, at: testAt(14, 15), want: 100*math.Pow(0.5, 5.25/5) + 25},
+		{name: "sin consumos", at: testAt(12, 0), want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := caffeineLevelAt(tt.intakes, tt.at, testCaffeineSettings)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("caffeineLevelAt() = %.6f, se esperaba %.6f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstimateCaffeineBelow(t *testing.T) {
+	horizon := caffeineLookback(testCaffeineSettings)
+
+	tests := []struct {
+		name        string
+		intakes     []models.CaffeineIntake
+		threshold   float64
+		from        time.Time
+		wantMinutes int
+		wantCurrent float64
+	}{
+		{
+			name:      "hasta que la dosis se reduce a la mitad",
+			intakes:   []models.CaffeineIntake{testIntake(8, 0, 100)},
+			threshold: 50, from: testAt(8, 0),
+			wantMinutes: 361, // el nivel vale exactamente 50 a las 14:00
+		},
+		{
+			name:      "desde la fase de absorción, contando la subida",
+			intakes:   []models.CaffeineIntake{testIntake(8, 0, 100)},
+			threshold: 75, from: testAt(8, 30),
+			wantMinutes: 155, wantCurrent: 50,
+		},
+		{
+			name:      "una segunda dosis alarga la espera",
+			intakes:   []models.CaffeineIntake{testIntake(8, 0, 100), testIntake(14, 0, 100)},
+			threshold: 50, from: testAt(8, 0),
+			wantMinutes: 877,
+		},
+		{
+			name:      "un umbral que nunca se alcanza",
+			intakes:   []models.CaffeineIntake{testIntake(8, 0, 100)},
+			threshold: 200, from: testAt(8, 0),
+			wantMinutes: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := estimateCaffeineBelow(tt.intakes, tt.threshold, tt.from, horizon, testCaffeineSettings)
+
+			if got.MinutesUntil != tt.wantMinutes {
+				t.Errorf("MinutesUntil = %d, se esperaban %d", got.MinutesUntil, tt.wantMinutes)
+			}
+			if want := tt.from.Add(time.Duration(tt.wantMinutes) * time.Minute); !got.BelowAt.Equal(want) {
+				t.Errorf("BelowAt = %s, se esperaba %s", got.BelowAt, want)
+			}
+			if math.Abs(got.CurrentLevel-tt.wantCurrent) > 1e-9 {
+				t.Errorf("CurrentLevel = %.6f, se esperaba %.6f", got.CurrentLevel, tt.wantCurrent)
+			}
+		})
+	}
+}
+
+func TestNextBedtime(t *testing.T) {
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		t.Skipf("sin datos de zonas horarias: %v", err)
+	}
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, madrid)
+	}
+
+	tests := []struct {
+		name    string
+		bedtime string
+		from    time.Time
+		want    time.Time
+	}{
+		{name: "por la tarde, la de esta noche", bedtime: "23:00", from: at(time.October, 10, 18, 0), want: at(time.October, 10, 23, 0)},
+		{name: "justo a la hora de dormir", bedtime: "23:00", from: at(time.October, 10, 23, 0), want: at(time.October, 10, 23, 0)},
+		{name: "pasada la hora, la de mañana", bedtime: "23:00", from: at(time.October, 10, 23, 30), want: at(time.October, 11, 23, 0)},
+		{name: "hora de dormir de madrugada", bedtime: "01:30", from: at(time.October, 10, 22, 0), want: at(time.October, 11, 1, 30)},
+		{name: "la hora local se mantiene al cambiar al horario de invierno", bedtime: "23:00", from: at(time.October, 25, 0, 0), want: at(time.October, 25, 23, 0)},
+		{name: "se evalúa en la zona del repositorio", bedtime: "23:00", from: at(time.October, 10, 22, 30).UTC(), want: at(time.October, 10, 23, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := nextBedtime(tt.bedtime, tt.from, madrid)
+			if err != nil {
+				t.Fatalf("nextBedtime: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("nextBedtime() = %s, se esperaba %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := nextBedtime("25:00", testAt(12, 0), madrid); err == nil {
+		t.Error("una hora de dormir inválida debería devolver un error")
+	}
+}
+
+func TestBedtimeWarningLevel(t *testing.T) {
+	tests := []struct {
+		residual float64
+		want     string
+	}{
+		{residual: 0, want: models.CaffeineWarningNone},
+		{residual: 24.9, want: models.CaffeineWarningNone},
+		{residual: 25, want: models.CaffeineWarningLow},
+		{residual: 49.9, want: models.CaffeineWarningLow},
+		{residual: 50, want: models.CaffeineWarningHigh},
+		{residual: 99.9, want: models.CaffeineWarningHigh},
+		{residual: 100, want: models.CaffeineWarningCritical},
+	}
+
+	for _, tt := range tests {
+		if got := bedtimeWarningLevel(tt.residual, 50); got != tt.want {
+			t.Errorf("bedtimeWarningLevel(%.1f, 50) = %s, se esperaba %s", tt.residual, got, tt.want)
+		}
+	}
+}
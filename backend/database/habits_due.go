@@ -0,0 +1,81 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+	"github.com/kubaliski/habit-tracker/backend/schedule"
+)
+
+// ==================== HÁBITOS QUE TOCAN EN UN DÍA ====================
+
+// GetDueHabit indica si un hábito toca en una fecha (YYYY-MM-DD) según el calendario vigente
+// ese día y devuelve su estado con lo registrado. En las cuotas un hábito deja de tocar cuando
+// ya se ha cumplido el número de días del período; el día que la completa sigue tocando,
+// cumplido. Los hábitos a evitar tocan todos los días y se cumplen si no hay recaídas. Los
+// hábitos inactivos, creados después de la fecha o con el día justificado no tocan.
+func (r *SQLiteRepo) GetDueHabit(habitID int, date string) (models.DueHabit, bool, error) {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return models.DueHabit{}, false, fmt.Errorf("fecha inválida: %w", err)
+	}
+
+	// El calendario y la meta son los vigentes ese día
+	habit, err := r.GetHabitAt(habitID, date)
+	if err != nil {
+		return models.DueHabit{}, false, err
+	}
+
+	entry := models.DueHabit{Habit: habit, Date: date}
+	if !habit.Active || r.DayOf(habit.CreatedAt) > date {
+		return entry, false, nil
+	}
+
+	excused, err := r.excusedDates(habit.ID, date, date)
+	if err != nil {
+		return entry, false, err
+	}
+	if excused[date] {
+		return entry, false, nil
+	}
+
+	if habit.Avoid {
+		slips, err := r.GetHabitSlips(habit.ID, date, date)
+		if err != nil {
+			return entry, false, err
+		}
+		entry.Completed = len(slips) == 0
+		return entry, true, nil
+	}
+
+	// Los registros desde el inicio del período deciden si la cuota ya está cumplida
+	periodStart := schedule.PeriodStart(habit.Schedule, day).Format("2006-01-02")
+	logs, err := r.GetHabitLogs(habit.ID, periodStart, date)
+	if err != nil {
+		return entry, false, err
+	}
+
+	completed := make(map[string]bool)
+	for i, habitLog := range logs {
+		logDate := habitLog.Date.Format("2006-01-02")
+		if habitLog.Completed {
+			completed[logDate] = true
+		}
+		if logDate == date {
+			entry.Log = &logs[i]
+			entry.Completed = habitLog.Completed
+		}
+	}
+
+	if len(schedule.DueDates(habit.Schedule, day, day, completed)) == 0 {
+		return entry, false, nil
+	}
+
+	if schedule.IsQuota(habit.Schedule) {
+		entry.PeriodDone = len(completed)
+		entry.PeriodTarget = habit.Schedule.Times
+	}
+
+	return entry, true, nil
+}
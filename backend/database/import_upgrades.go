@@ -0,0 +1,86 @@
+package database
+
+import (
+	"slices"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+	"github.com/kubaliski/habit-tracker/backend/schedule"
+)
+
+// ==================== VERSIONES DEL FORMATO DE EXPORTACIÓN ====================
+
+// formatUpgrade adapta un documento anterior a un cambio del formato de exportación
+type formatUpgrade struct {
+	version     int // versión del formato que introduce el cambio
+	description string
+	up          func(doc *models.ExportDocument) // nil si el importador no necesita nada más
+}
+
+// formatUpgrades contiene los cambios del formato en orden ascendente de versión. Cada uno
+// completa los documentos anteriores con lo que tendrían en el formato actual; solo rellena lo
+// que falta, porque algunas exportaciones se generaron con datos nuevos antes de subir la
+// versión. Como las migraciones, los cambios nuevos se añaden al final.
+var formatUpgrades = []formatUpgrade{
+	// Los registros sin hora ni franja son del día completo, como los de la v1
+	{2, "registros de ánimo con hora o franja", nil},
+	{3, "hábitos cuantitativos con unidad y objetivo", upgradeHabitMeasures},
+	// Sin la marca avoid todos los hábitos son a cumplir, y no hay recaídas que importar
+	{4, "hábitos a evitar y sus recaídas", nil},
+	{5, "calendario de los hábitos", upgradeHabitSchedules},
+	// Sin días justificados, todos los días se evalúan
+	{6, "días justificados", nil},
+	// Sin historial, importHabits da a cada hábito una versión con su meta desde su creación
+	{7, "historial de metas de los hábitos", nil},
+}
+
+// upgradeDocument completa un documento de una versión anterior del formato. Los documentos
+// sin versión son de la v1. Las tablas se copian para no modificar las del llamador.
+func upgradeDocument(doc *models.ExportDocument) {
+	if doc.FormatVersion >= models.ExportFormatVersion {
+		return
+	}
+
+	doc.Habits = slices.Clone(doc.Habits)
+	doc.HabitGoalVersions = slices.Clone(doc.HabitGoalVersions)
+	doc.HabitLogs = slices.Clone(doc.HabitLogs)
+
+	for _, upgrade := range formatUpgrades {
+		if doc.FormatVersion >= upgrade.version {
+			continue
+		}
+		if upgrade.up != nil {
+			upgrade.up(doc)
+		}
+	}
+}
+
+// upgradeHabitMeasures deja los hábitos sin tipo de medición como sí/no y da a sus registros
+// el número de veces como valor, igual que la migración del esquema
+func upgradeHabitMeasures(doc *models.ExportDocument) {
+	for i := range doc.Habits {
+		if doc.Habits[i].Measure == "" {
+			doc.Habits[i].Measure = models.MeasureBoolean
+		}
+	}
+	for i := range doc.HabitLogs {
+		if doc.HabitLogs[i].Value == 0 {
+			doc.HabitLogs[i].Value = float64(doc.HabitLogs[i].Count)
+		}
+	}
+}
+
+// upgradeHabitSchedules deriva de la frecuencia el calendario de los hábitos que no lo traen,
+// igual que la migración del esquema. Las versiones de la meta son posteriores a los calendarios,
+// pero una exportación sin versión correcta podría traerlas sin él.
+func upgradeHabitSchedules(doc *models.ExportDocument) {
+	for i, habit := range doc.Habits {
+		if habit.Schedule.Type == "" {
+			doc.Habits[i].Schedule = schedule.FromFrequency(habit.Frequency, habit.Goal)
+		}
+	}
+	for i, version := range doc.HabitGoalVersions {
+		if version.Schedule.Type == "" {
+			doc.HabitGoalVersions[i].Schedule = schedule.FromFrequency(version.Frequency, version.Goal)
+		}
+	}
+}
@@ -0,0 +1,475 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// ErrDatabaseTooNew se devuelve cuando la base de datos fue creada por una versión más reciente de la aplicación
+var ErrDatabaseTooNew = errors.New("la base de datos pertenece a una versión más reciente de la aplicación")
+
+// migration representa un cambio de esquema versionado
+type migration struct {
+	version     int
+	description string
+	up          func(tx *sql.Tx) error
+}
+
+// migrations contiene todas las migraciones en orden ascendente de versión.
+// Nunca se debe modificar una migración ya publicada: los cambios nuevos se añaden al final.
+var migrations = []migration{
+	{1, "esquema inicial", migrateInitialSchema},
+	{2, "tabla de ajustes de usuario", migrateSettingsTable},
+	{3, "recordatorios de hábitos", migrateHabitReminders},
+	{4, "día local de los consumos de cafeína", migrateIntakeLocalDates},
+	{5, "varios registros de estado de ánimo por día", migrateMoodCheckIns},
+	{6, "hábitos cuantitativos con unidad y objetivo", migrateHabitMeasures},
+	{7, "hábitos a evitar y sus recaídas", migrateHabitSlips},
+	{8, "calendario de los hábitos", migrateHabitSchedules},
+	{9, "días justificados", migrateExcuses},
+	{10, "historial de metas de los hábitos", migrateHabitGoalVersions},
+}
+
+// latestSchemaVersion devuelve la versión de esquema que espera este binario
+func latestSchemaVersion() int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].version
+}
+
+// migrate aplica las migraciones pendientes, creando antes una copia de seguridad
+func (r *SQLiteRepo) migrate() error {
+	// Tabla de control de versiones del esquema
+	_, err := r.db.Exec(`
+	CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER PRIMARY KEY,
+		description TEXT NOT NULL,
+		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return fmt.Errorf("error al crear tabla de versiones: %w", err)
+	}
+
+	current, err := r.GetSchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	latest := latestSchemaVersion()
+	if current > latest {
+		return fmt.Errorf("%w (esquema v%d, soportado hasta v%d)", ErrDatabaseTooNew, current, latest)
+	}
+
+	if current == latest {
+		return nil
+	}
+
+	// Copia de seguridad antes de tocar una base de datos con datos existentes
+	hasData, err := r.hasUserTables()
+	if err != nil {
+		return err
+	}
+	if hasData {
+		backupPath, err := r.backupBeforeMigration(current)
+		if err != nil {
+			return fmt.Errorf("error al crear copia de seguridad previa a la migración: %w", err)
+		}
+		if backupPath != "" {
+			log.Printf("Copia de seguridad creada en %s", backupPath)
+		}
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+
+		if err := r.applyMigration(m); err != nil {
+			return fmt.Errorf("error en la migración v%d (%s): %w", m.version, m.description, err)
+		}
+
+		log.Printf("Migración v%d aplicada: %s", m.version, m.description)
+	}
+
+	return nil
+}
+
+// applyMigration ejecuta una migración y registra su versión dentro de la misma transacción
+func (r *SQLiteRepo) applyMigration(m migration) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %w", err)
+	}
+
+	if err := m.up(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO schema_version (version, description, applied_at) VALUES (?, ?, ?)",
+		m.version,
+		m.description,
+		time.Now(),
+	)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("error al registrar versión: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error al confirmar transacción: %w", err)
+	}
+
+	return nil
+}
+
+// GetSchemaVersion devuelve la versión de esquema aplicada a la base de datos
+func (r *SQLiteRepo) GetSchemaVersion() (int, error) {
+	var version int
+	err := r.db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_version").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("error al obtener versión del esquema: %w", err)
+	}
+
+	return version, nil
+}
+
+// hasUserTables indica si la base de datos ya contiene tablas de la aplicación
+func (r *SQLiteRepo) hasUserTables() (bool, error) {
+	var count int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*)
+		FROM sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name != 'schema_version'
+	`).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("error al inspeccionar tablas existentes: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// backupBeforeMigration guarda una copia consistente de la base de datos junto al archivo original.
+// Devuelve una ruta vacía si la base de datos no está respaldada por un archivo.
+func (r *SQLiteRepo) backupBeforeMigration(fromVersion int) (string, error) {
+	if r.path == "" || r.path == ":memory:" {
+		return "", nil
+	}
+
+	backupPath := fmt.Sprintf("%s.v%d-%s.bak", r.path, fromVersion, time.Now().Format("20060102-150405"))
+
+	// VACUUM INTO no sobrescribe archivos existentes
+	if _, err := os.Stat(backupPath); err == nil {
+		return "", fmt.Errorf("ya existe una copia de seguridad en %s", backupPath)
+	}
+
+	if _, err := r.db.Exec("VACUUM INTO ?", backupPath); err != nil {
+		return "", err
+	}
+
+	return backupPath, nil
+}
+
+// ==================== MIGRACIONES ====================
+
+// migrateInitialSchema crea las tablas originales de la aplicación.
+// Usa IF NOT EXISTS para adoptar bases de datos creadas antes del control de versiones.
+func migrateInitialSchema(tx *sql.Tx) error {
+	statements := []string{
+		// Tabla para los hábitos
+		`CREATE TABLE IF NOT EXISTS habits (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			description TEXT,
+			category TEXT,
+			frequency TEXT NOT NULL,
+			goal INTEGER DEFAULT 1,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			active INTEGER DEFAULT 1
+		)`,
+		// Tabla para el registro diario de hábitos
+		`CREATE TABLE IF NOT EXISTS habit_logs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			habit_id INTEGER NOT NULL,
+			date TEXT NOT NULL,
+			completed INTEGER DEFAULT 0,
+			count INTEGER DEFAULT 0,
+			notes TEXT,
+			FOREIGN KEY (habit_id) REFERENCES habits(id) ON DELETE CASCADE,
+			UNIQUE(habit_id, date)
+		)`,
+		// Tabla para el registro de estados de ánimo
+		`CREATE TABLE IF NOT EXISTS mood_entries (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			date TEXT NOT NULL UNIQUE,
+			mood_score INTEGER NOT NULL,
+			energy_level INTEGER,
+			anxiety_level INTEGER,
+			stress_level INTEGER,
+			sleep_hours REAL,
+			notes TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		// Tabla para etiquetas de estado mental
+		`CREATE TABLE IF NOT EXISTS mood_tags (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			mood_id INTEGER NOT NULL,
+			tag TEXT NOT NULL,
+			FOREIGN KEY (mood_id) REFERENCES mood_entries(id) ON DELETE CASCADE,
+			UNIQUE(mood_id, tag)
+		)`,
+		// Tabla para tipos de bebidas con cafeína
+		`CREATE TABLE IF NOT EXISTS caffeine_beverages (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			caffeine_content REAL NOT NULL,
+			standard_unit TEXT NOT NULL,
+			standard_unit_value REAL NOT NULL,
+			category TEXT,
+			image_path TEXT,
+			active INTEGER DEFAULT 1
+		)`,
+		// Tabla para registros de consumo de cafeína
+		`CREATE TABLE IF NOT EXISTS caffeine_intake (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			timestamp TIMESTAMP NOT NULL,
+			beverage_id INTEGER NOT NULL,
+			beverage_name TEXT NOT NULL,
+			amount REAL NOT NULL,
+			unit TEXT NOT NULL,
+			total_caffeine REAL NOT NULL,
+			perceived_effects TEXT,
+			related_activity TEXT,
+			notes TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (beverage_id) REFERENCES caffeine_beverages(id) ON DELETE CASCADE
+		)`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateSettingsTable crea la tabla clave-valor para los ajustes de usuario
+func migrateSettingsTable(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+	CREATE TABLE IF NOT EXISTS settings (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`)
+	return err
+}
+
+// migrateHabitReminders crea la tabla de recordatorios de hábitos
+func migrateHabitReminders(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+	CREATE TABLE IF NOT EXISTS habit_reminders (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		habit_id INTEGER NOT NULL,
+		time TEXT NOT NULL,
+		weekdays INTEGER NOT NULL DEFAULT 127,
+		active INTEGER DEFAULT 1,
+		snoozed_until TIMESTAMP,
+		dismissed_on TEXT,
+		last_fired_on TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (habit_id) REFERENCES habits(id) ON DELETE CASCADE
+	)`)
+	return err
+}
+
+// migrateIntakeLocalDates añade el día local de cada consumo, que sustituye a DATE(timestamp)
+// (que agrupa por día UTC), y guarda todos los instantes en UTC. Todavía no hay zona horaria
+// configurada, así que se usa la del sistema.
+func migrateIntakeLocalDates(tx *sql.Tx) error {
+	if _, err := tx.Exec("ALTER TABLE caffeine_intake ADD COLUMN local_date TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("CREATE INDEX IF NOT EXISTS idx_caffeine_intake_local_date ON caffeine_intake(local_date)"); err != nil {
+		return err
+	}
+
+	return normalizeLocalDates(tx, "caffeine_intake", "local_date", dayClock{loc: time.Local})
+}
+
+// migrateMoodCheckIns permite varios registros de estado de ánimo por día: quita la restricción
+// UNIQUE de la fecha y añade la hora y la franja del día. SQLite no permite quitar restricciones,
+// así que se reconstruye la tabla conservando los IDs, de los que dependen las etiquetas. Los
+// registros existentes quedan como registros del día completo.
+func migrateMoodCheckIns(tx *sql.Tx) error {
+	statements := []string{
+		`CREATE TABLE mood_entries_new (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			date TEXT NOT NULL,
+			timestamp TIMESTAMP,
+			slot TEXT NOT NULL DEFAULT '',
+			mood_score INTEGER NOT NULL,
+			energy_level INTEGER,
+			anxiety_level INTEGER,
+			stress_level INTEGER,
+			sleep_hours REAL,
+			notes TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`INSERT INTO mood_entries_new (
+			id, date, mood_score, energy_level, anxiety_level, stress_level, sleep_hours, notes, created_at
+		)
+		SELECT id, date, mood_score, energy_level, anxiety_level, stress_level, sleep_hours, notes, created_at
+		FROM mood_entries`,
+		`DROP TABLE mood_entries`,
+		`ALTER TABLE mood_entries_new RENAME TO mood_entries`,
+		`CREATE INDEX IF NOT EXISTS idx_mood_entries_date ON mood_entries(date)`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateHabitMeasures añade el tipo de medición, la unidad y el objetivo diario de los hábitos y
+// el valor decimal de sus registros. Los hábitos existentes quedan como sí/no y el valor de sus
+// registros es el número de veces.
+func migrateHabitMeasures(tx *sql.Tx) error {
+	statements := []string{
+		"ALTER TABLE habits ADD COLUMN measure TEXT NOT NULL DEFAULT 'boolean'",
+		"ALTER TABLE habits ADD COLUMN unit TEXT NOT NULL DEFAULT ''",
+		"ALTER TABLE habits ADD COLUMN target REAL NOT NULL DEFAULT 0",
+		"ALTER TABLE habit_logs ADD COLUMN value REAL NOT NULL DEFAULT 0",
+		"UPDATE habit_logs SET value = COALESCE(count, 0)",
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateHabitSlips añade la marca de hábito a evitar y la tabla de recaídas. Como los consumos
+// de cafeína, las recaídas guardan el instante en UTC y su día lógico.
+func migrateHabitSlips(tx *sql.Tx) error {
+	statements := []string{
+		"ALTER TABLE habits ADD COLUMN avoid INTEGER NOT NULL DEFAULT 0",
+		`CREATE TABLE IF NOT EXISTS habit_slips (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			habit_id INTEGER NOT NULL,
+			timestamp TIMESTAMP NOT NULL,
+			local_date TEXT NOT NULL,
+			notes TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (habit_id) REFERENCES habits(id) ON DELETE CASCADE
+		)`,
+		"CREATE INDEX IF NOT EXISTS idx_habit_slips_habit_date ON habit_slips(habit_id, local_date)",
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateHabitSchedules añade el calendario de los hábitos. Los semanales y mensuales pasan a
+// ser cuotas de tantos días como su objetivo (como ya se evaluaban) y el resto, diarios.
+func migrateHabitSchedules(tx *sql.Tx) error {
+	statements := []string{
+		"ALTER TABLE habits ADD COLUMN schedule_type TEXT NOT NULL DEFAULT 'daily'",
+		"ALTER TABLE habits ADD COLUMN schedule_weekdays INTEGER NOT NULL DEFAULT 0",
+		"ALTER TABLE habits ADD COLUMN schedule_interval INTEGER NOT NULL DEFAULT 0",
+		"ALTER TABLE habits ADD COLUMN schedule_anchor TEXT NOT NULL DEFAULT ''",
+		"UPDATE habits SET schedule_type = frequency WHERE frequency IN ('weekly', 'monthly')",
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateExcuses crea la tabla de días justificados. habit_id es NULL en los que afectan a todos
+// los hábitos.
+func migrateExcuses(tx *sql.Tx) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS excuses (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			habit_id INTEGER,
+			start_date TEXT NOT NULL,
+			end_date TEXT NOT NULL,
+			reason TEXT NOT NULL,
+			notes TEXT,
+			created_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (habit_id) REFERENCES habits(id) ON DELETE CASCADE
+		)`,
+		"CREATE INDEX IF NOT EXISTS idx_excuses_dates ON excuses(start_date, end_date)",
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateHabitGoalVersions crea el historial de metas de los hábitos, con una primera versión
+// por hábito con su meta actual desde el día de su creación
+func migrateHabitGoalVersions(tx *sql.Tx) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS habit_goal_versions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			habit_id INTEGER NOT NULL,
+			effective_date TEXT NOT NULL,
+			frequency TEXT NOT NULL,
+			goal INTEGER NOT NULL,
+			schedule_type TEXT NOT NULL,
+			schedule_weekdays INTEGER NOT NULL DEFAULT 0,
+			schedule_interval INTEGER NOT NULL DEFAULT 0,
+			schedule_anchor TEXT NOT NULL DEFAULT '',
+			target REAL NOT NULL DEFAULT 0,
+			created_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (habit_id) REFERENCES habits(id) ON DELETE CASCADE,
+			UNIQUE(habit_id, effective_date)
+		)`,
+		`INSERT INTO habit_goal_versions (
+			habit_id, effective_date, frequency, goal,
+			schedule_type, schedule_weekdays, schedule_interval, schedule_anchor, target, created_at
+		)
+		SELECT id, substr(created_at, 1, 10), frequency, goal,
+		       schedule_type, schedule_weekdays, schedule_interval, schedule_anchor, target, CURRENT_TIMESTAMP
+		FROM habits`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
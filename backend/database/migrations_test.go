@@ -0,0 +1,271 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+	"github.com/kubaliski/habit-tracker/backend/schedule"
+)
+
+// openLegacyDatabase crea una base de datos como las anteriores al control de versiones
+func openLegacyDatabase(t *testing.T, path string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrateInitialSchema(tx); err != nil {
+		t.Fatalf("migrateInitialSchema: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+// openDatabaseAtVersion crea una base de datos con las migraciones aplicadas hasta version
+func openDatabaseAtVersion(t *testing.T, path string, version int) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all := migrations
+	migrations = migrations[:version]
+	defer func() { migrations = all }()
+
+	if err := (&SQLiteRepo{db: db}).migrate(); err != nil {
+		t.Fatalf("migrar hasta la v%d: %v", version, err)
+	}
+
+	return db
+}
+
+// execAll ejecuta sentencias de preparación de un test
+func execAll(t *testing.T, db *sql.DB, statements ...string) {
+	t.Helper()
+	for _, stmt := range statements {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+}
+
+func TestMigrationsAreSequential(t *testing.T) {
+	for i, m := range migrations {
+		if m.version != i+1 {
+			t.Errorf("la migración %d (%s) tiene la versión %d", i+1, m.description, m.version)
+		}
+		if m.up == nil || m.description == "" {
+			t.Errorf("la migración v%d está incompleta", m.version)
+		}
+	}
+}
+
+func TestMigrateNewDatabase(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "habits.db")
+
+	for i := 0; i < 2; i++ {
+		repo, err := NewSQLiteRepo(path)
+		if err != nil {
+			t.Fatalf("apertura %d: %v", i+1, err)
+		}
+
+		version, err := repo.GetSchemaVersion()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version != latestSchemaVersion() {
+			t.Errorf("apertura %d: versión del esquema = %d, se esperaba %d", i+1, version, latestSchemaVersion())
+		}
+
+		var applied int
+		if err := repo.db.QueryRow("SELECT COUNT(*) FROM schema_version").Scan(&applied); err != nil {
+			t.Fatal(err)
+		}
+		if applied != len(migrations) {
+			t.Errorf("apertura %d: %d migraciones registradas, se esperaban %d", i+1, applied, len(migrations))
+		}
+		repo.Close()
+	}
+
+	// Ni una base de datos nueva ni una ya migrada necesitan copia de seguridad
+	if backups, _ := filepath.Glob(path + ".*.bak"); len(backups) != 0 {
+		t.Errorf("copias de seguridad = %v, no se esperaba ninguna", backups)
+	}
+}
+
+func TestMigrateRejectsNewerDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "habits.db")
+	repo, err := NewSQLiteRepo(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	execAll(t, repo.db, "INSERT INTO schema_version (version, description) VALUES (1000, 'de una versión futura')")
+	repo.Close()
+
+	if _, err := NewSQLiteRepo(path); !errors.Is(err, ErrDatabaseTooNew) {
+		t.Errorf("NewSQLiteRepo() = %v, se esperaba ErrDatabaseTooNew", err)
+	}
+}
+
+func TestApplyMigrationRollsBackOnError(t *testing.T) {
+	repo := newTestRepo(t)
+	failing := migration{
+		version:     latestSchemaVersion() + 1,
+		description: "migración que falla",
+		up: func(tx *sql.Tx) error {
+			if _, err := tx.Exec("CREATE TABLE half_applied (id INTEGER)"); err != nil {
+				return err
+			}
+			return errors.New("fallo a mitad de la migración")
+		},
+	}
+
+	if err := repo.applyMigration(failing); err == nil {
+		t.Fatal("applyMigration debería devolver el error de la migración")
+	}
+
+	version, err := repo.GetSchemaVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != latestSchemaVersion() {
+		t.Errorf("versión del esquema = %d, se esperaba %d", version, latestSchemaVersion())
+	}
+	var tables int
+	if err := repo.db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'half_applied'").Scan(&tables); err != nil {
+		t.Fatal(err)
+	}
+	if tables != 0 {
+		t.Error("la tabla creada por la migración fallida no se deshizo")
+	}
+}
+
+func TestMigrateLegacyDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "habits.db")
+	db := openLegacyDatabase(t, path)
+	// La aplicación siempre ha guardado los textos vacíos como '' y no como NULL
+	execAll(t, db,
+		"INSERT INTO habits (id, name, description, category, frequency, goal, created_at) VALUES (1, 'Leer', '', '', 'daily', 2, '2026-09-01 10:00:00')",
+		"INSERT INTO habits (id, name, description, category, frequency, goal, created_at) VALUES (2, 'Correr', '', '', 'weekly', 3, '2026-09-02 10:00:00')",
+		"INSERT INTO habit_logs (habit_id, date, completed, count, notes) VALUES (1, '2026-09-03', 1, 2, '')",
+	)
+	db.Close()
+
+	repo, err := NewSQLiteRepo(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteRepo: %v", err)
+	}
+	defer repo.Close()
+
+	if version, _ := repo.GetSchemaVersion(); version != latestSchemaVersion() {
+		t.Errorf("versión del esquema = %d, se esperaba %d", version, latestSchemaVersion())
+	}
+	if backups, _ := filepath.Glob(path + ".v0-*.bak"); len(backups) != 1 {
+		t.Errorf("copias de seguridad = %v, se esperaba una de la v0", backups)
+	}
+
+	tests := []struct {
+		name     string
+		schedule models.HabitSchedule
+		since    string
+	}{
+		{name: "Leer", schedule: schedule.FromFrequency(models.FrequencyDaily, 2), since: "2026-09-01"},
+		{name: "Correr", schedule: schedule.FromFrequency(models.FrequencyWeekly, 3), since: "2026-09-02"},
+	}
+	for _, tt := range tests {
+		habit := importedHabit(t, repo, tt.name)
+		if habit.Measure != models.MeasureBoolean || habit.Avoid || habit.Schedule != tt.schedule {
+			t.Errorf("%s = %+v, se esperaba un hábito sí/no a cumplir con el calendario %+v", tt.name, habit, tt.schedule)
+		}
+
+		versions, err := repo.GetHabitGoalVersions(habit.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(versions) != 1 || versions[0].EffectiveDate != tt.since || versions[0].Goal != habit.Goal {
+			t.Errorf("versiones de %s = %+v, se esperaba su meta desde el %s", tt.name, versions, tt.since)
+		}
+	}
+
+	logs, err := repo.GetHabitLogs(1, "2026-09-03", "2026-09-03")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 1 || logs[0].Value != 2 {
+		t.Errorf("registros = %+v, se esperaba el número de veces como valor", logs)
+	}
+}
+
+func TestMigrateMoodCheckIns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "habits.db")
+	db := openDatabaseAtVersion(t, path, 4)
+	execAll(t, db,
+		`INSERT INTO mood_entries (id, date, mood_score, energy_level, anxiety_level, stress_level, sleep_hours, notes)
+		VALUES (5, '2026-09-01', 7, 6, 3, 4, 7.5, 'Buen día'), (9, '2026-09-02', 4, 3, 6, 7, 5, '')`,
+		"INSERT INTO mood_tags (mood_id, tag) VALUES (5, 'trabajo'), (5, 'deporte'), (9, 'cansancio')",
+	)
+	// Antes de la v5 solo cabe un registro por día
+	if _, err := db.Exec("INSERT INTO mood_entries (date, mood_score, notes) VALUES ('2026-09-01', 5, '')"); err == nil {
+		t.Fatal("la v4 debería rechazar un segundo registro del mismo día")
+	}
+	db.Close()
+
+	repo, err := NewSQLiteRepo(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteRepo: %v", err)
+	}
+	defer repo.Close()
+
+	entries, err := repo.GetAllMoodEntries("2026-09-01", "2026-09-02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("registros = %+v, se esperaban los 2 existentes", entries)
+	}
+	want := map[int]struct {
+		date string
+		tags int
+	}{5: {"2026-09-01", 2}, 9: {"2026-09-02", 1}}
+	for _, entry := range entries {
+		w, ok := want[entry.ID]
+		if !ok || entry.Date.Format("2006-01-02") != w.date || len(entry.Tags) != w.tags {
+			t.Errorf("registro %d = %+v, se esperaba el del %s con %d etiquetas", entry.ID, entry, w.date, w.tags)
+		}
+		if entry.Timestamp != nil || entry.Slot != "" {
+			t.Errorf("registro %d: un registro anterior a la v5 debe quedar como del día completo", entry.ID)
+		}
+	}
+
+	// Ya caben varios registros por día
+	_, err = repo.CreateMoodEntry(models.NewMoodEntryInput{Date: "2026-09-01", Slot: models.MoodSlotEvening, MoodScore: 5})
+	if err != nil {
+		t.Fatalf("CreateMoodEntry: %v", err)
+	}
+	entries, err = repo.GetAllMoodEntries("2026-09-01", "2026-09-01")
+	if err != nil || len(entries) != 2 {
+		t.Errorf("registros del 1 = %+v (%v), se esperaban 2", entries, err)
+	}
+
+	// Al reconstruir la tabla, las etiquetas siguen apuntando a sus registros
+	var tags int
+	if err := repo.db.QueryRow("SELECT COUNT(*) FROM mood_tags WHERE mood_id NOT IN (SELECT id FROM mood_entries)").Scan(&tags); err != nil {
+		t.Fatal(err)
+	}
+	if tags != 0 {
+		t.Errorf("%d etiquetas sin registro tras la migración", tags)
+	}
+}
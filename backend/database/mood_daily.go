@@ -0,0 +1,109 @@
+package database
+
+import (
+	"math"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+// ==================== AGREGADOS DIARIOS DE ESTADO DE ÁNIMO ====================
+
+// GetDailyMoods agrega los registros de estado de ánimo de cada día del rango, en orden
+// cronológico. Los días sin registros no aparecen.
+func (r *SQLiteRepo) GetDailyMoods(startDate, endDate string) ([]models.DailyMood, error) {
+	entries, err := r.GetAllMoodEntries(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// GetAllMoodEntries devuelve primero lo más reciente: recorrer al revés deja cada día en
+	// orden cronológico
+	days := []models.DailyMood{}
+	var day []models.MoodEntry
+	for i := len(entries) - 1; i >= 0; i-- {
+		if len(day) > 0 && !entries[i].Date.Equal(day[0].Date) {
+			days = append(days, aggregateDailyMood(day))
+			day = nil
+		}
+		day = append(day, entries[i])
+	}
+	if len(day) > 0 {
+		days = append(days, aggregateDailyMood(day))
+	}
+
+	return days, nil
+}
+
+// aggregateDailyMood resume los registros de un día, en orden cronológico
+func aggregateDailyMood(entries []models.MoodEntry) models.DailyMood {
+	daily := models.DailyMood{
+		Date:    entries[0].Date.Format("2006-01-02"),
+		Entries: len(entries),
+		Tags:    []string{},
+	}
+
+	var mood, energy, anxiety, stress []float64
+	seenTags := make(map[string]bool)
+	for _, entry := range entries {
+		mood = append(mood, float64(entry.MoodScore))
+		energy = appendLevel(energy, entry.EnergyLevel)
+		anxiety = appendLevel(anxiety, entry.AnxietyLevel)
+		stress = appendLevel(stress, entry.StressLevel)
+
+		if entry.SleepHours > 0 {
+			daily.SleepHours = entry.SleepHours
+		}
+
+		for _, tag := range entry.Tags {
+			if !seenTags[tag] {
+				seenTags[tag] = true
+				daily.Tags = append(daily.Tags, tag)
+			}
+		}
+	}
+
+	daily.MoodScore = *aggregateMood(mood)
+	daily.EnergyLevel = aggregateMood(energy)
+	daily.AnxietyLevel = aggregateMood(anxiety)
+	daily.StressLevel = aggregateMood(stress)
+
+	return daily
+}
+
+// appendLevel añade un nivel opcional (0 = no registrado) a su serie
+func appendLevel(values []float64, level int) []float64 {
+	if level == 0 {
+		return values
+	}
+	return append(values, float64(level))
+}
+
+// aggregateMood calcula media, mínimo, máximo y último valor; nil si no hay valores
+func aggregateMood(values []float64) *models.MoodAggregate {
+	if len(values) == 0 {
+		return nil
+	}
+
+	aggregate := models.MoodAggregate{
+		Count: len(values),
+		Min:   math.Inf(1),
+		Max:   math.Inf(-1),
+		Last:  values[len(values)-1],
+	}
+	for _, v := range values {
+		aggregate.Mean += v
+		aggregate.Min = math.Min(aggregate.Min, v)
+		aggregate.Max = math.Max(aggregate.Max, v)
+	}
+	aggregate.Mean /= float64(len(values))
+
+	return &aggregate
+}
+
+// meanOf devuelve la media de un agregado opcional, o 0 si el día no lo registró
+func meanOf(aggregate *models.MoodAggregate) float64 {
+	if aggregate == nil {
+		return 0
+	}
+	return aggregate.Mean
+}
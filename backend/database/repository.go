@@ -1,6 +1,8 @@
 package database
 
 import (
+	"time"
+
 	"github.com/kubaliski/habit-tracker/backend/models"
 )
 
@@ -12,6 +14,9 @@ type Repository interface {
 	GetAllHabits() ([]models.Habit, error)
 	UpdateHabit(id int, habit models.UpdateHabitInput) error
 	DeleteHabit(id int) error
+	GetHabitAt(id int, date string) (models.Habit, error)
+	GetHabitGoalVersions(habitID int) ([]models.HabitGoalVersion, error)
+	GetDueHabit(habitID int, date string) (models.DueHabit, bool, error)
 
 	// Métodos para registros de hábitos
 	LogHabit(habitID int, log models.NewHabitLogInput) error
@@ -19,12 +24,36 @@ type Repository interface {
 	UpdateHabitLog(id int, log models.NewHabitLogInput) error
 	DeleteHabitLog(id int) error
 
+	// Métodos para recaídas en hábitos a evitar
+	CreateHabitSlip(habitID int, slip models.NewHabitSlipInput) (int, error)
+	GetHabitSlip(id int) (models.HabitSlip, error)
+	GetHabitSlips(habitID int, startDate, endDate string) ([]models.HabitSlip, error)
+	DeleteHabitSlip(id int) error
+
+	// Métodos para días justificados
+	CreateExcuse(excuse models.NewExcuseInput) (int, error)
+	GetExcuse(id int) (models.Excuse, error)
+	GetExcuses(habitID int, startDate, endDate string) ([]models.Excuse, error)
+	DeleteExcuse(id int) error
+
+	// Métodos para recordatorios de hábitos
+	CreateHabitReminder(habitID int, reminder models.NewHabitReminderInput) (int, error)
+	GetHabitReminder(id int) (models.HabitReminder, error)
+	GetHabitReminders(habitID int) ([]models.HabitReminder, error)
+	GetActiveHabitReminders() ([]models.HabitReminder, error)
+	UpdateHabitReminder(id int, reminder models.UpdateHabitReminderInput) error
+	DeleteHabitReminder(id int) error
+	SnoozeHabitReminder(id int, until time.Time) error
+	DismissHabitReminder(id int, date string) error
+	MarkHabitReminderFired(id int, date string) error
+
 	// Métodos para estado de ánimo
 	CreateMoodEntry(mood models.NewMoodEntryInput) (int, error)
 	GetMoodEntry(id int) (models.MoodEntry, error)
 	GetAllMoodEntries(startDate, endDate string) ([]models.MoodEntry, error)
 	UpdateMoodEntry(id int, mood models.UpdateMoodEntryInput) error
 	DeleteMoodEntry(id int) error
+	GetDailyMoods(startDate, endDate string) ([]models.DailyMood, error)
 
 	// Métodos para tipos de bebidas con cafeína
 	CreateCaffeineBeverage(beverage models.NewCaffeineBeverageInput) (int, error)
@@ -42,13 +71,48 @@ type Repository interface {
 	DeleteCaffeineIntake(id int) error
 	GetDailyCaffeineTotal(date string) (float64, error)
 
+	// Métodos para el modelo de metabolismo de cafeína
+	GetCaffeineSettings() (models.CaffeineSettings, error)
+	UpdateCaffeineSettings(input models.UpdateCaffeineSettingsInput) error
+	GetCaffeineLevelAt(at time.Time) (float64, error)
+	GetCaffeineCurve(date string, resolutionMinutes int) (models.CaffeineCurve, error)
+	EstimateCaffeineBelow(threshold float64, from time.Time) (models.CaffeineThresholdEstimate, error)
+	ProjectBedtimeCaffeine(candidate *models.NewCaffeineIntakeInput) (models.BedtimeCaffeineProjection, error)
+
+	// Métodos para límites de consumo de cafeína
+	GetCaffeineLimits() (models.CaffeineLimits, error)
+	UpdateCaffeineLimits(input models.UpdateCaffeineLimitsInput) error
+	GetCaffeineBudget(date string) (models.CaffeineBudget, error)
+
+	// Configuración del servidor REST local
+	GetServerSettings() (models.ServerSettings, error)
+	UpdateServerSettings(input models.UpdateServerSettingsInput) error
+
+	// Zona horaria del usuario y asignación de instantes a días
+	GetTimeSettings() (models.TimeSettings, error)
+	UpdateTimeSettings(input models.UpdateTimeSettingsInput) error
+	Now() time.Time
+	Today() string
+	DayOf(t time.Time) string
+
 	// Métodos para estadísticas
-	GetHabitStats(habitID int, period string) (map[string]interface{}, error)
-	GetMoodStats(period string) (map[string]interface{}, error)
-	GetCaffeineStats(period string) (map[string]interface{}, error)
-	GetCorrelationStats() (map[string]interface{}, error)
+	GetHabitStats(habitID int, period models.DateRange) (models.HabitStats, error)
+	GetHabitStreaks(habitID int) (models.HabitStreaks, error)
+	GetMoodStats(period models.DateRange) (models.MoodStats, error)
+	GetCaffeineStats(period models.DateRange) (models.CaffeineStats, error)
+	GetCorrelationStats(period models.DateRange) (models.CorrelationStats, error)
+	GetHabitMoodCorrelations(period models.DateRange) (models.HabitMoodAnalysis, error)
+	GetLaggedCorrelations(period models.DateRange, maxLag, cutoffHour int) (models.LaggedCorrelationMatrix, error)
+
+	// Importación de datos
+	ImportDocument(doc models.ExportDocument, strategy string, dryRun bool) (models.ImportReport, error)
+
+	// Copias de seguridad
+	BackupTo(destPath string) error
+	RestoreFrom(srcPath string) error
 
 	// Inicialización y cierre
+	GetSchemaVersion() (int, error)
 	InitializeDefaultCaffeineBeverages() error
 	Close() error
 }
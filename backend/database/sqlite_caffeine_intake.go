@@ -19,13 +19,7 @@ func (r *SQLiteRepo) CreateCaffeineIntake(input models.NewCaffeineIntakeInput) (
 	}
 
 	// Calcular el total de cafeína basado en la cantidad y el contenido de cafeína de la bebida
-	// Fórmula correcta: (cantidad * contenido_cafeína) / unidad_estándar_valor
-	totalCaffeine := (input.Amount * beverage.CaffeineContent)
-
-	// Si el input ya tenía un valor para totalCaffeine, respetarlo
-	if input.TotalCaffeine > 0 {
-		totalCaffeine = input.TotalCaffeine
-	}
+	totalCaffeine := intakeCaffeine(input, beverage)
 
 	// Establecer la unidad predeterminada si no se proporciona
 	unit := input.Unit
@@ -41,14 +35,15 @@ func (r *SQLiteRepo) CreateCaffeineIntake(input models.NewCaffeineIntakeInput) (
 	// Insertar el registro - CORREGIDO: añadir beverage_name en los campos y valores
 	query := `
         INSERT INTO caffeine_intake (
-            timestamp, beverage_id, beverage_name, amount, unit, total_caffeine,
+            timestamp, local_date, beverage_id, beverage_name, amount, unit, total_caffeine,
             perceived_effects, related_activity, notes, created_at
-        ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+        ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
     `
 
 	result, err := r.db.Exec(
 		query,
-		timestamp,
+		timestamp.UTC(),
+		r.DayOf(timestamp),
 		input.BeverageID,
 		beverage.Name, // AÑADIDO: pasar el nombre de la bebida
 		input.Amount,
@@ -71,6 +66,17 @@ func (r *SQLiteRepo) CreateCaffeineIntake(input models.NewCaffeineIntakeInput) (
 	return int(id), nil
 }
 
+// intakeCaffeine calcula los mg de cafeína de un consumo a partir de la bebida.
+// Si el input ya trae un valor de total_caffeine, se respeta.
+func intakeCaffeine(input models.NewCaffeineIntakeInput, beverage models.CaffeineBeverage) float64 {
+	if input.TotalCaffeine > 0 {
+		return input.TotalCaffeine
+	}
+
+	// Fórmula correcta: (cantidad * contenido_cafeína) / unidad_estándar_valor
+	return input.Amount * beverage.CaffeineContent
+}
+
 // GetCaffeineIntake obtiene un registro de consumo de cafeína por su ID
 func (r *SQLiteRepo) GetCaffeineIntake(id int) (models.CaffeineIntake, error) {
 	query := `
@@ -100,8 +106,8 @@ func (r *SQLiteRepo) GetCaffeineIntake(id int) (models.CaffeineIntake, error) {
 		return models.CaffeineIntake{}, fmt.Errorf("error al obtener registro de consumo de cafeína: %w", err)
 	}
 
-	// Convertir valores
-	intake.Timestamp, _ = time.Parse(time.RFC3339, timestamp)
+	// Convertir valores (los instantes se guardan en UTC y se muestran en la zona del usuario)
+	intake.Timestamp = r.localInstant(timestamp)
 	intake.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
 
 	return intake, nil
@@ -113,7 +119,7 @@ func (r *SQLiteRepo) GetCaffeineIntakeByDay(date string) ([]models.CaffeineIntak
 		SELECT id, timestamp, beverage_id, beverage_name, amount, unit,
 		       total_caffeine, perceived_effects, related_activity, notes, created_at
 		FROM caffeine_intake
-		WHERE DATE(timestamp) = DATE(?)
+		WHERE local_date = DATE(?)
 		ORDER BY timestamp DESC
 	`
 
@@ -144,8 +150,8 @@ func (r *SQLiteRepo) GetCaffeineIntakeByDay(date string) ([]models.CaffeineIntak
 			return nil, fmt.Errorf("error al escanear consumo de cafeína: %w", err)
 		}
 
-		// Convertir valores
-		intake.Timestamp, _ = time.Parse(time.RFC3339, timestamp)
+		// Convertir valores (los instantes se guardan en UTC y se muestran en la zona del usuario)
+		intake.Timestamp = r.localInstant(timestamp)
 		intake.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
 
 		intakes = append(intakes, intake)
@@ -164,7 +170,7 @@ func (r *SQLiteRepo) GetCaffeineIntakeRange(startDate, endDate string) ([]models
 		SELECT id, timestamp, beverage_id, beverage_name, amount, unit,
 		       total_caffeine, perceived_effects, related_activity, notes, created_at
 		FROM caffeine_intake
-		WHERE DATE(timestamp) >= DATE(?) AND DATE(timestamp) <= DATE(?)
+		WHERE local_date >= DATE(?) AND local_date <= DATE(?)
 		ORDER BY timestamp DESC
 	`
 
@@ -195,8 +201,8 @@ func (r *SQLiteRepo) GetCaffeineIntakeRange(startDate, endDate string) ([]models
 			return nil, fmt.Errorf("error al escanear consumo de cafeína: %w", err)
 		}
 
-		// Convertir valores
-		intake.Timestamp, _ = time.Parse(time.RFC3339, timestamp)
+		// Convertir valores (los instantes se guardan en UTC y se muestran en la zona del usuario)
+		intake.Timestamp = r.localInstant(timestamp)
 		intake.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
 
 		intakes = append(intakes, intake)
@@ -219,8 +225,13 @@ func (r *SQLiteRepo) UpdateCaffeineIntake(id int, input models.UpdateCaffeineInt
 	updateFields := []string{}
 
 	if input.Timestamp != "" {
-		updateFields = append(updateFields, "timestamp = ?")
-		params = append(params, input.Timestamp)
+		timestamp, err := time.Parse(time.RFC3339, input.Timestamp)
+		if err != nil {
+			return fmt.Errorf("error al parsear timestamp: %w", err)
+		}
+
+		updateFields = append(updateFields, "timestamp = ?", "local_date = ?")
+		params = append(params, timestamp.UTC(), r.DayOf(timestamp))
 	}
 
 	if input.BeverageID > 0 {
@@ -334,7 +345,7 @@ func (r *SQLiteRepo) GetDailyCaffeineTotal(date string) (float64, error) {
 	query := `
 		SELECT COALESCE(SUM(total_caffeine), 0)
 		FROM caffeine_intake
-		WHERE DATE(timestamp) = DATE(?)
+		WHERE local_date = DATE(?)
 	`
 
 	err := r.db.QueryRow(query, date).Scan(&total)
@@ -0,0 +1,157 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+// ==================== MÉTODOS PARA DÍAS JUSTIFICADOS ====================
+
+// CreateExcuse justifica un día o un rango de días, para un hábito o para todos
+func (r *SQLiteRepo) CreateExcuse(excuse models.NewExcuseInput) (int, error) {
+	query := `
+		INSERT INTO excuses (habit_id, start_date, end_date, reason, notes, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.Exec(query, excuse.HabitID, excuse.StartDate, excuse.EndDate, excuse.Reason, excuse.Notes, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("error al justificar días: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("error al obtener ID: %w", err)
+	}
+
+	return int(id), nil
+}
+
+// GetExcuse obtiene un día justificado por su ID
+func (r *SQLiteRepo) GetExcuse(id int) (models.Excuse, error) {
+	query := `
+		SELECT id, habit_id, start_date, end_date, reason, COALESCE(notes, ''), created_at
+		FROM excuses
+		WHERE id = ?
+	`
+
+	excuse, err := scanExcuse(r.db.QueryRow(query, id))
+	if err != nil {
+		return models.Excuse{}, fmt.Errorf("error al obtener día justificado: %w", err)
+	}
+
+	return excuse, nil
+}
+
+// GetExcuses obtiene los días justificados que se solapan con el rango, ordenados por fecha.
+// Con habitID > 0 solo devuelve los de ese hábito y los que afectan a todos; con 0, todos.
+func (r *SQLiteRepo) GetExcuses(habitID int, startDate, endDate string) ([]models.Excuse, error) {
+	query := `
+		SELECT id, habit_id, start_date, end_date, reason, COALESCE(notes, ''), created_at
+		FROM excuses
+		WHERE start_date <= ? AND end_date >= ? AND (? = 0 OR habit_id IS NULL OR habit_id = ?)
+		ORDER BY start_date, id
+	`
+
+	rows, err := r.db.Query(query, endDate, startDate, habitID, habitID)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar días justificados: %w", err)
+	}
+	defer rows.Close()
+
+	var excuses []models.Excuse
+	for rows.Next() {
+		excuse, err := scanExcuse(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear día justificado: %w", err)
+		}
+		excuses = append(excuses, excuse)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar días justificados: %w", err)
+	}
+
+	return excuses, nil
+}
+
+// DeleteExcuse elimina un día justificado
+func (r *SQLiteRepo) DeleteExcuse(id int) error {
+	query := "DELETE FROM excuses WHERE id = ?"
+
+	_, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("error al eliminar día justificado: %w", err)
+	}
+
+	return nil
+}
+
+// periodExcusedDates devuelve los días justificados de un hábito en las semanas y meses que tocan
+// el rango, enteros: en semanas y meses la meta depende de todo el período
+func (r *SQLiteRepo) periodExcusedDates(habitID int, start, end time.Time) (map[string]bool, error) {
+	first := periodStart(start, "week")
+	if month := periodStart(start, "month"); month.Before(first) {
+		first = month
+	}
+	last := nextPeriod(periodStart(end, "week"), "week").AddDate(0, 0, -1)
+	if month := nextPeriod(periodStart(end, "month"), "month").AddDate(0, 0, -1); month.After(last) {
+		last = month
+	}
+
+	return r.excusedDates(habitID, first.Format("2006-01-02"), last.Format("2006-01-02"))
+}
+
+// excusedDates devuelve los días justificados de un hábito dentro del rango (YYYY-MM-DD)
+func (r *SQLiteRepo) excusedDates(habitID int, startDate, endDate string) (map[string]bool, error) {
+	excuses, err := r.GetExcuses(habitID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	dates := make(map[string]bool)
+	for _, excuse := range excuses {
+		first, err1 := time.Parse("2006-01-02", excuse.StartDate)
+		last, err2 := time.Parse("2006-01-02", excuse.EndDate)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+			if date := day.Format("2006-01-02"); date >= startDate && date <= endDate {
+				dates[date] = true
+			}
+		}
+	}
+
+	return dates, nil
+}
+
+// scanExcuse convierte una fila en un día justificado
+func scanExcuse(row rowScanner) (models.Excuse, error) {
+	var excuse models.Excuse
+	var habitID sql.NullInt64
+	var createdAt string
+
+	if err := row.Scan(
+		&excuse.ID,
+		&habitID,
+		&excuse.StartDate,
+		&excuse.EndDate,
+		&excuse.Reason,
+		&excuse.Notes,
+		&createdAt,
+	); err != nil {
+		return models.Excuse{}, err
+	}
+
+	if habitID.Valid {
+		id := int(habitID.Int64)
+		excuse.HabitID = &id
+	}
+	excuse.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+
+	return excuse, nil
+}
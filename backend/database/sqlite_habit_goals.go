@@ -0,0 +1,231 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+	"github.com/kubaliski/habit-tracker/backend/schedule"
+)
+
+// ==================== MÉTODOS PARA EL HISTORIAL DE METAS ====================
+
+// GetHabitGoalVersions obtiene el historial de metas de un hábito, de la más antigua a la más
+// reciente
+func (r *SQLiteRepo) GetHabitGoalVersions(habitID int) ([]models.HabitGoalVersion, error) {
+	query := `
+		SELECT id, habit_id, effective_date, frequency, goal,
+		       schedule_type, schedule_weekdays, schedule_interval, schedule_anchor, target, created_at
+		FROM habit_goal_versions
+		WHERE habit_id = ?
+		ORDER BY effective_date
+	`
+
+	rows, err := r.db.Query(query, habitID)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar el historial de metas: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []models.HabitGoalVersion
+	for rows.Next() {
+		var version models.HabitGoalVersion
+		var createdAt string
+
+		if err := rows.Scan(
+			&version.ID,
+			&version.HabitID,
+			&version.EffectiveDate,
+			&version.Frequency,
+			&version.Goal,
+			&version.Schedule.Type,
+			&version.Schedule.Weekdays,
+			&version.Schedule.Interval,
+			&version.Schedule.Anchor,
+			&version.Target,
+			&createdAt,
+		); err != nil {
+			return nil, fmt.Errorf("error al escanear versión de meta: %w", err)
+		}
+
+		version.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		if schedule.IsQuota(version.Schedule) {
+			version.Schedule.Times = version.Goal
+		}
+
+		versions = append(versions, version)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar el historial de metas: %w", err)
+	}
+
+	return versions, nil
+}
+
+// GetHabitAt obtiene un hábito con la meta vigente en una fecha (YYYY-MM-DD)
+func (r *SQLiteRepo) GetHabitAt(id int, date string) (models.Habit, error) {
+	habit, err := r.GetHabit(id)
+	if err != nil {
+		return models.Habit{}, err
+	}
+
+	versions, err := r.GetHabitGoalVersions(id)
+	if err != nil {
+		return models.Habit{}, err
+	}
+
+	if version, ok := goalVersionAt(versions, date); ok {
+		habit = habit.WithGoal(version)
+	}
+
+	return habit, nil
+}
+
+// habitGoalVersions devuelve el historial de metas de un hábito; si no tiene, su meta actual
+// como única versión
+func (r *SQLiteRepo) habitGoalVersions(habit models.Habit) ([]models.HabitGoalVersion, error) {
+	versions, err := r.GetHabitGoalVersions(habit.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(versions) == 0 {
+		versions = []models.HabitGoalVersion{goalVersionOf(habit, "")}
+	}
+
+	return versions, nil
+}
+
+// goalVersionAt devuelve la versión vigente en una fecha: la más reciente que no sea posterior
+// o, si la fecha es anterior a todas, la primera. versions debe estar ordenado por fecha.
+func goalVersionAt(versions []models.HabitGoalVersion, date string) (models.HabitGoalVersion, bool) {
+	if len(versions) == 0 {
+		return models.HabitGoalVersion{}, false
+	}
+
+	i := sort.Search(len(versions), func(i int) bool { return versions[i].EffectiveDate > date })
+	if i == 0 {
+		return versions[0], true
+	}
+	return versions[i-1], true
+}
+
+// goalVersionOf devuelve la meta actual de un hábito como versión vigente desde una fecha
+func goalVersionOf(habit models.Habit, effectiveDate string) models.HabitGoalVersion {
+	return models.HabitGoalVersion{
+		HabitID:       habit.ID,
+		EffectiveDate: effectiveDate,
+		Frequency:     habit.Frequency,
+		Goal:          habit.Goal,
+		Schedule:      habit.Schedule,
+		Target:        habit.Target,
+	}
+}
+
+// sameGoal indica si dos versiones piden lo mismo
+func sameGoal(a, b models.HabitGoalVersion) bool {
+	return a.Frequency == b.Frequency && a.Goal == b.Goal && a.Target == b.Target &&
+		a.Schedule.Type == b.Schedule.Type && a.Schedule.Weekdays == b.Schedule.Weekdays &&
+		a.Schedule.Interval == b.Schedule.Interval && a.Schedule.Anchor == b.Schedule.Anchor
+}
+
+// saveGoalVersion guarda una versión de la meta, sustituyendo la que hubiera en la misma fecha
+func saveGoalVersion(tx *sql.Tx, version models.HabitGoalVersion) error {
+	query := `
+		INSERT INTO habit_goal_versions (
+			habit_id, effective_date, frequency, goal,
+			schedule_type, schedule_weekdays, schedule_interval, schedule_anchor, target, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(habit_id, effective_date) DO UPDATE SET
+			frequency = excluded.frequency,
+			goal = excluded.goal,
+			schedule_type = excluded.schedule_type,
+			schedule_weekdays = excluded.schedule_weekdays,
+			schedule_interval = excluded.schedule_interval,
+			schedule_anchor = excluded.schedule_anchor,
+			target = excluded.target
+	`
+
+	_, err := tx.Exec(
+		query,
+		version.HabitID,
+		version.EffectiveDate,
+		version.Frequency,
+		version.Goal,
+		version.Schedule.Type,
+		version.Schedule.Weekdays,
+		version.Schedule.Interval,
+		version.Schedule.Anchor,
+		version.Target,
+		time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("error al guardar versión de meta: %w", err)
+	}
+
+	return nil
+}
+
+// reevaluateHabitLogs recalcula si se cumplió la meta en los registros de un hábito desde from
+// hasta el día anterior a until (sin límite si está vacío), con la meta que tiene el hábito
+// indicado. Los hábitos sin umbral no se tocan.
+func reevaluateHabitLogs(tx *sql.Tx, habit models.Habit, from, until string) error {
+	if _, decided := habit.MeetsGoal(0, 0); !decided || habit.Avoid {
+		return nil
+	}
+
+	rows, err := tx.Query(
+		"SELECT id, count, value FROM habit_logs WHERE habit_id = ? AND date >= ? AND (? = '' OR date < ?)",
+		habit.ID, from, until, until,
+	)
+	if err != nil {
+		return fmt.Errorf("error al consultar registros del hábito: %w", err)
+	}
+
+	completed := make(map[int]bool)
+	for rows.Next() {
+		var id, count int
+		var value float64
+		if err := rows.Scan(&id, &count, &value); err != nil {
+			rows.Close()
+			return fmt.Errorf("error al escanear registro del hábito: %w", err)
+		}
+		completed[id], _ = habit.MeetsGoal(count, value)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error al iterar registros del hábito: %w", err)
+	}
+
+	for id, done := range completed {
+		if _, err := tx.Exec("UPDATE habit_logs SET completed = ? WHERE id = ?", boolToInt(done), id); err != nil {
+			return fmt.Errorf("error al actualizar registro del hábito: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// syncHabitGoal copia en el hábito la meta de su versión más reciente, que es la que se muestra
+// como meta actual
+func syncHabitGoal(tx *sql.Tx, habitID int) error {
+	query := `
+		UPDATE habits SET (frequency, goal, schedule_type, schedule_weekdays, schedule_interval, schedule_anchor, target) = (
+			SELECT frequency, goal, schedule_type, schedule_weekdays, schedule_interval, schedule_anchor, target
+			FROM habit_goal_versions
+			WHERE habit_id = habits.id
+			ORDER BY effective_date DESC
+			LIMIT 1
+		)
+		WHERE id = ? AND EXISTS (SELECT 1 FROM habit_goal_versions WHERE habit_id = habits.id)
+	`
+
+	if _, err := tx.Exec(query, habitID); err != nil {
+		return fmt.Errorf("error al actualizar la meta actual del hábito: %w", err)
+	}
+
+	return nil
+}
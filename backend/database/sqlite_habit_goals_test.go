@@ -0,0 +1,121 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+// newTestRepo abre una base de datos nueva en un directorio temporal
+func newTestRepo(t *testing.T) *SQLiteRepo {
+	t.Helper()
+	repo, err := NewSQLiteRepo(filepath.Join(t.TempDir(), "habits.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteRepo: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+func TestGoalVersionAt(t *testing.T) {
+	versions := []models.HabitGoalVersion{
+		{EffectiveDate: "2026-09-01", Goal: 1},
+		{EffectiveDate: "2026-09-10", Goal: 2},
+		{EffectiveDate: "2026-09-20", Goal: 3},
+	}
+
+	tests := []struct {
+		date string
+		want int
+	}{
+		{date: "2026-08-15", want: 1}, // antes de la primera: la primera
+		{date: "2026-09-01", want: 1},
+		{date: "2026-09-09", want: 1},
+		{date: "2026-09-10", want: 2},
+		{date: "2026-09-19", want: 2},
+		{date: "2026-09-20", want: 3},
+		{date: "2026-12-31", want: 3},
+	}
+
+	for _, tt := range tests {
+		version, ok := goalVersionAt(versions, tt.date)
+		if !ok || version.Goal != tt.want {
+			t.Errorf("goalVersionAt(%s) = %d, se esperaba %d", tt.date, version.Goal, tt.want)
+		}
+	}
+
+	if _, ok := goalVersionAt(nil, "2026-09-01"); ok {
+		t.Error("goalVersionAt sin versiones debería indicar que no hay meta")
+	}
+}
+
+func TestUpdateHabitBackdatedGoalKeepsLaterVersions(t *testing.T) {
+	repo := newTestRepo(t)
+
+	id, err := repo.CreateHabit(models.NewHabitInput{
+		Name: "Flexiones", Frequency: models.FrequencyDaily, Goal: 1, Measure: models.MeasureCount,
+	})
+	if err != nil {
+		t.Fatalf("CreateHabit: %v", err)
+	}
+
+	// El hábito existe desde el 1 de septiembre, con su meta inicial
+	if _, err := repo.db.Exec("UPDATE habits SET created_at = ? WHERE id = ?", "2026-09-01T08:00:00Z", id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.db.Exec("UPDATE habit_goal_versions SET effective_date = ? WHERE habit_id = ?", "2026-09-01", id); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, date := range []string{"2026-09-05", "2026-09-12", "2026-09-22"} {
+		if err := repo.LogHabit(id, models.NewHabitLogInput{Date: date, Completed: true, Count: 2}); err != nil {
+			t.Fatalf("LogHabit(%s): %v", date, err)
+		}
+	}
+
+	// Primero la meta sube a 3 desde el 20 y después, con fecha anterior, a 2 desde el 10
+	if err := repo.UpdateHabit(id, models.UpdateHabitInput{Goal: 3, EffectiveDate: "2026-09-20"}); err != nil {
+		t.Fatalf("UpdateHabit(20): %v", err)
+	}
+	if err := repo.UpdateHabit(id, models.UpdateHabitInput{Goal: 2, EffectiveDate: "2026-09-10"}); err != nil {
+		t.Fatalf("UpdateHabit(10): %v", err)
+	}
+
+	versions, err := repo.GetHabitGoalVersions(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, v := range versions {
+		got = append(got, v.EffectiveDate)
+	}
+	want := []string{"2026-09-01", "2026-09-10", "2026-09-20"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Fatalf("versiones = %v, se esperaba %v", got, want)
+	}
+
+	habit, err := repo.GetHabit(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if habit.Goal != 3 {
+		t.Errorf("meta actual = %d, se esperaba la de la última versión (3)", habit.Goal)
+	}
+
+	// Cada registro queda evaluado con la meta vigente en su día
+	logs, err := repo.GetHabitLogs(id, "2026-09-01", "2026-09-30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	completed := make(map[string]bool)
+	for _, log := range logs {
+		completed[log.Date.Format("2006-01-02")] = log.Completed
+	}
+	wantCompleted := map[string]bool{"2026-09-05": true, "2026-09-12": true, "2026-09-22": false}
+	for date, want := range wantCompleted {
+		if completed[date] != want {
+			t.Errorf("registro del %s completado = %v, se esperaba %v", date, completed[date], want)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+// ==================== MÉTODOS PARA RECORDATORIOS DE HÁBITOS ====================
+
+// CreateHabitReminder crea un nuevo recordatorio para un hábito
+func (r *SQLiteRepo) CreateHabitReminder(habitID int, reminder models.NewHabitReminderInput) (int, error) {
+	query := `
+		INSERT INTO habit_reminders (habit_id, time, weekdays, active, created_at)
+		VALUES (?, ?, ?, 1, ?)
+	`
+
+	weekdays := reminder.Weekdays
+	if weekdays == 0 {
+		weekdays = models.AllWeekdays
+	}
+
+	result, err := r.db.Exec(query, habitID, reminder.Time, weekdays, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("error al crear recordatorio: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("error al obtener ID: %w", err)
+	}
+
+	return int(id), nil
+}
+
+// GetHabitReminder obtiene un recordatorio por su ID
+func (r *SQLiteRepo) GetHabitReminder(id int) (models.HabitReminder, error) {
+	query := `
+		SELECT id, habit_id, time, weekdays, active, snoozed_until, dismissed_on, last_fired_on, created_at
+		FROM habit_reminders
+		WHERE id = ?
+	`
+
+	reminder, err := scanHabitReminder(r.db.QueryRow(query, id))
+	if err != nil {
+		return models.HabitReminder{}, fmt.Errorf("error al obtener recordatorio: %w", err)
+	}
+
+	return reminder, nil
+}
+
+// GetHabitReminders obtiene los recordatorios de un hábito
+func (r *SQLiteRepo) GetHabitReminders(habitID int) ([]models.HabitReminder, error) {
+	query := `
+		SELECT id, habit_id, time, weekdays, active, snoozed_until, dismissed_on, last_fired_on, created_at
+		FROM habit_reminders
+		WHERE habit_id = ?
+		ORDER BY time
+	`
+
+	return r.queryHabitReminders(query, habitID)
+}
+
+// GetActiveHabitReminders obtiene los recordatorios activos de hábitos activos
+func (r *SQLiteRepo) GetActiveHabitReminders() ([]models.HabitReminder, error) {
+	query := `
+		SELECT hr.id, hr.habit_id, hr.time, hr.weekdays, hr.active, hr.snoozed_until,
+		       hr.dismissed_on, hr.last_fired_on, hr.created_at
+		FROM habit_reminders hr
+		JOIN habits h ON h.id = hr.habit_id
+		WHERE hr.active = 1 AND h.active = 1
+		ORDER BY hr.time
+	`
+
+	return r.queryHabitReminders(query)
+}
+
+// UpdateHabitReminder actualiza un recordatorio existente
+func (r *SQLiteRepo) UpdateHabitReminder(id int, reminder models.UpdateHabitReminderInput) error {
+	// Construir la consulta dinámicamente basada en los campos proporcionados
+	updates := []string{}
+	args := []interface{}{}
+
+	if reminder.Time != "" {
+		updates = append(updates, "time = ?")
+		args = append(args, reminder.Time)
+	}
+
+	if reminder.Weekdays > 0 {
+		updates = append(updates, "weekdays = ?")
+		args = append(args, reminder.Weekdays)
+	}
+
+	if reminder.Active != nil {
+		updates = append(updates, "active = ?")
+		if *reminder.Active {
+			args = append(args, 1)
+		} else {
+			args = append(args, 0)
+		}
+	}
+
+	// Si no hay nada que actualizar, salir
+	if len(updates) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf("UPDATE habit_reminders SET %s WHERE id = ?", strings.Join(updates, ", "))
+	args = append(args, id)
+
+	_, err := r.db.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("error al actualizar recordatorio: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteHabitReminder elimina un recordatorio
+func (r *SQLiteRepo) DeleteHabitReminder(id int) error {
+	query := "DELETE FROM habit_reminders WHERE id = ?"
+
+	_, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("error al eliminar recordatorio: %w", err)
+	}
+
+	return nil
+}
+
+// SnoozeHabitReminder aplaza un recordatorio hasta el instante indicado
+func (r *SQLiteRepo) SnoozeHabitReminder(id int, until time.Time) error {
+	query := "UPDATE habit_reminders SET snoozed_until = ?, dismissed_on = NULL WHERE id = ?"
+
+	_, err := r.db.Exec(query, until, id)
+	if err != nil {
+		return fmt.Errorf("error al aplazar recordatorio: %w", err)
+	}
+
+	return nil
+}
+
+// DismissHabitReminder descarta un recordatorio para la fecha indicada
+func (r *SQLiteRepo) DismissHabitReminder(id int, date string) error {
+	query := "UPDATE habit_reminders SET dismissed_on = ?, snoozed_until = NULL WHERE id = ?"
+
+	_, err := r.db.Exec(query, date, id)
+	if err != nil {
+		return fmt.Errorf("error al descartar recordatorio: %w", err)
+	}
+
+	return nil
+}
+
+// MarkHabitReminderFired registra que un recordatorio se ha disparado en una fecha
+func (r *SQLiteRepo) MarkHabitReminderFired(id int, date string) error {
+	query := "UPDATE habit_reminders SET last_fired_on = ?, snoozed_until = NULL WHERE id = ?"
+
+	_, err := r.db.Exec(query, date, id)
+	if err != nil {
+		return fmt.Errorf("error al registrar disparo de recordatorio: %w", err)
+	}
+
+	return nil
+}
+
+// queryHabitReminders ejecuta una consulta que devuelve recordatorios
+func (r *SQLiteRepo) queryHabitReminders(query string, args ...interface{}) ([]models.HabitReminder, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar recordatorios: %w", err)
+	}
+	defer rows.Close()
+
+	var reminders []models.HabitReminder
+	for rows.Next() {
+		reminder, err := scanHabitReminder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear recordatorio: %w", err)
+		}
+		reminders = append(reminders, reminder)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar recordatorios: %w", err)
+	}
+
+	return reminders, nil
+}
+
+// rowScanner abstrae sql.Row y sql.Rows para reutilizar el escaneo
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanHabitReminder convierte una fila en un recordatorio
+func scanHabitReminder(row rowScanner) (models.HabitReminder, error) {
+	var reminder models.HabitReminder
+	var activeInt int
+	var snoozedUntil sql.NullTime
+	var dismissedOn, lastFiredOn sql.NullString
+	var createdAt string
+
+	if err := row.Scan(
+		&reminder.ID,
+		&reminder.HabitID,
+		&reminder.Time,
+		&reminder.Weekdays,
+		&activeInt,
+		&snoozedUntil,
+		&dismissedOn,
+		&lastFiredOn,
+		&createdAt,
+	); err != nil {
+		return models.HabitReminder{}, err
+	}
+
+	// Convertir valores
+	reminder.Active = activeInt == 1
+	if snoozedUntil.Valid {
+		until := snoozedUntil.Time
+		reminder.SnoozedUntil = &until
+	}
+	reminder.DismissedOn = dismissedOn.String
+	reminder.LastFiredOn = lastFiredOn.String
+	reminder.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+
+	return reminder, nil
+}
@@ -0,0 +1,118 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+// ==================== MÉTODOS PARA RECAÍDAS EN HÁBITOS A EVITAR ====================
+
+// CreateHabitSlip registra una recaída en un hábito a evitar
+func (r *SQLiteRepo) CreateHabitSlip(habitID int, slip models.NewHabitSlipInput) (int, error) {
+	timestamp, err := time.Parse(time.RFC3339, slip.Timestamp)
+	if err != nil {
+		return 0, fmt.Errorf("error al parsear timestamp: %w", err)
+	}
+
+	query := `
+		INSERT INTO habit_slips (habit_id, timestamp, local_date, notes, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.Exec(query, habitID, timestamp.UTC(), r.DayOf(timestamp), slip.Notes, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("error al registrar recaída: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("error al obtener ID: %w", err)
+	}
+
+	return int(id), nil
+}
+
+// GetHabitSlip obtiene una recaída por su ID
+func (r *SQLiteRepo) GetHabitSlip(id int) (models.HabitSlip, error) {
+	query := `
+		SELECT id, habit_id, timestamp, local_date, COALESCE(notes, ''), created_at
+		FROM habit_slips
+		WHERE id = ?
+	`
+
+	slip, err := r.scanHabitSlip(r.db.QueryRow(query, id))
+	if err != nil {
+		return models.HabitSlip{}, fmt.Errorf("error al obtener recaída: %w", err)
+	}
+
+	return slip, nil
+}
+
+// GetHabitSlips obtiene las recaídas de un hábito cuyo día lógico está en el rango, de la más
+// reciente a la más antigua
+func (r *SQLiteRepo) GetHabitSlips(habitID int, startDate, endDate string) ([]models.HabitSlip, error) {
+	query := `
+		SELECT id, habit_id, timestamp, local_date, COALESCE(notes, ''), created_at
+		FROM habit_slips
+		WHERE habit_id = ? AND local_date >= ? AND local_date <= ?
+		ORDER BY timestamp DESC
+	`
+
+	rows, err := r.db.Query(query, habitID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar recaídas: %w", err)
+	}
+	defer rows.Close()
+
+	var slips []models.HabitSlip
+	for rows.Next() {
+		slip, err := r.scanHabitSlip(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear recaída: %w", err)
+		}
+		slips = append(slips, slip)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar recaídas: %w", err)
+	}
+
+	return slips, nil
+}
+
+// DeleteHabitSlip elimina una recaída
+func (r *SQLiteRepo) DeleteHabitSlip(id int) error {
+	query := "DELETE FROM habit_slips WHERE id = ?"
+
+	_, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("error al eliminar recaída: %w", err)
+	}
+
+	return nil
+}
+
+// scanHabitSlip convierte una fila en una recaída
+func (r *SQLiteRepo) scanHabitSlip(row rowScanner) (models.HabitSlip, error) {
+	var slip models.HabitSlip
+	var timestamp, createdAt string
+
+	if err := row.Scan(
+		&slip.ID,
+		&slip.HabitID,
+		&timestamp,
+		&slip.Date,
+		&slip.Notes,
+		&createdAt,
+	); err != nil {
+		return models.HabitSlip{}, err
+	}
+
+	// Convertir valores (los instantes se guardan en UTC y se muestran en la zona del usuario)
+	slip.Timestamp = r.localInstant(timestamp)
+	slip.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+
+	return slip, nil
+}
@@ -6,25 +6,48 @@ import (
 	"time"
 
 	"github.com/kubaliski/habit-tracker/backend/models"
+	"github.com/kubaliski/habit-tracker/backend/schedule"
 )
 
 // ==================== MÉTODOS PARA HÁBITOS ====================
 
-// CreateHabit crea un nuevo hábito
+// CreateHabit crea un nuevo hábito, con su meta como primera versión del historial
 func (r *SQLiteRepo) CreateHabit(habit models.NewHabitInput) (int, error) {
 	query := `
-		INSERT INTO habits (name, description, category, frequency, goal, created_at, updated_at, active)
-		VALUES (?, ?, ?, ?, ?, ?, ?, 1)
+		INSERT INTO habits (
+			name, description, category, frequency, goal,
+			schedule_type, schedule_weekdays, schedule_interval, schedule_anchor,
+			measure, unit, target, avoid, created_at, updated_at, active
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 1)
 	`
 	now := time.Now()
 
-	result, err := r.db.Exec(
+	var schedule models.HabitSchedule
+	if habit.Schedule != nil {
+		schedule = *habit.Schedule
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("error al iniciar transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
 		query,
 		habit.Name,
 		habit.Description,
 		habit.Category,
 		habit.Frequency,
 		habit.Goal,
+		schedule.Type,
+		schedule.Weekdays,
+		schedule.Interval,
+		schedule.Anchor,
+		habit.Measure,
+		habit.Unit,
+		habit.Target,
+		boolToInt(habit.Avoid),
 		now,
 		now,
 	)
@@ -37,20 +60,38 @@ func (r *SQLiteRepo) CreateHabit(habit models.NewHabitInput) (int, error) {
 		return 0, fmt.Errorf("error al obtener ID: %w", err)
 	}
 
+	err = saveGoalVersion(tx, models.HabitGoalVersion{
+		HabitID:       int(id),
+		EffectiveDate: r.DayOf(now),
+		Frequency:     habit.Frequency,
+		Goal:          habit.Goal,
+		Schedule:      schedule,
+		Target:        habit.Target,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error al confirmar transacción: %w", err)
+	}
+
 	return int(id), nil
 }
 
 // GetHabit obtiene un hábito por su ID
 func (r *SQLiteRepo) GetHabit(id int) (models.Habit, error) {
 	query := `
-		SELECT id, name, description, category, frequency, goal, created_at, updated_at, active
+		SELECT id, name, description, category, frequency, goal,
+		       schedule_type, schedule_weekdays, schedule_interval, schedule_anchor,
+		       measure, unit, target, avoid, created_at, updated_at, active
 		FROM habits
 		WHERE id = ?
 	`
 
 	var habit models.Habit
 	var createdAt, updatedAt string
-	var activeInt int
+	var activeInt, avoidInt int
 
 	err := r.db.QueryRow(query, id).Scan(
 		&habit.ID,
@@ -59,6 +100,14 @@ func (r *SQLiteRepo) GetHabit(id int) (models.Habit, error) {
 		&habit.Category,
 		&habit.Frequency,
 		&habit.Goal,
+		&habit.Schedule.Type,
+		&habit.Schedule.Weekdays,
+		&habit.Schedule.Interval,
+		&habit.Schedule.Anchor,
+		&habit.Measure,
+		&habit.Unit,
+		&habit.Target,
+		&avoidInt,
 		&createdAt,
 		&updatedAt,
 		&activeInt,
@@ -71,6 +120,8 @@ func (r *SQLiteRepo) GetHabit(id int) (models.Habit, error) {
 	habit.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
 	habit.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
 	habit.Active = activeInt == 1
+	habit.Avoid = avoidInt == 1
+	fillScheduleTimes(&habit)
 
 	return habit, nil
 }
@@ -78,7 +129,9 @@ func (r *SQLiteRepo) GetHabit(id int) (models.Habit, error) {
 // GetAllHabits obtiene todos los hábitos
 func (r *SQLiteRepo) GetAllHabits() ([]models.Habit, error) {
 	query := `
-		SELECT id, name, description, category, frequency, goal, created_at, updated_at, active
+		SELECT id, name, description, category, frequency, goal,
+		       schedule_type, schedule_weekdays, schedule_interval, schedule_anchor,
+		       measure, unit, target, avoid, created_at, updated_at, active
 		FROM habits
 		ORDER BY name
 	`
@@ -93,7 +146,7 @@ func (r *SQLiteRepo) GetAllHabits() ([]models.Habit, error) {
 	for rows.Next() {
 		var habit models.Habit
 		var createdAt, updatedAt string
-		var activeInt int
+		var activeInt, avoidInt int
 
 		if err := rows.Scan(
 			&habit.ID,
@@ -102,6 +155,14 @@ func (r *SQLiteRepo) GetAllHabits() ([]models.Habit, error) {
 			&habit.Category,
 			&habit.Frequency,
 			&habit.Goal,
+			&habit.Schedule.Type,
+			&habit.Schedule.Weekdays,
+			&habit.Schedule.Interval,
+			&habit.Schedule.Anchor,
+			&habit.Measure,
+			&habit.Unit,
+			&habit.Target,
+			&avoidInt,
 			&createdAt,
 			&updatedAt,
 			&activeInt,
@@ -113,6 +174,8 @@ func (r *SQLiteRepo) GetAllHabits() ([]models.Habit, error) {
 		habit.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
 		habit.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
 		habit.Active = activeInt == 1
+		habit.Avoid = avoidInt == 1
+		fillScheduleTimes(&habit)
 
 		habits = append(habits, habit)
 	}
@@ -124,8 +187,66 @@ func (r *SQLiteRepo) GetAllHabits() ([]models.Habit, error) {
 	return habits, nil
 }
 
-// UpdateHabit actualiza un hábito existente
+// UpdateHabit actualiza un hábito existente. Los cambios de meta (frecuencia, objetivo,
+// calendario y objetivo diario) se guardan como una versión vigente desde habit.EffectiveDate
+// (hoy si se omite) hasta la siguiente versión, si la hay: los cambios posteriores se conservan.
+// El hábito muestra siempre la versión más reciente.
 func (r *SQLiteRepo) UpdateHabit(id int, habit models.UpdateHabitInput) error {
+	// Combinar los cambios de meta con la meta vigente ese día
+	var goal *models.HabitGoalVersion
+	var evaluated models.Habit
+	var until string // día en que empieza la siguiente versión; vacío si no hay
+	if habit.Frequency != "" || habit.Goal > 0 || habit.Schedule != nil || habit.Target > 0 {
+		effectiveDate := habit.EffectiveDate
+		if effectiveDate == "" {
+			effectiveDate = r.Today()
+		}
+
+		current, err := r.GetHabitAt(id, effectiveDate)
+		if err != nil {
+			return err
+		}
+
+		base := goalVersionOf(current, effectiveDate)
+		version := base
+		if habit.Frequency != "" {
+			version.Frequency = habit.Frequency
+		}
+		if habit.Goal > 0 {
+			version.Goal = habit.Goal
+		}
+		if habit.Schedule != nil {
+			version.Schedule = *habit.Schedule
+		}
+		if habit.Target > 0 {
+			version.Target = habit.Target
+		}
+		if !sameGoal(version, base) {
+			goal = &version
+
+			// Los registros desde la fecha de efecto hasta la siguiente versión se reevalúan con
+			// la nueva meta
+			versions, err := r.GetHabitGoalVersions(id)
+			if err != nil {
+				return err
+			}
+			for _, later := range versions {
+				if later.EffectiveDate > effectiveDate {
+					until = later.EffectiveDate
+					break
+				}
+			}
+
+			evaluated = current.WithGoal(version)
+			if habit.Measure != "" {
+				evaluated.Measure = habit.Measure
+			}
+			if habit.Avoid != nil {
+				evaluated.Avoid = *habit.Avoid
+			}
+		}
+	}
+
 	// Construir la consulta dinámicamente basada en los campos proporcionados
 	updates := []string{}
 	args := []interface{}{}
@@ -145,14 +266,19 @@ func (r *SQLiteRepo) UpdateHabit(id int, habit models.UpdateHabitInput) error {
 		args = append(args, habit.Category)
 	}
 
-	if habit.Frequency != "" {
-		updates = append(updates, "frequency = ?")
-		args = append(args, habit.Frequency)
+	if habit.Measure != "" {
+		updates = append(updates, "measure = ?")
+		args = append(args, habit.Measure)
 	}
 
-	if habit.Goal > 0 {
-		updates = append(updates, "goal = ?")
-		args = append(args, habit.Goal)
+	if habit.Unit != "" {
+		updates = append(updates, "unit = ?")
+		args = append(args, habit.Unit)
+	}
+
+	if habit.Avoid != nil {
+		updates = append(updates, "avoid = ?")
+		args = append(args, boolToInt(*habit.Avoid))
 	}
 
 	if habit.Active != nil {
@@ -169,18 +295,39 @@ func (r *SQLiteRepo) UpdateHabit(id int, habit models.UpdateHabitInput) error {
 	args = append(args, time.Now())
 
 	// Si no hay nada que actualizar, salir
-	if len(updates) <= 1 {
+	if len(updates) <= 1 && goal == nil {
 		return nil
 	}
 
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := fmt.Sprintf("UPDATE habits SET %s WHERE id = ?", strings.Join(updates, ", "))
 	args = append(args, id)
 
-	_, err := r.db.Exec(query, args...)
-	if err != nil {
+	if _, err := tx.Exec(query, args...); err != nil {
 		return fmt.Errorf("error al actualizar hábito: %w", err)
 	}
 
+	if goal != nil {
+		if err := saveGoalVersion(tx, *goal); err != nil {
+			return err
+		}
+		if err := syncHabitGoal(tx, id); err != nil {
+			return err
+		}
+		if err := reevaluateHabitLogs(tx, evaluated, goal.EffectiveDate, until); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error al confirmar transacción: %w", err)
+	}
+
 	return nil
 }
 
@@ -195,3 +342,10 @@ func (r *SQLiteRepo) DeleteHabit(id int) error {
 
 	return nil
 }
+
+// fillScheduleTimes completa la cuota del calendario, que se guarda como objetivo del hábito
+func fillScheduleTimes(habit *models.Habit) {
+	if schedule.IsQuota(habit.Schedule) {
+		habit.Schedule.Times = habit.Goal
+	}
+}
@@ -24,8 +24,8 @@ func (r *SQLiteRepo) LogHabit(habitID int, log models.NewHabitLogInput) error {
 	// Si no existe, insertar
 	if err == sql.ErrNoRows {
 		query := `
-			INSERT INTO habit_logs (habit_id, date, completed, count, notes)
-			VALUES (?, ?, ?, ?, ?)
+			INSERT INTO habit_logs (habit_id, date, completed, count, value, notes)
+			VALUES (?, ?, ?, ?, ?, ?)
 		`
 
 		completedInt := 0
@@ -39,6 +39,7 @@ func (r *SQLiteRepo) LogHabit(habitID int, log models.NewHabitLogInput) error {
 			log.Date,
 			completedInt,
 			log.Count,
+			log.Value,
 			log.Notes,
 		)
 		if err != nil {
@@ -54,7 +55,7 @@ func (r *SQLiteRepo) LogHabit(habitID int, log models.NewHabitLogInput) error {
 // GetHabitLogs obtiene los registros de un hábito en un rango de fechas
 func (r *SQLiteRepo) GetHabitLogs(habitID int, startDate, endDate string) ([]models.HabitLog, error) {
 	query := `
-		SELECT id, habit_id, date, completed, count, notes
+		SELECT id, habit_id, date, completed, count, value, notes
 		FROM habit_logs
 		WHERE habit_id = ? AND date >= ? AND date <= ?
 		ORDER BY date DESC
@@ -78,6 +79,7 @@ func (r *SQLiteRepo) GetHabitLogs(habitID int, startDate, endDate string) ([]mod
 			&dateStr,
 			&completedInt,
 			&log.Count,
+			&log.Value,
 			&log.Notes,
 		); err != nil {
 			return nil, fmt.Errorf("error al escanear registro de hábito: %w", err)
@@ -101,7 +103,7 @@ func (r *SQLiteRepo) GetHabitLogs(habitID int, startDate, endDate string) ([]mod
 func (r *SQLiteRepo) UpdateHabitLog(id int, log models.NewHabitLogInput) error {
 	query := `
 		UPDATE habit_logs
-		SET completed = ?, count = ?, notes = ?
+		SET completed = ?, count = ?, value = ?, notes = ?
 		WHERE id = ?
 	`
 
@@ -114,6 +116,7 @@ func (r *SQLiteRepo) UpdateHabitLog(id int, log models.NewHabitLogInput) error {
 		query,
 		completedInt,
 		log.Count,
+		log.Value,
 		log.Notes,
 		id,
 	)
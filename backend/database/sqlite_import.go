@@ -0,0 +1,866 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+// ==================== IMPORTACIÓN DE DATOS ====================
+
+// importer aplica un documento de exportación dentro de una transacción
+type importer struct {
+	tx       *sql.Tx
+	strategy string
+	report   *models.ImportReport
+	clock    dayClock
+
+	// Correspondencia entre los IDs del documento y los de esta base de datos
+	habitIDs    map[int]int
+	beverageIDs map[int]int
+}
+
+// ImportDocument importa un documento de exportación en una sola transacción.
+// Los IDs del documento se reasignan y los registros duplicados se resuelven con la
+// estrategia indicada. En modo de prueba se calcula el informe y se deshacen los cambios.
+// Los documentos de versiones anteriores del formato se completan antes (ver formatUpgrades).
+func (r *SQLiteRepo) ImportDocument(doc models.ExportDocument, strategy string, dryRun bool) (models.ImportReport, error) {
+	if doc.FormatVersion > models.ExportFormatVersion {
+		return models.ImportReport{}, fmt.Errorf("formato de exportación v%d no soportado (máximo v%d)", doc.FormatVersion, models.ExportFormatVersion)
+	}
+	upgradeDocument(&doc)
+
+	report := models.ImportReport{
+		DryRun:    dryRun,
+		Strategy:  strategy,
+		Tables:    map[string]models.ImportTableReport{},
+		Conflicts: []models.ImportConflict{},
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return models.ImportReport{}, fmt.Errorf("error al iniciar transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	imp := &importer{
+		tx:          tx,
+		strategy:    strategy,
+		report:      &report,
+		clock:       r.dayClock(),
+		habitIDs:    make(map[int]int),
+		beverageIDs: make(map[int]int),
+	}
+
+	steps := []func(models.ExportDocument) error{
+		imp.importHabits,
+		imp.importHabitGoalVersions,
+		imp.importHabitLogs,
+		imp.importHabitSlips,
+		imp.importExcuses,
+		imp.importMoodEntries,
+		imp.importBeverages,
+		imp.importIntakes,
+	}
+
+	for _, step := range steps {
+		if err := step(doc); err != nil {
+			return models.ImportReport{}, err
+		}
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.ImportReport{}, fmt.Errorf("error al confirmar transacción: %w", err)
+	}
+	report.Committed = true
+
+	return report, nil
+}
+
+// count registra el resultado de un registro en el informe
+func (imp *importer) count(table, key, action string) {
+	stats := imp.report.Tables[table]
+
+	switch action {
+	case "inserted":
+		stats.Inserted++
+	case "skipped":
+		stats.Skipped++
+		imp.report.Conflicts = append(imp.report.Conflicts, models.ImportConflict{Table: table, Key: key, Action: action})
+	default:
+		stats.Updated++
+		imp.report.Conflicts = append(imp.report.Conflicts, models.ImportConflict{Table: table, Key: key, Action: action})
+	}
+
+	imp.report.Tables[table] = stats
+}
+
+// resolvedAction devuelve la acción registrada para un conflicto según la estrategia
+func (imp *importer) resolvedAction() string {
+	switch imp.strategy {
+	case models.ImportStrategyOverwrite:
+		return "updated"
+	case models.ImportStrategyMerge:
+		return "merged"
+	default:
+		return "skipped"
+	}
+}
+
+// importHabits importa hábitos, emparejando por nombre con los existentes
+func (imp *importer) importHabits(doc models.ExportDocument) error {
+	// Las exportaciones anteriores al historial de metas (v7) no traen versiones: la meta del
+	// hábito pasa a ser su única versión
+	versioned := make(map[int]bool)
+	for _, version := range doc.HabitGoalVersions {
+		versioned[version.HabitID] = true
+	}
+
+	for _, habit := range doc.Habits {
+		var existingID int
+		var description, category string
+		err := imp.tx.QueryRow(
+			"SELECT id, COALESCE(description, ''), COALESCE(category, '') FROM habits WHERE name = ?",
+			habit.Name,
+		).Scan(&existingID, &description, &category)
+
+		if err == sql.ErrNoRows {
+			createdAt := habit.CreatedAt
+			if createdAt.IsZero() {
+				createdAt = time.Now()
+			}
+
+			result, err := imp.tx.Exec(`
+				INSERT INTO habits (
+					name, description, category, frequency, goal,
+					schedule_type, schedule_weekdays, schedule_interval, schedule_anchor,
+					measure, unit, target, avoid, created_at, updated_at, active
+				) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			`, habit.Name, habit.Description, habit.Category, habit.Frequency, habit.Goal,
+				habit.Schedule.Type, habit.Schedule.Weekdays, habit.Schedule.Interval, habit.Schedule.Anchor,
+				habit.Measure, habit.Unit, habit.Target,
+				boolToInt(habit.Avoid), createdAt, time.Now(), boolToInt(habit.Active))
+			if err != nil {
+				return fmt.Errorf("error al importar hábito %s: %w", habit.Name, err)
+			}
+
+			id, err := result.LastInsertId()
+			if err != nil {
+				return fmt.Errorf("error al obtener ID: %w", err)
+			}
+
+			imp.habitIDs[habit.ID] = int(id)
+			if !versioned[habit.ID] {
+				version := goalVersionOf(habit, imp.clock.day(createdAt))
+				version.HabitID = int(id)
+				if err := saveGoalVersion(imp.tx, version); err != nil {
+					return fmt.Errorf("error al importar hábito %s: %w", habit.Name, err)
+				}
+			}
+			imp.count("habits", habit.Name, "inserted")
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error al buscar hábito %s: %w", habit.Name, err)
+		}
+
+		imp.habitIDs[habit.ID] = existingID
+
+		switch imp.strategy {
+		case models.ImportStrategyOverwrite:
+			_, err = imp.tx.Exec(`
+				UPDATE habits SET description = ?, category = ?, frequency = ?, goal = ?,
+					schedule_type = ?, schedule_weekdays = ?, schedule_interval = ?, schedule_anchor = ?,
+					measure = ?, unit = ?, target = ?, avoid = ?, active = ?, updated_at = ?
+				WHERE id = ?
+			`, habit.Description, habit.Category, habit.Frequency, habit.Goal,
+				habit.Schedule.Type, habit.Schedule.Weekdays, habit.Schedule.Interval, habit.Schedule.Anchor,
+				habit.Measure, habit.Unit, habit.Target,
+				boolToInt(habit.Avoid), boolToInt(habit.Active), time.Now(), existingID)
+			if err == nil && !versioned[habit.ID] {
+				// La meta sobrescrita rige desde hoy; los días anteriores conservan la suya
+				version := goalVersionOf(habit, imp.clock.day(time.Now()))
+				version.HabitID = existingID
+				err = imp.saveChangedGoal(version)
+			}
+		case models.ImportStrategyMerge:
+			_, err = imp.tx.Exec(
+				"UPDATE habits SET description = ?, category = ?, updated_at = ? WHERE id = ?",
+				firstNonEmpty(description, habit.Description), firstNonEmpty(category, habit.Category), time.Now(), existingID,
+			)
+		}
+		if err != nil {
+			return fmt.Errorf("error al actualizar hábito %s: %w", habit.Name, err)
+		}
+
+		imp.count("habits", habit.Name, imp.resolvedAction())
+	}
+
+	return nil
+}
+
+// saveChangedGoal guarda una versión de la meta solo si difiere de la última del hábito
+func (imp *importer) saveChangedGoal(version models.HabitGoalVersion) error {
+	var latest models.HabitGoalVersion
+	err := imp.tx.QueryRow(`
+		SELECT frequency, goal, schedule_type, schedule_weekdays, schedule_interval, schedule_anchor, target
+		FROM habit_goal_versions
+		WHERE habit_id = ?
+		ORDER BY effective_date DESC
+		LIMIT 1
+	`, version.HabitID).Scan(
+		&latest.Frequency, &latest.Goal, &latest.Schedule.Type, &latest.Schedule.Weekdays,
+		&latest.Schedule.Interval, &latest.Schedule.Anchor, &latest.Target,
+	)
+	if err == nil && sameGoal(latest, version) {
+		return nil
+	}
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("error al buscar la meta actual: %w", err)
+	}
+
+	return saveGoalVersion(imp.tx, version)
+}
+
+// importHabitGoalVersions importa el historial de metas; la clave única es (habit_id,
+// effective_date). Una versión existente solo se sustituye al sobrescribir: dos metas no se
+// pueden fusionar. Al terminar, cada hábito afectado toma como meta actual su última versión.
+func (imp *importer) importHabitGoalVersions(doc models.ExportDocument) error {
+	touched := make(map[int]bool)
+
+	for _, version := range doc.HabitGoalVersions {
+		habitID, ok := imp.habitIDs[version.HabitID]
+		if !ok {
+			return fmt.Errorf("la versión de meta %d hace referencia a un hábito %d que no está en la exportación", version.ID, version.HabitID)
+		}
+
+		key := fmt.Sprintf("%d/%s", habitID, version.EffectiveDate)
+		sched := version.Schedule
+
+		var existingID int
+		err := imp.tx.QueryRow(
+			"SELECT id FROM habit_goal_versions WHERE habit_id = ? AND effective_date = ?",
+			habitID, version.EffectiveDate,
+		).Scan(&existingID)
+
+		if err == sql.ErrNoRows {
+			createdAt := version.CreatedAt
+			if createdAt.IsZero() {
+				createdAt = time.Now()
+			}
+
+			_, err := imp.tx.Exec(`
+				INSERT INTO habit_goal_versions (
+					habit_id, effective_date, frequency, goal,
+					schedule_type, schedule_weekdays, schedule_interval, schedule_anchor, target, created_at
+				) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			`, habitID, version.EffectiveDate, version.Frequency, version.Goal,
+				sched.Type, sched.Weekdays, sched.Interval, sched.Anchor, version.Target, createdAt)
+			if err != nil {
+				return fmt.Errorf("error al importar versión de meta %s: %w", key, err)
+			}
+			touched[habitID] = true
+			imp.count("habit_goal_versions", key, "inserted")
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error al buscar versión de meta %s: %w", key, err)
+		}
+
+		if imp.strategy != models.ImportStrategyOverwrite {
+			imp.count("habit_goal_versions", key, "skipped")
+			continue
+		}
+
+		_, err = imp.tx.Exec(`
+			UPDATE habit_goal_versions SET frequency = ?, goal = ?,
+				schedule_type = ?, schedule_weekdays = ?, schedule_interval = ?, schedule_anchor = ?, target = ?
+			WHERE id = ?
+		`, version.Frequency, version.Goal,
+			sched.Type, sched.Weekdays, sched.Interval, sched.Anchor, version.Target, existingID)
+		if err != nil {
+			return fmt.Errorf("error al actualizar versión de meta %s: %w", key, err)
+		}
+		touched[habitID] = true
+		imp.count("habit_goal_versions", key, "updated")
+	}
+
+	for habitID := range touched {
+		if err := syncHabitGoal(imp.tx, habitID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// importHabitLogs importa registros de hábitos; la clave única es (habit_id, date)
+func (imp *importer) importHabitLogs(doc models.ExportDocument) error {
+	for _, log := range doc.HabitLogs {
+		habitID, ok := imp.habitIDs[log.HabitID]
+		if !ok {
+			return fmt.Errorf("el registro %d hace referencia a un hábito %d que no está en la exportación", log.ID, log.HabitID)
+		}
+
+		date := log.Date.Format("2006-01-02")
+		key := fmt.Sprintf("%d/%s", habitID, date)
+
+		var existingID, completed, count int
+		var existingValue float64
+		var notes string
+		err := imp.tx.QueryRow(
+			"SELECT id, completed, count, value, COALESCE(notes, '') FROM habit_logs WHERE habit_id = ? AND date = ?",
+			habitID, date,
+		).Scan(&existingID, &completed, &count, &existingValue, &notes)
+
+		if err == sql.ErrNoRows {
+			_, err := imp.tx.Exec(
+				"INSERT INTO habit_logs (habit_id, date, completed, count, value, notes) VALUES (?, ?, ?, ?, ?, ?)",
+				habitID, date, boolToInt(log.Completed), log.Count, log.Value, log.Notes,
+			)
+			if err != nil {
+				return fmt.Errorf("error al importar registro de hábito %s: %w", key, err)
+			}
+			imp.count("habit_logs", key, "inserted")
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error al buscar registro de hábito %s: %w", key, err)
+		}
+
+		switch imp.strategy {
+		case models.ImportStrategyOverwrite:
+			_, err = imp.tx.Exec(
+				"UPDATE habit_logs SET completed = ?, count = ?, value = ?, notes = ? WHERE id = ?",
+				boolToInt(log.Completed), log.Count, log.Value, log.Notes, existingID,
+			)
+		case models.ImportStrategyMerge:
+			// Completado si lo está en cualquiera de los dos, con el mayor recuento y el mayor valor
+			mergedCount := count
+			if log.Count > mergedCount {
+				mergedCount = log.Count
+			}
+			_, err = imp.tx.Exec(
+				"UPDATE habit_logs SET completed = ?, count = ?, value = ?, notes = ? WHERE id = ?",
+				boolToInt(completed == 1 || log.Completed), mergedCount, math.Max(existingValue, log.Value),
+				mergeNotes(notes, log.Notes), existingID,
+			)
+		}
+		if err != nil {
+			return fmt.Errorf("error al actualizar registro de hábito %s: %w", key, err)
+		}
+
+		imp.count("habit_logs", key, imp.resolvedAction())
+	}
+
+	return nil
+}
+
+// importHabitSlips importa recaídas de hábitos a evitar; la clave única es (habit_id, instante)
+func (imp *importer) importHabitSlips(doc models.ExportDocument) error {
+	for _, slip := range doc.HabitSlips {
+		habitID, ok := imp.habitIDs[slip.HabitID]
+		if !ok {
+			return fmt.Errorf("la recaída %d hace referencia a un hábito %d que no está en la exportación", slip.ID, slip.HabitID)
+		}
+
+		key := fmt.Sprintf("%d/%s", habitID, slip.Timestamp.Format(time.RFC3339))
+
+		existingID, notes, err := imp.findSlip(habitID, slip.Timestamp)
+		if err == sql.ErrNoRows {
+			createdAt := slip.CreatedAt
+			if createdAt.IsZero() {
+				createdAt = time.Now()
+			}
+
+			_, err := imp.tx.Exec(
+				"INSERT INTO habit_slips (habit_id, timestamp, local_date, notes, created_at) VALUES (?, ?, ?, ?, ?)",
+				habitID, slip.Timestamp.UTC(), imp.clock.day(slip.Timestamp), slip.Notes, createdAt,
+			)
+			if err != nil {
+				return fmt.Errorf("error al importar recaída %s: %w", key, err)
+			}
+			imp.count("habit_slips", key, "inserted")
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error al buscar recaída %s: %w", key, err)
+		}
+
+		switch imp.strategy {
+		case models.ImportStrategyOverwrite:
+			_, err = imp.tx.Exec("UPDATE habit_slips SET notes = ? WHERE id = ?", slip.Notes, existingID)
+		case models.ImportStrategyMerge:
+			_, err = imp.tx.Exec("UPDATE habit_slips SET notes = ? WHERE id = ?", mergeNotes(notes, slip.Notes), existingID)
+		}
+		if err != nil {
+			return fmt.Errorf("error al actualizar recaída %s: %w", key, err)
+		}
+
+		imp.count("habit_slips", key, imp.resolvedAction())
+	}
+
+	return nil
+}
+
+// importExcuses importa días justificados; la clave única es (hábito o todos, inicio, fin)
+func (imp *importer) importExcuses(doc models.ExportDocument) error {
+	for _, excuse := range doc.Excuses {
+		var habitID *int
+		target := "all"
+		if excuse.HabitID != nil {
+			id, ok := imp.habitIDs[*excuse.HabitID]
+			if !ok {
+				return fmt.Errorf("el día justificado %d hace referencia a un hábito %d que no está en la exportación", excuse.ID, *excuse.HabitID)
+			}
+			habitID = &id
+			target = strconv.Itoa(id)
+		}
+
+		key := fmt.Sprintf("%s/%s..%s", target, excuse.StartDate, excuse.EndDate)
+		reason := firstNonEmpty(excuse.Reason, models.ExcuseOther)
+
+		var existingID int
+		var notes string
+		err := imp.tx.QueryRow(
+			"SELECT id, COALESCE(notes, '') FROM excuses WHERE habit_id IS ? AND start_date = ? AND end_date = ?",
+			habitID, excuse.StartDate, excuse.EndDate,
+		).Scan(&existingID, &notes)
+
+		if err == sql.ErrNoRows {
+			createdAt := excuse.CreatedAt
+			if createdAt.IsZero() {
+				createdAt = time.Now()
+			}
+
+			_, err := imp.tx.Exec(
+				"INSERT INTO excuses (habit_id, start_date, end_date, reason, notes, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+				habitID, excuse.StartDate, excuse.EndDate, reason, excuse.Notes, createdAt,
+			)
+			if err != nil {
+				return fmt.Errorf("error al importar día justificado %s: %w", key, err)
+			}
+			imp.count("excuses", key, "inserted")
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error al buscar día justificado %s: %w", key, err)
+		}
+
+		switch imp.strategy {
+		case models.ImportStrategyOverwrite:
+			_, err = imp.tx.Exec("UPDATE excuses SET reason = ?, notes = ? WHERE id = ?", reason, excuse.Notes, existingID)
+		case models.ImportStrategyMerge:
+			_, err = imp.tx.Exec("UPDATE excuses SET notes = ? WHERE id = ?", mergeNotes(notes, excuse.Notes), existingID)
+		}
+		if err != nil {
+			return fmt.Errorf("error al actualizar día justificado %s: %w", key, err)
+		}
+
+		imp.count("excuses", key, imp.resolvedAction())
+	}
+
+	return nil
+}
+
+// findSlip busca una recaída del hábito en el mismo instante (ver findIntake)
+func (imp *importer) findSlip(habitID int, timestamp time.Time) (int, string, error) {
+	rows, err := imp.tx.Query("SELECT id, timestamp, COALESCE(notes, '') FROM habit_slips WHERE habit_id = ?", habitID)
+	if err != nil {
+		return 0, "", err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var stored, notes string
+		if err := rows.Scan(&id, &stored, &notes); err != nil {
+			return 0, "", err
+		}
+
+		storedTime, _ := time.Parse(time.RFC3339, stored)
+		if storedTime.Truncate(time.Second).Equal(timestamp.Truncate(time.Second)) {
+			return id, notes, nil
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, "", err
+	}
+
+	return 0, "", sql.ErrNoRows
+}
+
+// importMoodEntries importa registros de estado de ánimo. Un registro con hora es duplicado
+// de otro en el mismo instante y su día se recalcula con la zona y la hora de cambio de día
+// actuales; uno sin hora lo es de otro sin hora de la misma fecha y franja.
+func (imp *importer) importMoodEntries(doc models.ExportDocument) error {
+	for _, entry := range doc.MoodEntries {
+		date := entry.Date.Format("2006-01-02")
+		var timestamp interface{}
+		if entry.Timestamp != nil {
+			date = imp.clock.day(*entry.Timestamp)
+			timestamp = entry.Timestamp.UTC()
+		}
+		key := moodEntryKey(date, entry)
+
+		existing, err := imp.findMoodEntry(date, entry.Slot, entry.Timestamp)
+		if err == sql.ErrNoRows {
+			createdAt := entry.CreatedAt
+			if createdAt.IsZero() {
+				createdAt = time.Now()
+			}
+
+			result, err := imp.tx.Exec(`
+				INSERT INTO mood_entries (
+					date, timestamp, slot, mood_score, energy_level, anxiety_level, stress_level, sleep_hours, notes, created_at
+				) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			`, date, timestamp, entry.Slot, entry.MoodScore, entry.EnergyLevel, entry.AnxietyLevel, entry.StressLevel, entry.SleepHours, entry.Notes, createdAt)
+			if err != nil {
+				return fmt.Errorf("error al importar estado de ánimo %s: %w", key, err)
+			}
+
+			id, err := result.LastInsertId()
+			if err != nil {
+				return fmt.Errorf("error al obtener ID: %w", err)
+			}
+
+			if err := imp.insertTags(int(id), entry.Tags); err != nil {
+				return err
+			}
+
+			imp.count("mood_entries", key, "inserted")
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error al buscar estado de ánimo %s: %w", key, err)
+		}
+
+		switch imp.strategy {
+		case models.ImportStrategyOverwrite:
+			_, err = imp.tx.Exec(`
+				UPDATE mood_entries
+				SET mood_score = ?, energy_level = ?, anxiety_level = ?, stress_level = ?, sleep_hours = ?, notes = ?
+				WHERE id = ?
+			`, entry.MoodScore, entry.EnergyLevel, entry.AnxietyLevel, entry.StressLevel, entry.SleepHours, entry.Notes, existing.ID)
+			if err != nil {
+				return fmt.Errorf("error al actualizar estado de ánimo %s: %w", key, err)
+			}
+
+			if _, err := imp.tx.Exec("DELETE FROM mood_tags WHERE mood_id = ?", existing.ID); err != nil {
+				return fmt.Errorf("error al eliminar etiquetas existentes: %w", err)
+			}
+			if err := imp.insertTags(existing.ID, entry.Tags); err != nil {
+				return err
+			}
+
+		case models.ImportStrategyMerge:
+			// Conservar los valores existentes y completar los que falten
+			_, err = imp.tx.Exec(`
+				UPDATE mood_entries
+				SET energy_level = ?, anxiety_level = ?, stress_level = ?, sleep_hours = ?, notes = ?
+				WHERE id = ?
+			`,
+				firstNonZeroInt(existing.EnergyLevel, entry.EnergyLevel),
+				firstNonZeroInt(existing.AnxietyLevel, entry.AnxietyLevel),
+				firstNonZeroInt(existing.StressLevel, entry.StressLevel),
+				firstNonZeroFloat(existing.SleepHours, entry.SleepHours),
+				mergeNotes(existing.Notes, entry.Notes),
+				existing.ID,
+			)
+			if err != nil {
+				return fmt.Errorf("error al combinar estado de ánimo %s: %w", key, err)
+			}
+
+			if err := imp.insertTags(existing.ID, entry.Tags); err != nil {
+				return err
+			}
+		}
+
+		imp.count("mood_entries", key, imp.resolvedAction())
+	}
+
+	return nil
+}
+
+// findMoodEntry busca el registro de ánimo que coincide con uno importado: con hora, el del
+// mismo instante (ver findIntake); sin hora, el de la misma fecha y franja
+func (imp *importer) findMoodEntry(date, slot string, timestamp *time.Time) (models.MoodEntry, error) {
+	query := `
+		SELECT id, timestamp, COALESCE(energy_level, 0), COALESCE(anxiety_level, 0), COALESCE(stress_level, 0),
+			COALESCE(sleep_hours, 0), COALESCE(notes, '')
+		FROM mood_entries
+	`
+	args := []interface{}{}
+	if timestamp != nil {
+		query += "WHERE timestamp IS NOT NULL"
+	} else {
+		query += "WHERE timestamp IS NULL AND date = ? AND slot = ?"
+		args = append(args, date, slot)
+	}
+
+	rows, err := imp.tx.Query(query, args...)
+	if err != nil {
+		return models.MoodEntry{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry models.MoodEntry
+		var stored sql.NullString
+		err := rows.Scan(&entry.ID, &stored, &entry.EnergyLevel, &entry.AnxietyLevel, &entry.StressLevel, &entry.SleepHours, &entry.Notes)
+		if err != nil {
+			return models.MoodEntry{}, err
+		}
+
+		if timestamp == nil {
+			return entry, nil
+		}
+
+		storedTime, _ := time.Parse(time.RFC3339, stored.String)
+		if storedTime.Truncate(time.Second).Equal(timestamp.Truncate(time.Second)) {
+			return entry, nil
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return models.MoodEntry{}, err
+	}
+
+	return models.MoodEntry{}, sql.ErrNoRows
+}
+
+// moodEntryKey identifica un registro de estado de ánimo en el informe de importación
+func moodEntryKey(date string, entry models.MoodEntry) string {
+	if entry.Timestamp != nil {
+		return date + " " + entry.Timestamp.UTC().Format(time.RFC3339)
+	}
+	if entry.Slot != "" {
+		return date + " " + entry.Slot
+	}
+	return date
+}
+
+// insertTags añade etiquetas a un registro de estado de ánimo ignorando las repetidas
+func (imp *importer) insertTags(moodID int, tags []string) error {
+	for _, tag := range tags {
+		_, err := imp.tx.Exec("INSERT OR IGNORE INTO mood_tags (mood_id, tag) VALUES (?, ?)", moodID, tag)
+		if err != nil {
+			return fmt.Errorf("error al insertar etiqueta: %w", err)
+		}
+	}
+	return nil
+}
+
+// importBeverages importa bebidas con cafeína, emparejando por nombre con las existentes
+func (imp *importer) importBeverages(doc models.ExportDocument) error {
+	for _, beverage := range doc.CaffeineBeverages {
+		var existingID int
+		var category, imagePath string
+		err := imp.tx.QueryRow(
+			"SELECT id, COALESCE(category, ''), COALESCE(image_path, '') FROM caffeine_beverages WHERE name = ?",
+			beverage.Name,
+		).Scan(&existingID, &category, &imagePath)
+
+		if err == sql.ErrNoRows {
+			result, err := imp.tx.Exec(`
+				INSERT INTO caffeine_beverages (
+					name, caffeine_content, standard_unit, standard_unit_value, category, image_path, active
+				) VALUES (?, ?, ?, ?, ?, ?, ?)
+			`, beverage.Name, beverage.CaffeineContent, beverage.StandardUnit, beverage.StandardUnitValue,
+				beverage.Category, beverage.ImagePath, boolToInt(beverage.Active))
+			if err != nil {
+				return fmt.Errorf("error al importar bebida %s: %w", beverage.Name, err)
+			}
+
+			id, err := result.LastInsertId()
+			if err != nil {
+				return fmt.Errorf("error al obtener ID: %w", err)
+			}
+
+			imp.beverageIDs[beverage.ID] = int(id)
+			imp.count("caffeine_beverages", beverage.Name, "inserted")
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error al buscar bebida %s: %w", beverage.Name, err)
+		}
+
+		imp.beverageIDs[beverage.ID] = existingID
+
+		switch imp.strategy {
+		case models.ImportStrategyOverwrite:
+			_, err = imp.tx.Exec(`
+				UPDATE caffeine_beverages
+				SET caffeine_content = ?, standard_unit = ?, standard_unit_value = ?, category = ?, image_path = ?, active = ?
+				WHERE id = ?
+			`, beverage.CaffeineContent, beverage.StandardUnit, beverage.StandardUnitValue,
+				beverage.Category, beverage.ImagePath, boolToInt(beverage.Active), existingID)
+		case models.ImportStrategyMerge:
+			_, err = imp.tx.Exec(
+				"UPDATE caffeine_beverages SET category = ?, image_path = ? WHERE id = ?",
+				firstNonEmpty(category, beverage.Category), firstNonEmpty(imagePath, beverage.ImagePath), existingID,
+			)
+		}
+		if err != nil {
+			return fmt.Errorf("error al actualizar bebida %s: %w", beverage.Name, err)
+		}
+
+		imp.count("caffeine_beverages", beverage.Name, imp.resolvedAction())
+	}
+
+	return nil
+}
+
+// importIntakes importa consumos de cafeína; un consumo de la misma bebida en el
+// mismo instante se considera duplicado
+func (imp *importer) importIntakes(doc models.ExportDocument) error {
+	for _, intake := range doc.CaffeineIntake {
+		beverageID, ok := imp.beverageIDs[intake.BeverageID]
+		if !ok {
+			return fmt.Errorf("el consumo %d hace referencia a una bebida %d que no está en la exportación", intake.ID, intake.BeverageID)
+		}
+
+		key := fmt.Sprintf("%s/%s", intake.Timestamp.Format(time.RFC3339), intake.BeverageName)
+
+		existingID, effects, activity, notes, err := imp.findIntake(beverageID, intake.Timestamp)
+		if err == sql.ErrNoRows {
+			createdAt := intake.CreatedAt
+			if createdAt.IsZero() {
+				createdAt = time.Now()
+			}
+
+			_, err := imp.tx.Exec(`
+				INSERT INTO caffeine_intake (
+					timestamp, local_date, beverage_id, beverage_name, amount, unit, total_caffeine,
+					perceived_effects, related_activity, notes, created_at
+				) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			`, intake.Timestamp.UTC(), imp.clock.day(intake.Timestamp), beverageID, intake.BeverageName, intake.Amount, intake.Unit, intake.TotalCaffeine,
+				intake.PerceivedEffects, intake.RelatedActivity, intake.Notes, createdAt)
+			if err != nil {
+				return fmt.Errorf("error al importar consumo %s: %w", key, err)
+			}
+			imp.count("caffeine_intake", key, "inserted")
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error al buscar consumo %s: %w", key, err)
+		}
+
+		switch imp.strategy {
+		case models.ImportStrategyOverwrite:
+			_, err = imp.tx.Exec(`
+				UPDATE caffeine_intake
+				SET amount = ?, unit = ?, total_caffeine = ?, perceived_effects = ?, related_activity = ?, notes = ?
+				WHERE id = ?
+			`, intake.Amount, intake.Unit, intake.TotalCaffeine, intake.PerceivedEffects, intake.RelatedActivity, intake.Notes, existingID)
+		case models.ImportStrategyMerge:
+			_, err = imp.tx.Exec(
+				"UPDATE caffeine_intake SET perceived_effects = ?, related_activity = ?, notes = ? WHERE id = ?",
+				firstNonEmpty(effects, intake.PerceivedEffects), firstNonEmpty(activity, intake.RelatedActivity),
+				mergeNotes(notes, intake.Notes), existingID,
+			)
+		}
+		if err != nil {
+			return fmt.Errorf("error al actualizar consumo %s: %w", key, err)
+		}
+
+		imp.count("caffeine_intake", key, imp.resolvedAction())
+	}
+
+	return nil
+}
+
+// findIntake busca un consumo de la bebida en el mismo instante. La comparación se hace
+// en Go porque el mismo instante puede estar guardado con distinta zona horaria.
+func (imp *importer) findIntake(beverageID int, timestamp time.Time) (int, string, string, string, error) {
+	rows, err := imp.tx.Query(`
+		SELECT id, timestamp, COALESCE(perceived_effects, ''), COALESCE(related_activity, ''), COALESCE(notes, '')
+		FROM caffeine_intake
+		WHERE beverage_id = ?
+	`, beverageID)
+	if err != nil {
+		return 0, "", "", "", err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var stored, effects, activity, notes string
+		if err := rows.Scan(&id, &stored, &effects, &activity, &notes); err != nil {
+			return 0, "", "", "", err
+		}
+
+		storedTime, _ := time.Parse(time.RFC3339, stored)
+		if storedTime.Truncate(time.Second).Equal(timestamp.Truncate(time.Second)) {
+			return id, effects, activity, notes, nil
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, "", "", "", err
+	}
+
+	return 0, "", "", "", sql.ErrNoRows
+}
+
+// boolToInt convierte un booleano al entero usado por SQLite
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// firstNonEmpty devuelve el primer texto no vacío
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// firstNonZeroInt devuelve el primer entero distinto de cero
+func firstNonZeroInt(values ...int) int {
+	for _, v := range values {
+		if v != 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+// firstNonZeroFloat devuelve el primer número distinto de cero
+func firstNonZeroFloat(values ...float64) float64 {
+	for _, v := range values {
+		if v != 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+// mergeNotes combina dos notas sin repetirlas
+func mergeNotes(existing, imported string) string {
+	existing = strings.TrimSpace(existing)
+	imported = strings.TrimSpace(imported)
+
+	if imported == "" || strings.Contains(existing, imported) {
+		return existing
+	}
+	if existing == "" {
+		return imported
+	}
+	return existing + "\n" + imported
+}
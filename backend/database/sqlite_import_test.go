@@ -0,0 +1,352 @@
+package database
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+	"github.com/kubaliski/habit-tracker/backend/schedule"
+)
+
+// setTestClock fija la zona horaria y la hora de cambio de día del repositorio
+func setTestClock(t *testing.T, repo *SQLiteRepo, timezone string, rolloverHour int) {
+	t.Helper()
+	err := repo.UpdateTimeSettings(models.UpdateTimeSettingsInput{Timezone: &timezone, RolloverHour: &rolloverHour})
+	if err != nil {
+		t.Fatalf("UpdateTimeSettings: %v", err)
+	}
+}
+
+func TestImportMoodEntriesMatchesInstants(t *testing.T) {
+	repo := newTestRepo(t)
+	setTestClock(t, repo, "Europe/Madrid", 4)
+
+	// 03:30 en Madrid, antes del cambio de día: pertenece al 17 aunque el documento diga 18
+	at := time.Date(2026, time.October, 18, 1, 30, 0, 0, time.UTC)
+	doc := models.ExportDocument{
+		FormatVersion: models.ExportFormatVersion,
+		MoodEntries: []models.MoodEntry{
+			{Date: testDay(t, "2026-10-18"), Timestamp: &at, MoodScore: 6},
+			{Date: testDay(t, "2026-10-18"), Slot: models.MoodSlotMorning, MoodScore: 7},
+		},
+	}
+
+	report, err := repo.ImportDocument(doc, models.ImportStrategySkip, false)
+	if err != nil {
+		t.Fatalf("ImportDocument: %v", err)
+	}
+	if got := report.Tables["mood_entries"]; got.Inserted != 2 {
+		t.Fatalf("primera importación = %+v, se esperaban 2 insertados", got)
+	}
+
+	entries, err := repo.GetAllMoodEntries("2026-10-17", "2026-10-17")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Timestamp == nil {
+		t.Fatalf("registros del 17 = %+v, se esperaba el registro con hora", entries)
+	}
+
+	// El mismo instante expresado en otra zona horaria es un duplicado
+	sameInstant := at.In(time.FixedZone("CEST", 2*60*60))
+	doc.MoodEntries[0].Timestamp = &sameInstant
+
+	report, err = repo.ImportDocument(doc, models.ImportStrategySkip, false)
+	if err != nil {
+		t.Fatalf("ImportDocument: %v", err)
+	}
+	if got := report.Tables["mood_entries"]; got.Inserted != 0 || got.Skipped != 2 {
+		t.Errorf("segunda importación = %+v, se esperaban 2 omitidos", got)
+	}
+}
+
+func TestFormatUpgradesReachCurrentVersion(t *testing.T) {
+	last := formatUpgrades[len(formatUpgrades)-1].version
+	if last != models.ExportFormatVersion {
+		t.Errorf("el último cambio del formato es la v%d, pero ExportFormatVersion es %d", last, models.ExportFormatVersion)
+	}
+	for i := 1; i < len(formatUpgrades); i++ {
+		if formatUpgrades[i].version != formatUpgrades[i-1].version+1 {
+			t.Errorf("formatUpgrades salta de la v%d a la v%d", formatUpgrades[i-1].version, formatUpgrades[i].version)
+		}
+	}
+}
+
+func TestImportOlderFormatVersions(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   models.ExportDocument
+		check func(t *testing.T, repo *SQLiteRepo)
+	}{
+		{
+			name: "v1: un registro de ánimo por día, del día completo",
+			doc: models.ExportDocument{
+				FormatVersion: 1,
+				MoodEntries:   []models.MoodEntry{{Date: testDay(t, "2026-09-01"), MoodScore: 7}},
+			},
+			check: func(t *testing.T, repo *SQLiteRepo) {
+				entries, err := repo.GetAllMoodEntries("2026-09-01", "2026-09-01")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(entries) != 1 || entries[0].Timestamp != nil || entries[0].Slot != "" {
+					t.Errorf("registros = %+v, se esperaba uno del día completo", entries)
+				}
+			},
+		},
+		{
+			name: "v2: hábitos sí/no con el número de veces como valor",
+			doc: models.ExportDocument{
+				FormatVersion: 2,
+				Habits:        []models.Habit{{ID: 7, Name: "Leer", Frequency: models.FrequencyDaily, Goal: 1, Active: true}},
+				HabitLogs:     []models.HabitLog{{HabitID: 7, Date: testDay(t, "2026-09-01"), Completed: true, Count: 2}},
+			},
+			check: func(t *testing.T, repo *SQLiteRepo) {
+				habit := importedHabit(t, repo, "Leer")
+				if habit.Measure != models.MeasureBoolean {
+					t.Errorf("medición = %q, se esperaba %q", habit.Measure, models.MeasureBoolean)
+				}
+				logs, err := repo.GetHabitLogs(habit.ID, "2026-09-01", "2026-09-01")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(logs) != 1 || logs[0].Value != 2 {
+					t.Errorf("registros = %+v, se esperaba el valor 2", logs)
+				}
+			},
+		},
+		{
+			name: "v3: hábitos a cumplir, sin recaídas",
+			doc: models.ExportDocument{
+				FormatVersion: 3,
+				Habits: []models.Habit{{
+					ID: 7, Name: "Leer", Frequency: models.FrequencyDaily, Goal: 1, Measure: models.MeasureBoolean, Active: true,
+				}},
+			},
+			check: func(t *testing.T, repo *SQLiteRepo) {
+				habit := importedHabit(t, repo, "Leer")
+				if habit.Avoid {
+					t.Error("un hábito de la v3 no puede ser a evitar")
+				}
+				slips, err := repo.GetHabitSlips(habit.ID, "0001-01-01", "9999-12-31")
+				if err != nil || len(slips) != 0 {
+					t.Errorf("recaídas = %+v (%v), no se esperaba ninguna", slips, err)
+				}
+			},
+		},
+		{
+			name: "v4: calendario derivado de la frecuencia",
+			doc: models.ExportDocument{
+				FormatVersion: 4,
+				Habits: []models.Habit{{
+					ID: 7, Name: "Correr", Frequency: models.FrequencyWeekly, Goal: 3, Measure: models.MeasureBoolean, Active: true,
+				}},
+			},
+			check: func(t *testing.T, repo *SQLiteRepo) {
+				habit := importedHabit(t, repo, "Correr")
+				if want := schedule.FromFrequency(models.FrequencyWeekly, 3); habit.Schedule != want {
+					t.Errorf("calendario = %+v, se esperaba %+v", habit.Schedule, want)
+				}
+				versions, err := repo.GetHabitGoalVersions(habit.ID)
+				if err != nil || len(versions) != 1 || versions[0].Schedule != habit.Schedule {
+					t.Errorf("versiones = %+v (%v), se esperaba una con el calendario del hábito", versions, err)
+				}
+			},
+		},
+		{
+			name: "v5: sin días justificados",
+			doc: models.ExportDocument{
+				FormatVersion: 5,
+				Habits: []models.Habit{{
+					ID: 7, Name: "Leer", Frequency: models.FrequencyDaily, Goal: 1, Measure: models.MeasureBoolean,
+					Schedule: schedule.FromFrequency(models.FrequencyDaily, 1), Active: true,
+				}},
+			},
+			check: func(t *testing.T, repo *SQLiteRepo) {
+				habit := importedHabit(t, repo, "Leer")
+				excuses, err := repo.GetExcuses(habit.ID, "0001-01-01", "9999-12-31")
+				if err != nil || len(excuses) != 0 {
+					t.Errorf("días justificados = %+v (%v), no se esperaba ninguno", excuses, err)
+				}
+			},
+		},
+		{
+			name: "v6: la meta del hábito es su única versión desde su creación",
+			doc: models.ExportDocument{
+				FormatVersion: 6,
+				Habits: []models.Habit{{
+					ID: 7, Name: "Leer", Frequency: models.FrequencyDaily, Goal: 2, Measure: models.MeasureCount,
+					Schedule: schedule.FromFrequency(models.FrequencyDaily, 2), Active: true,
+					CreatedAt: time.Date(2026, time.September, 1, 12, 0, 0, 0, time.UTC),
+				}},
+			},
+			check: func(t *testing.T, repo *SQLiteRepo) {
+				habit := importedHabit(t, repo, "Leer")
+				versions, err := repo.GetHabitGoalVersions(habit.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(versions) != 1 || versions[0].EffectiveDate != "2026-09-01" || versions[0].Goal != 2 {
+					t.Errorf("versiones = %+v, se esperaba la meta 2 desde el 2026-09-01", versions)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTestRepo(t)
+			if _, err := repo.ImportDocument(tt.doc, models.ImportStrategySkip, false); err != nil {
+				t.Fatalf("ImportDocument: %v", err)
+			}
+			tt.check(t, repo)
+		})
+	}
+}
+
+func TestImportStrategies(t *testing.T) {
+	doc := models.ExportDocument{
+		FormatVersion: models.ExportFormatVersion,
+		Habits: []models.Habit{{
+			ID: 40, Name: "Leer", Description: "Novela", Frequency: models.FrequencyDaily, Goal: 3,
+			Schedule: schedule.FromFrequency(models.FrequencyDaily, 3), Measure: models.MeasureCount, Active: true,
+		}},
+		HabitLogs: []models.HabitLog{{HabitID: 40, Date: testDay(t, "2026-10-01"), Completed: true, Count: 3, Value: 2, Notes: "noche"}},
+		MoodEntries: []models.MoodEntry{{
+			Date: testDay(t, "2026-10-01"), Slot: models.MoodSlotMorning, MoodScore: 8, EnergyLevel: 7,
+			Notes: "cansado", Tags: []string{"deporte"},
+		}},
+	}
+
+	type state struct {
+		description string
+		goal        int
+		log         models.HabitLog
+		mood        models.MoodEntry
+	}
+
+	// Lo que hay en la base de datos antes de importar
+	existing := state{
+		goal: 1,
+		log:  models.HabitLog{Completed: false, Count: 1, Value: 5, Notes: "mañana"},
+		mood: models.MoodEntry{MoodScore: 6, Notes: "cansado", Tags: []string{"trabajo"}},
+	}
+
+	tests := []struct {
+		strategy   string
+		dryRun     bool
+		wantAction string
+		want       state
+	}{
+		{strategy: models.ImportStrategySkip, wantAction: "skipped", want: existing},
+		{
+			strategy: models.ImportStrategyOverwrite, wantAction: "updated",
+			want: state{
+				description: "Novela", goal: 3,
+				log:  models.HabitLog{Completed: true, Count: 3, Value: 2, Notes: "noche"},
+				mood: models.MoodEntry{MoodScore: 8, EnergyLevel: 7, Notes: "cansado", Tags: []string{"deporte"}},
+			},
+		},
+		{
+			// Completado en cualquiera, el mayor recuento y valor, notas unidas sin repetir y
+			// los valores que faltaban en el registro de ánimo; la meta no se fusiona
+			strategy: models.ImportStrategyMerge, wantAction: "merged",
+			want: state{
+				description: "Novela", goal: 1,
+				log:  models.HabitLog{Completed: true, Count: 3, Value: 5, Notes: "mañana\nnoche"},
+				mood: models.MoodEntry{MoodScore: 6, EnergyLevel: 7, Notes: "cansado", Tags: []string{"deporte", "trabajo"}},
+			},
+		},
+		{strategy: models.ImportStrategyOverwrite, dryRun: true, wantAction: "updated", want: existing},
+	}
+
+	for _, tt := range tests {
+		name := tt.strategy
+		if tt.dryRun {
+			name += " en simulación"
+		}
+		t.Run(name, func(t *testing.T) {
+			repo := newTestRepo(t)
+			habitID, err := repo.CreateHabit(models.NewHabitInput{Name: "Leer", Frequency: models.FrequencyDaily, Goal: 1, Measure: models.MeasureCount})
+			if err != nil {
+				t.Fatal(err)
+			}
+			log := existing.log
+			err = repo.LogHabit(habitID, models.NewHabitLogInput{Date: "2026-10-01", Completed: log.Completed, Count: log.Count, Value: log.Value, Notes: log.Notes})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = repo.CreateMoodEntry(models.NewMoodEntryInput{
+				Date: "2026-10-01", Slot: models.MoodSlotMorning, MoodScore: existing.mood.MoodScore,
+				Notes: existing.mood.Notes, Tags: existing.mood.Tags,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			report, err := repo.ImportDocument(doc, tt.strategy, tt.dryRun)
+			if err != nil {
+				t.Fatalf("ImportDocument: %v", err)
+			}
+			if report.Committed == tt.dryRun {
+				t.Errorf("Committed = %v con dryRun = %v", report.Committed, tt.dryRun)
+			}
+			for _, conflict := range report.Conflicts {
+				if conflict.Action != tt.wantAction {
+					t.Errorf("conflicto %s/%s = %s, se esperaba %s", conflict.Table, conflict.Key, conflict.Action, tt.wantAction)
+				}
+			}
+			for _, table := range []string{"habits", "habit_logs", "mood_entries"} {
+				if stats := report.Tables[table]; stats.Inserted != 0 || stats.Skipped+stats.Updated != 1 {
+					t.Errorf("informe de %s = %+v, se esperaba un conflicto", table, stats)
+				}
+			}
+
+			habit, err := repo.GetHabit(habitID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if habit.Description != tt.want.description || habit.Goal != tt.want.goal {
+				t.Errorf("hábito = %q con meta %d, se esperaba %q con meta %d", habit.Description, habit.Goal, tt.want.description, tt.want.goal)
+			}
+
+			logs, err := repo.GetHabitLogs(habitID, "2026-10-01", "2026-10-01")
+			if err != nil || len(logs) != 1 {
+				t.Fatalf("registros = %+v (%v), se esperaba uno", logs, err)
+			}
+			got := logs[0]
+			if got.Completed != tt.want.log.Completed || got.Count != tt.want.log.Count || got.Value != tt.want.log.Value || got.Notes != tt.want.log.Notes {
+				t.Errorf("registro = %+v, se esperaba %+v", got, tt.want.log)
+			}
+
+			entries, err := repo.GetAllMoodEntries("2026-10-01", "2026-10-01")
+			if err != nil || len(entries) != 1 {
+				t.Fatalf("registros de ánimo = %+v (%v), se esperaba uno", entries, err)
+			}
+			mood := entries[0]
+			sort.Strings(mood.Tags)
+			if mood.MoodScore != tt.want.mood.MoodScore || mood.EnergyLevel != tt.want.mood.EnergyLevel ||
+				mood.Notes != tt.want.mood.Notes || strings.Join(mood.Tags, ",") != strings.Join(tt.want.mood.Tags, ",") {
+				t.Errorf("registro de ánimo = %+v, se esperaba %+v", mood, tt.want.mood)
+			}
+		})
+	}
+}
+
+// importedHabit busca por nombre un hábito importado
+func importedHabit(t *testing.T, repo *SQLiteRepo, name string) models.Habit {
+	t.Helper()
+	habits, err := repo.GetAllHabits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, habit := range habits {
+		if habit.Name == name {
+			return habit
+		}
+	}
+	t.Fatalf("no se importó el hábito %s", name)
+	return models.Habit{}
+}
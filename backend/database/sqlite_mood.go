@@ -1,7 +1,9 @@
 package database
 
 import (
+	"database/sql"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/kubaliski/habit-tracker/backend/models"
@@ -24,17 +26,30 @@ func (r *SQLiteRepo) CreateMoodEntry(mood models.NewMoodEntryInput) (int, error)
 		}
 	}()
 
+	// Los instantes se guardan en UTC; sin hora, el registro es de la franja o del día completo
+	var timestamp interface{}
+	if mood.Timestamp != "" {
+		var at time.Time
+		at, err = time.Parse(time.RFC3339, mood.Timestamp)
+		if err != nil {
+			return 0, fmt.Errorf("error al parsear timestamp: %w", err)
+		}
+		timestamp = at.UTC()
+	}
+
 	// Insertar entrada de estado de ánimo
 	query := `
 		INSERT INTO mood_entries (
-			date, mood_score, energy_level, anxiety_level, stress_level, sleep_hours, notes, created_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			date, timestamp, slot, mood_score, energy_level, anxiety_level, stress_level, sleep_hours, notes, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	now := time.Now()
 
 	result, err := tx.Exec(
 		query,
 		mood.Date,
+		timestamp,
+		mood.Slot,
 		mood.MoodScore,
 		mood.EnergyLevel,
 		mood.AnxietyLevel,
@@ -72,21 +87,20 @@ func (r *SQLiteRepo) CreateMoodEntry(mood models.NewMoodEntryInput) (int, error)
 	return int(id), nil
 }
 
-// GetMoodEntry obtiene un registro de estado de ánimo por su ID
-func (r *SQLiteRepo) GetMoodEntry(id int) (models.MoodEntry, error) {
-	// Consulta principal
-	query := `
-		SELECT id, date, mood_score, energy_level, anxiety_level, stress_level, sleep_hours, notes, created_at
-		FROM mood_entries
-		WHERE id = ?
-	`
+// moodEntryColumns son las columnas que leen las consultas de registros de estado de ánimo
+const moodEntryColumns = "id, date, timestamp, slot, mood_score, energy_level, anxiety_level, stress_level, sleep_hours, notes, created_at"
 
+// scanMoodEntry convierte una fila de mood_entries (sin etiquetas) en un MoodEntry
+func (r *SQLiteRepo) scanMoodEntry(row rowScanner) (models.MoodEntry, error) {
 	var entry models.MoodEntry
 	var dateStr, createdAt string
+	var timestamp sql.NullString
 
-	err := r.db.QueryRow(query, id).Scan(
+	if err := row.Scan(
 		&entry.ID,
 		&dateStr,
+		&timestamp,
+		&entry.Slot,
 		&entry.MoodScore,
 		&entry.EnergyLevel,
 		&entry.AnxietyLevel,
@@ -94,45 +108,47 @@ func (r *SQLiteRepo) GetMoodEntry(id int) (models.MoodEntry, error) {
 		&entry.SleepHours,
 		&entry.Notes,
 		&createdAt,
-	)
-	if err != nil {
-		return models.MoodEntry{}, fmt.Errorf("error al obtener registro de estado de ánimo: %w", err)
+	); err != nil {
+		return models.MoodEntry{}, err
 	}
 
-	// Convertir valores
+	// Convertir valores (los instantes se guardan en UTC y se muestran en la zona del usuario)
 	entry.Date, _ = time.Parse("2006-01-02", dateStr)
 	entry.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
-
-	// Obtener etiquetas
-	tagQuery := "SELECT tag FROM mood_tags WHERE mood_id = ?"
-	rows, err := r.db.Query(tagQuery, id)
-	if err != nil {
-		return models.MoodEntry{}, fmt.Errorf("error al consultar etiquetas: %w", err)
+	if timestamp.Valid {
+		at := r.localInstant(timestamp.String)
+		entry.Timestamp = &at
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var tag string
-		if err := rows.Scan(&tag); err != nil {
-			return models.MoodEntry{}, fmt.Errorf("error al escanear etiqueta: %w", err)
-		}
-		entry.Tags = append(entry.Tags, tag)
+	return entry, nil
+}
+
+// GetMoodEntry obtiene un registro de estado de ánimo por su ID
+func (r *SQLiteRepo) GetMoodEntry(id int) (models.MoodEntry, error) {
+	// Consulta principal
+	query := "SELECT " + moodEntryColumns + " FROM mood_entries WHERE id = ?"
+
+	entry, err := r.scanMoodEntry(r.db.QueryRow(query, id))
+	if err != nil {
+		return models.MoodEntry{}, fmt.Errorf("error al obtener registro de estado de ánimo: %w", err)
 	}
 
-	if err := rows.Err(); err != nil {
-		return models.MoodEntry{}, fmt.Errorf("error al iterar etiquetas: %w", err)
+	// Obtener etiquetas
+	entry.Tags, err = r.moodTags(id)
+	if err != nil {
+		return models.MoodEntry{}, err
 	}
 
 	return entry, nil
 }
 
-// GetAllMoodEntries obtiene todos los registros de estado de ánimo en un rango de fechas
+// GetAllMoodEntries obtiene todos los registros de estado de ánimo en un rango de fechas, del
+// más reciente al más antiguo (dentro de un día, por hora o franja)
 func (r *SQLiteRepo) GetAllMoodEntries(startDate, endDate string) ([]models.MoodEntry, error) {
-	query := `
-		SELECT id, date, mood_score, energy_level, anxiety_level, stress_level, sleep_hours, notes, created_at
+	query := "SELECT " + moodEntryColumns + `
 		FROM mood_entries
 		WHERE date >= ? AND date <= ?
-		ORDER BY date DESC
+		ORDER BY date DESC, id DESC
 	`
 
 	rows, err := r.db.Query(query, startDate, endDate)
@@ -143,27 +159,11 @@ func (r *SQLiteRepo) GetAllMoodEntries(startDate, endDate string) ([]models.Mood
 
 	var entries []models.MoodEntry
 	for rows.Next() {
-		var entry models.MoodEntry
-		var dateStr, createdAt string
-
-		if err := rows.Scan(
-			&entry.ID,
-			&dateStr,
-			&entry.MoodScore,
-			&entry.EnergyLevel,
-			&entry.AnxietyLevel,
-			&entry.StressLevel,
-			&entry.SleepHours,
-			&entry.Notes,
-			&createdAt,
-		); err != nil {
+		entry, err := r.scanMoodEntry(rows)
+		if err != nil {
 			return nil, fmt.Errorf("error al escanear registro de estado de ánimo: %w", err)
 		}
 
-		// Convertir valores
-		entry.Date, _ = time.Parse("2006-01-02", dateStr)
-		entry.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
-
 		entries = append(entries, entry)
 	}
 
@@ -171,30 +171,48 @@ func (r *SQLiteRepo) GetAllMoodEntries(startDate, endDate string) ([]models.Mood
 		return nil, fmt.Errorf("error al iterar registros de estado de ánimo: %w", err)
 	}
 
+	// Ordenar los registros de cada día por su momento
+	clock := r.dayClock()
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Date.Equal(entries[j].Date) {
+			return entries[i].Date.After(entries[j].Date)
+		}
+		return clock.moodEntryMinute(entries[i]) > clock.moodEntryMinute(entries[j])
+	})
+
 	// Obtener etiquetas para cada entrada
 	for i, entry := range entries {
-		tagQuery := "SELECT tag FROM mood_tags WHERE mood_id = ?"
-		tagRows, err := r.db.Query(tagQuery, entry.ID)
+		entries[i].Tags, err = r.moodTags(entry.ID)
 		if err != nil {
-			return nil, fmt.Errorf("error al consultar etiquetas: %w", err)
+			return nil, err
 		}
+	}
 
-		for tagRows.Next() {
-			var tag string
-			if err := tagRows.Scan(&tag); err != nil {
-				tagRows.Close()
-				return nil, fmt.Errorf("error al escanear etiqueta: %w", err)
-			}
-			entries[i].Tags = append(entries[i].Tags, tag)
-		}
+	return entries, nil
+}
 
-		tagRows.Close()
-		if err := tagRows.Err(); err != nil {
-			return nil, fmt.Errorf("error al iterar etiquetas: %w", err)
+// moodTags obtiene las etiquetas de un registro de estado de ánimo
+func (r *SQLiteRepo) moodTags(moodID int) ([]string, error) {
+	rows, err := r.db.Query("SELECT tag FROM mood_tags WHERE mood_id = ?", moodID)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar etiquetas: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, fmt.Errorf("error al escanear etiqueta: %w", err)
 		}
+		tags = append(tags, tag)
 	}
 
-	return entries, nil
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar etiquetas: %w", err)
+	}
+
+	return tags, nil
 }
 
 // UpdateMoodEntry actualiza un registro de estado de ánimo
@@ -4,11 +4,17 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"sync/atomic"
 )
 
 // SQLiteRepo implementa la interfaz Repository para SQLite
 type SQLiteRepo struct {
-	db *sql.DB
+	db   *sql.DB
+	path string
+
+	// Zona horaria y hora de cambio de día del usuario; se leen en cada consulta y pueden
+	// cambiar en caliente
+	clock atomic.Pointer[dayClock]
 }
 
 // NewSQLiteRepo crea una nueva instancia de SQLiteRepo
@@ -23,10 +29,11 @@ func NewSQLiteRepo(dbPath string) (*SQLiteRepo, error) {
 		return nil, fmt.Errorf("error al conectar con la base de datos: %w", err)
 	}
 
-	repo := &SQLiteRepo{db: db}
+	repo := &SQLiteRepo{db: db, path: dbPath}
 
 	// Inicializar la base de datos
 	if err := repo.initDB(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("error al inicializar la base de datos: %w", err)
 	}
 
@@ -38,104 +45,13 @@ func NewSQLiteRepo(dbPath string) (*SQLiteRepo, error) {
 	return repo, nil
 }
 
-// initDB crea las tablas necesarias si no existen
+// initDB aplica las migraciones pendientes del esquema
 func (r *SQLiteRepo) initDB() error {
-	// Tabla para los hábitos
-	_, err := r.db.Exec(`
-	CREATE TABLE IF NOT EXISTS habits (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		name TEXT NOT NULL,
-		description TEXT,
-		category TEXT,
-		frequency TEXT NOT NULL,
-		goal INTEGER DEFAULT 1,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-		active INTEGER DEFAULT 1
-	)`)
-	if err != nil {
-		return err
-	}
-
-	// Tabla para el registro diario de hábitos
-	_, err = r.db.Exec(`
-	CREATE TABLE IF NOT EXISTS habit_logs (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		habit_id INTEGER NOT NULL,
-		date TEXT NOT NULL,
-		completed INTEGER DEFAULT 0,
-		count INTEGER DEFAULT 0,
-		notes TEXT,
-		FOREIGN KEY (habit_id) REFERENCES habits(id) ON DELETE CASCADE,
-		UNIQUE(habit_id, date)
-	)`)
-	if err != nil {
-		return err
-	}
-
-	// Tabla para el registro de estados de ánimo
-	_, err = r.db.Exec(`
-	CREATE TABLE IF NOT EXISTS mood_entries (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		date TEXT NOT NULL UNIQUE,
-		mood_score INTEGER NOT NULL,
-		energy_level INTEGER,
-		anxiety_level INTEGER,
-		stress_level INTEGER,
-		sleep_hours REAL,
-		notes TEXT,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-	)`)
-	if err != nil {
+	if err := r.migrate(); err != nil {
 		return err
 	}
 
-	// Tabla para etiquetas de estado mental
-	_, err = r.db.Exec(`
-	CREATE TABLE IF NOT EXISTS mood_tags (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		mood_id INTEGER NOT NULL,
-		tag TEXT NOT NULL,
-		FOREIGN KEY (mood_id) REFERENCES mood_entries(id) ON DELETE CASCADE,
-		UNIQUE(mood_id, tag)
-	)`)
-	if err != nil {
-		return err
-	}
-
-	// Tabla para tipos de bebidas con cafeína
-	_, err = r.db.Exec(`
-	CREATE TABLE IF NOT EXISTS caffeine_beverages (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		name TEXT NOT NULL,
-		caffeine_content REAL NOT NULL,
-		standard_unit TEXT NOT NULL,
-		standard_unit_value REAL NOT NULL,
-		category TEXT,
-		image_path TEXT,
-		active INTEGER DEFAULT 1
-	)`)
-	if err != nil {
-		return err
-	}
-
-	// Tabla para registros de consumo de cafeína
-	_, err = r.db.Exec(`
-	CREATE TABLE IF NOT EXISTS caffeine_intake (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		timestamp TIMESTAMP NOT NULL,
-		beverage_id INTEGER NOT NULL,
-		beverage_name TEXT NOT NULL,
-		amount REAL NOT NULL,
-		unit TEXT NOT NULL,
-		total_caffeine REAL NOT NULL,
-		perceived_effects TEXT,
-		related_activity TEXT,
-		notes TEXT,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (beverage_id) REFERENCES caffeine_beverages(id) ON DELETE CASCADE
-	)`)
-	if err != nil {
+	if err := r.loadDayClock(); err != nil {
 		return err
 	}
 
@@ -0,0 +1,320 @@
+package database
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+// ==================== MÉTODOS PARA AJUSTES ====================
+
+// Claves de ajustes almacenados en la tabla settings
+const (
+	settingCaffeineHalfLife   = "caffeine.half_life_hours"
+	settingCaffeineAbsorption = "caffeine.absorption_minutes"
+	settingCaffeineBedtime    = "caffeine.bedtime"
+	settingCaffeineThreshold  = "caffeine.bedtime_threshold_mg"
+	settingCaffeineDailyLimit = "caffeine.limit_daily_mg"
+	settingCaffeineIntakeMax  = "caffeine.limit_intake_mg"
+	settingCaffeineWeekLimit  = "caffeine.limit_weekly_mg"
+	settingServerEnabled      = "server.enabled"
+	settingServerAddress      = "server.address"
+	settingServerToken        = "server.token"
+	settingTimezone           = "time.timezone"
+	settingRolloverHour       = "time.rollover_hour"
+)
+
+// Valores predeterminados de los ajustes
+const (
+	defaultCaffeineHalfLife   = 5.0
+	defaultCaffeineAbsorption = 45
+	defaultCaffeineBedtime    = "23:00"
+	defaultCaffeineThreshold  = 50.0
+)
+
+// getSetting obtiene el valor de un ajuste. El segundo valor indica si existe.
+func (r *SQLiteRepo) getSetting(key string) (string, bool, error) {
+	var value string
+	err := r.db.QueryRow("SELECT value FROM settings WHERE key = ?", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("error al obtener ajuste %s: %w", key, err)
+	}
+
+	return value, true, nil
+}
+
+// getStringSetting obtiene un ajuste de texto o su valor predeterminado
+func (r *SQLiteRepo) getStringSetting(key string, fallback string) (string, error) {
+	value, ok, err := r.getSetting(key)
+	if err != nil || !ok {
+		return fallback, err
+	}
+
+	return value, nil
+}
+
+// getFloatSetting obtiene un ajuste numérico o su valor predeterminado
+func (r *SQLiteRepo) getFloatSetting(key string, fallback float64) (float64, error) {
+	value, ok, err := r.getSetting(key)
+	if err != nil || !ok {
+		return fallback, err
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fallback, fmt.Errorf("valor inválido para el ajuste %s: %w", key, err)
+	}
+
+	return parsed, nil
+}
+
+// getIntSetting obtiene un ajuste entero o su valor predeterminado
+func (r *SQLiteRepo) getIntSetting(key string, fallback int) (int, error) {
+	value, ok, err := r.getSetting(key)
+	if err != nil || !ok {
+		return fallback, err
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fallback, fmt.Errorf("valor inválido para el ajuste %s: %w", key, err)
+	}
+
+	return parsed, nil
+}
+
+// setSetting guarda un ajuste, creándolo si no existe
+func setSetting(tx *sql.Tx, key, value string) error {
+	_, err := tx.Exec(`
+		INSERT INTO settings (key, value, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at
+	`, key, value, time.Now())
+	if err != nil {
+		return fmt.Errorf("error al guardar ajuste %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// GetCaffeineSettings obtiene los parámetros del modelo de metabolismo de la cafeína
+func (r *SQLiteRepo) GetCaffeineSettings() (models.CaffeineSettings, error) {
+	halfLife, err := r.getFloatSetting(settingCaffeineHalfLife, defaultCaffeineHalfLife)
+	if err != nil {
+		return models.CaffeineSettings{}, err
+	}
+
+	absorption, err := r.getIntSetting(settingCaffeineAbsorption, defaultCaffeineAbsorption)
+	if err != nil {
+		return models.CaffeineSettings{}, err
+	}
+
+	bedtime, err := r.getStringSetting(settingCaffeineBedtime, defaultCaffeineBedtime)
+	if err != nil {
+		return models.CaffeineSettings{}, err
+	}
+
+	threshold, err := r.getFloatSetting(settingCaffeineThreshold, defaultCaffeineThreshold)
+	if err != nil {
+		return models.CaffeineSettings{}, err
+	}
+
+	return models.CaffeineSettings{
+		HalfLifeHours:     halfLife,
+		AbsorptionMinutes: absorption,
+		Bedtime:           bedtime,
+		BedtimeThreshold:  threshold,
+	}, nil
+}
+
+// UpdateCaffeineSettings actualiza los parámetros del modelo de metabolismo de la cafeína
+func (r *SQLiteRepo) UpdateCaffeineSettings(input models.UpdateCaffeineSettingsInput) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	if input.HalfLifeHours > 0 {
+		if err := setSetting(tx, settingCaffeineHalfLife, strconv.FormatFloat(input.HalfLifeHours, 'f', -1, 64)); err != nil {
+			return err
+		}
+	}
+
+	if input.AbsorptionMinutes != nil {
+		if err := setSetting(tx, settingCaffeineAbsorption, strconv.Itoa(*input.AbsorptionMinutes)); err != nil {
+			return err
+		}
+	}
+
+	if input.Bedtime != "" {
+		if err := setSetting(tx, settingCaffeineBedtime, input.Bedtime); err != nil {
+			return err
+		}
+	}
+
+	if input.BedtimeThreshold > 0 {
+		if err := setSetting(tx, settingCaffeineThreshold, strconv.FormatFloat(input.BedtimeThreshold, 'f', -1, 64)); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error al confirmar transacción: %w", err)
+	}
+
+	return nil
+}
+
+// GetCaffeineLimits obtiene los límites de consumo de cafeína configurados
+func (r *SQLiteRepo) GetCaffeineLimits() (models.CaffeineLimits, error) {
+	daily, err := r.getFloatSetting(settingCaffeineDailyLimit, 0)
+	if err != nil {
+		return models.CaffeineLimits{}, err
+	}
+
+	intake, err := r.getFloatSetting(settingCaffeineIntakeMax, 0)
+	if err != nil {
+		return models.CaffeineLimits{}, err
+	}
+
+	weekly, err := r.getFloatSetting(settingCaffeineWeekLimit, 0)
+	if err != nil {
+		return models.CaffeineLimits{}, err
+	}
+
+	return models.CaffeineLimits{
+		DailyLimit:  daily,
+		IntakeLimit: intake,
+		WeeklyLimit: weekly,
+	}, nil
+}
+
+// UpdateCaffeineLimits actualiza los límites de consumo de cafeína
+func (r *SQLiteRepo) UpdateCaffeineLimits(input models.UpdateCaffeineLimitsInput) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	limits := []struct {
+		key   string
+		value *float64
+	}{
+		{settingCaffeineDailyLimit, input.DailyLimit},
+		{settingCaffeineIntakeMax, input.IntakeLimit},
+		{settingCaffeineWeekLimit, input.WeeklyLimit},
+	}
+
+	for _, limit := range limits {
+		if limit.value == nil {
+			continue
+		}
+		if err := setSetting(tx, limit.key, strconv.FormatFloat(*limit.value, 'f', -1, 64)); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error al confirmar transacción: %w", err)
+	}
+
+	return nil
+}
+
+// GetServerSettings obtiene la configuración del servidor REST local.
+// Si todavía no existe un token de acceso, se genera y se guarda.
+func (r *SQLiteRepo) GetServerSettings() (models.ServerSettings, error) {
+	enabled, err := r.getStringSetting(settingServerEnabled, "false")
+	if err != nil {
+		return models.ServerSettings{}, err
+	}
+
+	address, err := r.getStringSetting(settingServerAddress, models.DefaultServerAddress)
+	if err != nil {
+		return models.ServerSettings{}, err
+	}
+
+	token, err := r.getStringSetting(settingServerToken, "")
+	if err != nil {
+		return models.ServerSettings{}, err
+	}
+
+	if token == "" {
+		if token, err = r.regenerateServerToken(); err != nil {
+			return models.ServerSettings{}, err
+		}
+	}
+
+	return models.ServerSettings{
+		Enabled: enabled == "true",
+		Address: address,
+		Token:   token,
+	}, nil
+}
+
+// UpdateServerSettings actualiza la configuración del servidor REST local
+func (r *SQLiteRepo) UpdateServerSettings(input models.UpdateServerSettingsInput) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	if input.Enabled != nil {
+		if err := setSetting(tx, settingServerEnabled, strconv.FormatBool(*input.Enabled)); err != nil {
+			return err
+		}
+	}
+
+	if input.Address != "" {
+		if err := setSetting(tx, settingServerAddress, input.Address); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error al confirmar transacción: %w", err)
+	}
+
+	if input.RegenerateToken {
+		if _, err := r.regenerateServerToken(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// regenerateServerToken crea y guarda un token aleatorio de 256 bits
+func (r *SQLiteRepo) regenerateServerToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("error al generar token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return "", fmt.Errorf("error al iniciar transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := setSetting(tx, settingServerToken, token); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("error al confirmar transacción: %w", err)
+	}
+
+	return token, nil
+}
@@ -2,267 +2,268 @@ package database
 
 import (
 	"fmt"
-	"log"
 	"sort"
 	"time"
 
 	"github.com/kubaliski/habit-tracker/backend/models"
+	"github.com/kubaliski/habit-tracker/backend/schedule"
 )
 
-// GetHabitStats obtiene estadísticas para un hábito específico
-func (r *SQLiteRepo) GetHabitStats(habitID int, period string) (map[string]interface{}, error) {
+// GetHabitStats obtiene estadísticas para un hábito específico en un período
+func (r *SQLiteRepo) GetHabitStats(habitID int, period models.DateRange) (models.HabitStats, error) {
 	// Obtener información del hábito
 	habit, err := r.GetHabit(habitID)
 	if err != nil {
-		return nil, fmt.Errorf("error al obtener información del hábito: %w", err)
+		return models.HabitStats{}, fmt.Errorf("error al obtener información del hábito: %w", err)
 	}
 
-	// Determinar rango de fechas según el período
-	now := time.Now()
-	var startDate time.Time
-
-	switch period {
-	case "week":
-		startDate = now.AddDate(0, 0, -7)
-	case "month":
-		startDate = now.AddDate(0, -1, 0)
-	case "year":
-		startDate = now.AddDate(-1, 0, 0)
-	default:
-		startDate = now.AddDate(0, 0, -30) // Valor predeterminado: último mes
+	// Los hábitos a evitar solo se evalúan desde que se siguen: antes no hay días limpios ni fallos
+	evaluated := period
+	if habit.Avoid {
+		evaluated.StartDate, evaluated.EndDate, err = r.avoidRange(habit, period.StartDate, period.EndDate)
+		if err != nil {
+			return models.HabitStats{}, err
+		}
 	}
 
-	// Formato de fechas para la consulta
-	startDateStr := startDate.Format("2006-01-02")
-	endDateStr := now.Format("2006-01-02")
+	startDate, endDate, err := r.rangeBounds(evaluated)
+	if err != nil {
+		return models.HabitStats{}, err
+	}
 
 	// Obtener registros en el período
-	logs, err := r.GetHabitLogs(habitID, startDateStr, endDateStr)
+	logs, err := r.statsHabitLogs(habit, evaluated.StartDate, evaluated.EndDate)
 	if err != nil {
-		return nil, fmt.Errorf("error al obtener registros del hábito: %w", err)
+		return models.HabitStats{}, fmt.Errorf("error al obtener registros del hábito: %w", err)
+	}
+
+	// Historial de metas: cada día se evalúa con la meta vigente entonces
+	versions, err := r.habitGoalVersions(habit)
+	if err != nil {
+		return models.HabitStats{}, fmt.Errorf("error al obtener el historial de metas: %w", err)
 	}
 
 	// Calcular estadísticas
 	totalDays := len(logs)
 	completedDays := 0
 	totalCount := 0
-	streak := 0
-	currentStreak := 0
-	lastDate := time.Time{}
+	totalValue := 0.0
+	targetMetDays := 0
 
-	for i, log := range logs {
+	for _, log := range logs {
 		if log.Completed {
 			completedDays++
 		}
 		totalCount += log.Count
-
-		// Calcular racha actual
-		if i == 0 {
-			if log.Completed {
-				currentStreak = 1
-			}
-			lastDate = log.Date
-		} else {
-			dayDiff := int(lastDate.Sub(log.Date).Hours() / 24)
-			if log.Completed && dayDiff == 1 {
-				currentStreak++
-			} else if !log.Completed {
-				currentStreak = 0
-			}
-			lastDate = log.Date
-		}
-
-		// Actualizar racha máxima
-		if currentStreak > streak {
-			streak = currentStreak
+		totalValue += log.Value
+		if version, _ := goalVersionAt(versions, log.Date.Format("2006-01-02")); habit.Quantitative() && log.Value >= version.Target {
+			targetMetDays++
 		}
 	}
 
-	// Calcular tasas
-	completionRate := 0.0
+	averageCount, averageValue := 0.0, 0.0
 	if totalDays > 0 {
-		completionRate = float64(completedDays) / float64(totalDays) * 100
+		averageCount = float64(totalCount) / float64(totalDays)
+		averageValue = totalValue / float64(totalDays)
 	}
 
-	averageCount := 0.0
-	if totalDays > 0 {
-		averageCount = float64(totalCount) / float64(totalDays)
+	unit := periodUnit(habit.Frequency)
+	excused, err := r.periodExcusedDates(habit.ID, startDate, endDate)
+	if err != nil {
+		return models.HabitStats{}, err
+	}
+	excusedDays := 0
+	for date := range excused {
+		if date >= evaluated.StartDate && date <= evaluated.EndDate {
+			excusedDays++
+		}
 	}
 
+	// Los promedios por día y el objetivo acumulado solo cuentan los días ya transcurridos; el
+	// objetivo acumulado, además, solo los días que tocaban y con el objetivo de cada día
+	elapsed := models.DateRange{StartDate: evaluated.StartDate, EndDate: evaluated.EndDate}
+	if today := r.Today(); elapsed.EndDate > today {
+		elapsed.EndDate = today
+	}
+	dailyAverage, goalAttainment := 0.0, 0.0
+	if days := rangeDays(elapsed); days > 0 {
+		dailyAverage = totalValue / float64(days)
+	}
+	if habit.Quantitative() {
+		if expected := expectedTotal(versions, elapsed, excused); expected > 0 {
+			goalAttainment = totalValue / expected * 100
+		}
+	}
+
+	// Rachas y cumplimiento sobre el calendario completo, en la unidad del hábito.
+	// Los días sin registro cuentan como fallos; los que no tocan y los justificados, ni como
+	// fallos ni como éxitos.
+	periodStats := computePeriodStats(logs, versions, excused, startDate, endDate, r.Today(), lastPeriodDecided(habit, unit))
+
 	// Construir resultado
-	stats := map[string]interface{}{
-		"habit_id":        habitID,
-		"habit_name":      habit.Name,
-		"period":          period,
-		"total_days":      totalDays,
-		"completed_days":  completedDays,
-		"completion_rate": completionRate,
-		"total_count":     totalCount,
-		"average_count":   averageCount,
-		"max_streak":      streak,
-		"current_streak":  currentStreak,
-		"start_date":      startDateStr,
-		"end_date":        endDateStr,
+	stats := models.HabitStats{
+		HabitID:          habitID,
+		HabitName:        habit.Name,
+		Period:           period.Period,
+		Frequency:        habit.Frequency,
+		Goal:             habit.Goal,
+		Unit:             unit,
+		StartDate:        period.StartDate,
+		EndDate:          period.EndDate,
+		TotalDays:        totalDays,
+		CompletedDays:    completedDays,
+		TotalPeriods:     periodStats.TotalPeriods,
+		CompletedPeriods: periodStats.CompletedPeriods,
+		CompletionRate:   periodStats.CompletionRate,
+		TotalCount:       totalCount,
+		AverageCount:     averageCount,
+		Measure:          habit.Measure,
+		MeasureUnit:      habit.Unit,
+		TotalValue:       totalValue,
+		AverageValue:     averageValue,
+		DailyAverage:     dailyAverage,
+		TargetMetDays:    targetMetDays,
+		GoalAttainment:   goalAttainment,
+		MaxStreak:        periodStats.MaxStreak,
+		CurrentStreak:    periodStats.CurrentStreak,
+		ExcusedDays:      excusedDays,
+	}
+	if habit.Quantitative() {
+		stats.Target = habit.Target
+	}
+
+	if habit.Avoid {
+		if err := r.fillSlipStats(&stats, habit, period); err != nil {
+			return models.HabitStats{}, err
+		}
 	}
 
 	return stats, nil
 }
 
+// expectedTotal suma el objetivo diario vigente en cada día del rango que tocaba según el
+// calendario de entonces (todos, en las cuotas), sin los justificados
+func expectedTotal(versions []models.HabitGoalVersion, period models.DateRange, excused map[string]bool) float64 {
+	start, err1 := time.Parse("2006-01-02", period.StartDate)
+	end, err2 := time.Parse("2006-01-02", period.EndDate)
+	if err1 != nil || err2 != nil {
+		return 0
+	}
+
+	total := 0.0
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		version, _ := goalVersionAt(versions, date)
+		if !excused[date] && schedule.Scheduled(version.Schedule, day) {
+			total += version.Target
+		}
+	}
+	return total
+}
+
 // GetMoodStats obtiene estadísticas de estado de ánimo para un período
-func (r *SQLiteRepo) GetMoodStats(period string) (map[string]interface{}, error) {
-	// Determinar rango de fechas según el período
-	now := time.Now()
-	var startDate time.Time
-
-	switch period {
-	case "week":
-		startDate = now.AddDate(0, 0, -7)
-	case "month":
-		startDate = now.AddDate(0, -1, 0)
-	case "year":
-		startDate = now.AddDate(-1, 0, 0)
-	default:
-		startDate = now.AddDate(0, 0, -30) // Valor predeterminado: último mes
-	}
-
-	// Formato de fechas para la consulta
-	startDateStr := startDate.Format("2006-01-02")
-	endDateStr := now.Format("2006-01-02")
+func (r *SQLiteRepo) GetMoodStats(period models.DateRange) (models.MoodStats, error) {
+	stats := models.MoodStats{
+		Period:     period.Period,
+		StartDate:  period.StartDate,
+		EndDate:    period.EndDate,
+		CommonTags: []models.TagCount{},
+	}
 
-	// Obtener registros en el período
-	entries, err := r.GetAllMoodEntries(startDateStr, endDateStr)
+	// Obtener los agregados diarios del período: cada día pesa lo mismo, tenga uno o
+	// varios registros
+	days, err := r.GetDailyMoods(stats.StartDate, stats.EndDate)
 	if err != nil {
-		return nil, fmt.Errorf("error al obtener registros de estado de ánimo: %w", err)
+		return stats, fmt.Errorf("error al obtener registros de estado de ánimo: %w", err)
 	}
 
 	// Calcular estadísticas
-	totalEntries := len(entries)
-	if totalEntries == 0 {
-		return map[string]interface{}{
-			"period":        period,
-			"total_entries": 0,
-			"start_date":    startDateStr,
-			"end_date":      endDateStr,
-			"message":       "No hay datos para el período solicitado",
-		}, nil
+	totalDays := len(days)
+	if totalDays == 0 {
+		stats.Message = models.NoDataMessage
+		return stats, nil
 	}
 
 	var sumMood, sumEnergy, sumAnxiety, sumStress, sumSleep float64
-	var countSleep int
+	var countSleep, totalEntries int
 
-	// Mapas para contar frecuencia de etiquetas
+	// Mapas para contar frecuencia de etiquetas (días en que aparece cada una)
 	tagFrequency := make(map[string]int)
 
-	for _, entry := range entries {
-		sumMood += float64(entry.MoodScore)
-		sumEnergy += float64(entry.EnergyLevel)
-		sumAnxiety += float64(entry.AnxietyLevel)
-		sumStress += float64(entry.StressLevel)
+	for _, day := range days {
+		totalEntries += day.Entries
+		sumMood += day.MoodScore.Mean
+		sumEnergy += meanOf(day.EnergyLevel)
+		sumAnxiety += meanOf(day.AnxietyLevel)
+		sumStress += meanOf(day.StressLevel)
 
-		if entry.SleepHours > 0 {
-			sumSleep += entry.SleepHours
+		if day.SleepHours > 0 {
+			sumSleep += day.SleepHours
 			countSleep++
 		}
 
 		// Contar frecuencia de etiquetas
-		for _, tag := range entry.Tags {
+		for _, tag := range day.Tags {
 			tagFrequency[tag]++
 		}
 	}
 
 	// Calcular promedios
-	avgMood := sumMood / float64(totalEntries)
-	avgEnergy := sumEnergy / float64(totalEntries)
-	avgAnxiety := sumAnxiety / float64(totalEntries)
-	avgStress := sumStress / float64(totalEntries)
+	stats.HasData = true
+	stats.TotalEntries = totalEntries
+	stats.TotalDays = totalDays
+	stats.AvgMoodScore = sumMood / float64(totalDays)
+	stats.AvgEnergyLevel = sumEnergy / float64(totalDays)
+	stats.AvgAnxietyLevel = sumAnxiety / float64(totalDays)
+	stats.AvgStressLevel = sumStress / float64(totalDays)
 
-	avgSleep := 0.0
 	if countSleep > 0 {
-		avgSleep = sumSleep / float64(countSleep)
+		stats.AvgSleepHours = sumSleep / float64(countSleep)
 	}
 
 	// Encontrar las etiquetas más comunes
-	type tagCount struct {
-		Tag   string
-		Count int
-	}
-	var topTags []tagCount
-
+	var topTags []models.TagCount
 	for tag, count := range tagFrequency {
-		topTags = append(topTags, tagCount{Tag: tag, Count: count})
+		topTags = append(topTags, models.TagCount{Tag: tag, Count: count})
 	}
 
-	// Ordenar etiquetas por frecuencia (de mayor a menor)
+	// Ordenar etiquetas por frecuencia (de mayor a menor, y por nombre en caso de empate)
 	sort.Slice(topTags, func(i, j int) bool {
-		return topTags[i].Count > topTags[j].Count
+		if topTags[i].Count != topTags[j].Count {
+			return topTags[i].Count > topTags[j].Count
+		}
+		return topTags[i].Tag < topTags[j].Tag
 	})
 
 	// Limitar a las 5 etiquetas más comunes
-	commonTags := []map[string]interface{}{}
-	for i, tc := range topTags {
-		if i >= 5 {
-			break
-		}
-		commonTags = append(commonTags, map[string]interface{}{
-			"tag":   tc.Tag,
-			"count": tc.Count,
-		})
-	}
-
-	// Construir resultado
-	stats := map[string]interface{}{
-		"period":            period,
-		"total_entries":     totalEntries,
-		"avg_mood_score":    avgMood,
-		"avg_energy_level":  avgEnergy,
-		"avg_anxiety_level": avgAnxiety,
-		"avg_stress_level":  avgStress,
-		"avg_sleep_hours":   avgSleep,
-		"common_tags":       commonTags,
-		"start_date":        startDateStr,
-		"end_date":          endDateStr,
+	if len(topTags) > 5 {
+		topTags = topTags[:5]
 	}
+	stats.CommonTags = append(stats.CommonTags, topTags...)
 
 	return stats, nil
 }
 
 // GetCaffeineStats obtiene estadísticas de consumo de cafeína para un período
-func (r *SQLiteRepo) GetCaffeineStats(period string) (map[string]interface{}, error) {
-	// Determinar rango de fechas según el período
-	now := time.Now()
-	var startDate time.Time
-
-	switch period {
-	case "week":
-		startDate = now.AddDate(0, 0, -7)
-	case "month":
-		startDate = now.AddDate(0, -1, 0)
-	case "year":
-		startDate = now.AddDate(-1, 0, 0)
-	default:
-		startDate = now.AddDate(0, 0, -30) // Valor predeterminado: último mes
-	}
-
-	// Formato de fechas para la consulta
-	startDateStr := startDate.Format("2006-01-02")
-	endDateStr := now.Format("2006-01-02")
+func (r *SQLiteRepo) GetCaffeineStats(period models.DateRange) (models.CaffeineStats, error) {
+	stats := models.CaffeineStats{
+		Period:          period.Period,
+		StartDate:       period.StartDate,
+		EndDate:         period.EndDate,
+		CommonBeverages: []models.BeverageUsage{},
+	}
 
 	// Obtener registros en el período
-	intakes, err := r.GetCaffeineIntakeRange(startDateStr, endDateStr)
+	intakes, err := r.GetCaffeineIntakeRange(stats.StartDate, stats.EndDate)
 	if err != nil {
-		return nil, fmt.Errorf("error al obtener registros de consumo de cafeína: %w", err)
+		return stats, fmt.Errorf("error al obtener registros de consumo de cafeína: %w", err)
 	}
 
 	// Calcular estadísticas
 	totalIntakes := len(intakes)
 	if totalIntakes == 0 {
-		return map[string]interface{}{
-			"period":        period,
-			"total_intakes": 0,
-			"start_date":    startDateStr,
-			"end_date":      endDateStr,
-			"message":       "No hay datos para el período solicitado",
-		}, nil
+		stats.Message = models.NoDataMessage
+		return stats, nil
 	}
 
 	var totalCaffeine float64
@@ -279,167 +280,110 @@ func (r *SQLiteRepo) GetCaffeineStats(period string) (map[string]interface{}, er
 		beverageCaffeine[intake.BeverageName] += intake.TotalCaffeine
 
 		// Agrupar por día
-		dateStr := intake.Timestamp.Format("2006-01-02")
-		dailyCaffeine[dateStr] += intake.TotalCaffeine
+		dailyCaffeine[r.DayOf(intake.Timestamp)] += intake.TotalCaffeine
 	}
 
-	// Calcular promedios y máximos
-	avgCaffeinePerIntake := totalCaffeine / float64(totalIntakes)
+	stats.HasData = true
+	stats.TotalIntakes = totalIntakes
+	stats.TotalCaffeine = totalCaffeine
+	stats.AvgCaffeinePerIntake = totalCaffeine / float64(totalIntakes)
 
 	// Calcular consumo diario promedio y máximo
 	var sumDailyCaffeine float64
-	var maxDailyCaffeine float64
-	var maxDailyDate string
-	uniqueDays := len(dailyCaffeine)
+	stats.UniqueDays = len(dailyCaffeine)
 
 	for date, amount := range dailyCaffeine {
 		sumDailyCaffeine += amount
-		if amount > maxDailyCaffeine {
-			maxDailyCaffeine = amount
-			maxDailyDate = date
+		if amount > stats.MaxDailyCaffeine || (amount == stats.MaxDailyCaffeine && date < stats.MaxDailyDate) {
+			stats.MaxDailyCaffeine = amount
+			stats.MaxDailyDate = date
 		}
 	}
 
-	avgDailyCaffeine := 0.0
-	if uniqueDays > 0 {
-		avgDailyCaffeine = sumDailyCaffeine / float64(uniqueDays)
+	if stats.UniqueDays > 0 {
+		stats.AvgDailyCaffeine = sumDailyCaffeine / float64(stats.UniqueDays)
 	}
 
 	// Encontrar las bebidas más comunes
-	type beverageStats struct {
-		Name            string
-		Count           int
-		TotalCaffeine   float64
-		AverageCaffeine float64
-		PercentOfTotal  float64
-	}
-
-	var topBeverages []beverageStats
+	var topBeverages []models.BeverageUsage
 	for name, count := range beverageFrequency {
-		avg := beverageCaffeine[name] / float64(count)
-		percent := (beverageCaffeine[name] / totalCaffeine) * 100
+		percent := 0.0
+		if totalCaffeine > 0 {
+			percent = (beverageCaffeine[name] / totalCaffeine) * 100
+		}
 
-		topBeverages = append(topBeverages, beverageStats{
+		topBeverages = append(topBeverages, models.BeverageUsage{
 			Name:            name,
 			Count:           count,
 			TotalCaffeine:   beverageCaffeine[name],
-			AverageCaffeine: avg,
+			AverageCaffeine: beverageCaffeine[name] / float64(count),
 			PercentOfTotal:  percent,
 		})
 	}
 
-	// Ordenar bebidas por frecuencia (de mayor a menor)
+	// Ordenar bebidas por frecuencia (de mayor a menor, y por nombre en caso de empate)
 	sort.Slice(topBeverages, func(i, j int) bool {
-		return topBeverages[i].Count > topBeverages[j].Count
+		if topBeverages[i].Count != topBeverages[j].Count {
+			return topBeverages[i].Count > topBeverages[j].Count
+		}
+		return topBeverages[i].Name < topBeverages[j].Name
 	})
 
 	// Limitar a las 5 bebidas más comunes
-	commonBeverages := []map[string]interface{}{}
-	for i, beverage := range topBeverages {
-		if i >= 5 {
-			break
-		}
-		commonBeverages = append(commonBeverages, map[string]interface{}{
-			"name":             beverage.Name,
-			"count":            beverage.Count,
-			"total_caffeine":   beverage.TotalCaffeine,
-			"average_caffeine": beverage.AverageCaffeine,
-			"percent_of_total": beverage.PercentOfTotal,
-		})
-	}
-
-	// Construir resultado
-	stats := map[string]interface{}{
-		"period":                  period,
-		"total_intakes":           totalIntakes,
-		"unique_days":             uniqueDays,
-		"total_caffeine":          totalCaffeine,
-		"avg_caffeine_per_intake": avgCaffeinePerIntake,
-		"avg_daily_caffeine":      avgDailyCaffeine,
-		"max_daily_caffeine":      maxDailyCaffeine,
-		"max_daily_date":          maxDailyDate,
-		"common_beverages":        commonBeverages,
-		"start_date":              startDateStr,
-		"end_date":                endDateStr,
+	if len(topBeverages) > 5 {
+		topBeverages = topBeverages[:5]
 	}
+	stats.CommonBeverages = append(stats.CommonBeverages, topBeverages...)
 
 	return stats, nil
 }
 
 // GetCorrelationStats analiza posibles correlaciones entre el consumo de cafeína y el estado de ánimo
-func (r *SQLiteRepo) GetCorrelationStats() (map[string]interface{}, error) {
-	// Obtener datos de los últimos 90 días para tener suficientes datos para el análisis
-	now := time.Now()
-	startDate := now.AddDate(0, 0, -90)
-
-	startDateStr := startDate.Format("2006-01-02")
-	endDateStr := now.Format("2006-01-02")
-
-	// Obtener registros de estado de ánimo
-	moodEntries, err := r.GetAllMoodEntries(startDateStr, endDateStr)
+// en un período
+func (r *SQLiteRepo) GetCorrelationStats(period models.DateRange) (models.CorrelationStats, error) {
+	stats := models.CorrelationStats{
+		Period:       period.Period,
+		WindowDays:   rangeDays(period),
+		StartDate:    period.StartDate,
+		EndDate:      period.EndDate,
+		Correlations: []models.CorrelationResult{},
+	}
+
+	// Obtener el estado de ánimo agregado de cada día
+	moodDays, err := r.GetDailyMoods(stats.StartDate, stats.EndDate)
 	if err != nil {
-		return nil, fmt.Errorf("error al obtener registros de estado de ánimo: %w", err)
+		return stats, fmt.Errorf("error al obtener registros de estado de ánimo: %w", err)
 	}
 
 	// Mapeo de fecha a estado de ánimo
-	moodByDate := make(map[string]models.MoodEntry)
-	for _, entry := range moodEntries {
-		dateStr := entry.Date.Format("2006-01-02")
-		moodByDate[dateStr] = entry
+	moodByDate := make(map[string]models.DailyMood)
+	for _, day := range moodDays {
+		moodByDate[day.Date] = day
 	}
 
 	// Obtener consumo diario de cafeína
-	caffeineByDate := make(map[string]float64)
-
-	// Para cada día en el período
-	for d := startDate; !d.After(now); d = d.AddDate(0, 0, 1) {
-		dateStr := d.Format("2006-01-02")
-
-		// Obtener consumo de cafeína para este día
-		caffeine, err := r.GetDailyCaffeineTotal(dateStr)
-		if err != nil {
-			// Si hay error, continuar con el siguiente día
-			log.Printf("Error al obtener consumo de cafeína para %s: %v", dateStr, err)
-			continue
-		}
-
-		caffeineByDate[dateStr] = caffeine
-	}
-
-	// Analizar correlaciones
-	type correlation struct {
-		MoodScore    float64
-		EnergyLevel  float64
-		AnxietyLevel float64
-		StressLevel  float64
-		SleepHours   float64
+	caffeineByDate, err := r.dailyCaffeineTotals(stats.StartDate, stats.EndDate)
+	if err != nil {
+		return stats, err
 	}
 
-	// Dividir los datos en grupos según el consumo de cafeína
-	lowCaffeine := correlation{}
-	mediumCaffeine := correlation{}
-	highCaffeine := correlation{}
-
-	var lowCount, mediumCount, highCount int
-	var lowSum, mediumSum, highSum float64
-
 	// Contar días con cafeína
-	daysWithCaffeine := 0
 	for _, amount := range caffeineByDate {
 		if amount > 0 {
-			daysWithCaffeine++
+			stats.CaffeineDays++
 		}
 	}
+	stats.MoodDays = len(moodDays)
 
-	// Si no hay suficientes datos, devolver mensaje
-	if daysWithCaffeine < 10 || len(moodEntries) < 10 {
-		return map[string]interface{}{
-			"message":       "No hay suficientes datos para analizar correlaciones. Se necesitan al menos 10 días con registros de cafeína y estado de ánimo.",
-			"caffeine_days": daysWithCaffeine,
-			"mood_days":     len(moodEntries),
-		}, nil
+	// Si no hay suficientes datos, devolver solo los recuentos
+	if stats.CaffeineDays < models.MinCorrelationDays || stats.MoodDays < models.MinCorrelationDays {
+		stats.Message = fmt.Sprintf("No hay suficientes datos para analizar correlaciones. Se necesitan al menos %d días con registros de cafeína y estado de ánimo.", models.MinCorrelationDays)
+		return stats, nil
 	}
 
+	stats.HasData = true
+	stats.Correlations = caffeineMoodCorrelations(moodByDate, caffeineByDate)
+
 	// Calcular tercios para clasificar el consumo
 	var allCaffeine []float64
 	for _, amount := range caffeineByDate {
@@ -450,125 +394,164 @@ func (r *SQLiteRepo) GetCorrelationStats() (map[string]interface{}, error) {
 
 	sort.Float64s(allCaffeine)
 
-	var lowThreshold, highThreshold float64
 	if len(allCaffeine) >= 3 {
-		lowThreshold = allCaffeine[len(allCaffeine)/3]
-		highThreshold = allCaffeine[2*len(allCaffeine)/3]
+		stats.ThresholdLow = allCaffeine[len(allCaffeine)/3]
+		stats.ThresholdHigh = allCaffeine[2*len(allCaffeine)/3]
 	} else if len(allCaffeine) > 0 {
 		// Si hay pocos datos, usar valores arbitrarios
-		lowThreshold = allCaffeine[0] * 0.5
-		highThreshold = allCaffeine[0] * 1.5
+		stats.ThresholdLow = allCaffeine[0] * 0.5
+		stats.ThresholdHigh = allCaffeine[0] * 1.5
 	}
 
 	// Clasificar y acumular datos
-	for dateStr, entry := range moodByDate {
+	for dateStr, day := range moodByDate {
 		caffeine, exists := caffeineByDate[dateStr]
 		if !exists || caffeine == 0 {
 			continue // No hay datos de cafeína para este día
 		}
 
-		if caffeine <= lowThreshold {
-			lowCaffeine.MoodScore += float64(entry.MoodScore)
-			lowCaffeine.EnergyLevel += float64(entry.EnergyLevel)
-			lowCaffeine.AnxietyLevel += float64(entry.AnxietyLevel)
-			lowCaffeine.StressLevel += float64(entry.StressLevel)
-			lowCaffeine.SleepHours += entry.SleepHours
-			lowCount++
-			lowSum += caffeine
-		} else if caffeine <= highThreshold {
-			mediumCaffeine.MoodScore += float64(entry.MoodScore)
-			mediumCaffeine.EnergyLevel += float64(entry.EnergyLevel)
-			mediumCaffeine.AnxietyLevel += float64(entry.AnxietyLevel)
-			mediumCaffeine.StressLevel += float64(entry.StressLevel)
-			mediumCaffeine.SleepHours += entry.SleepHours
-			mediumCount++
-			mediumSum += caffeine
-		} else {
-			highCaffeine.MoodScore += float64(entry.MoodScore)
-			highCaffeine.EnergyLevel += float64(entry.EnergyLevel)
-			highCaffeine.AnxietyLevel += float64(entry.AnxietyLevel)
-			highCaffeine.StressLevel += float64(entry.StressLevel)
-			highCaffeine.SleepHours += entry.SleepHours
-			highCount++
-			highSum += caffeine
+		switch {
+		case caffeine <= stats.ThresholdLow:
+			addToCaffeineGroup(&stats.LowCaffeine, day, caffeine)
+		case caffeine <= stats.ThresholdHigh:
+			addToCaffeineGroup(&stats.MediumCaffeine, day, caffeine)
+		default:
+			addToCaffeineGroup(&stats.HighCaffeine, day, caffeine)
 		}
 	}
 
 	// Calcular promedios
-	if lowCount > 0 {
-		lowCaffeine.MoodScore /= float64(lowCount)
-		lowCaffeine.EnergyLevel /= float64(lowCount)
-		lowCaffeine.AnxietyLevel /= float64(lowCount)
-		lowCaffeine.StressLevel /= float64(lowCount)
-		lowCaffeine.SleepHours /= float64(lowCount)
+	averageCaffeineGroup(&stats.LowCaffeine)
+	averageCaffeineGroup(&stats.MediumCaffeine)
+	averageCaffeineGroup(&stats.HighCaffeine)
+
+	return stats, nil
+}
+
+// addToCaffeineGroup acumula un día en un tercil de consumo
+func addToCaffeineGroup(group *models.CaffeineGroupStats, day models.DailyMood, caffeine float64) {
+	group.Count++
+	group.AvgCaffeine += caffeine
+	group.AvgMoodScore += day.MoodScore.Mean
+	group.AvgEnergyLevel += meanOf(day.EnergyLevel)
+	group.AvgAnxietyLevel += meanOf(day.AnxietyLevel)
+	group.AvgStressLevel += meanOf(day.StressLevel)
+	group.AvgSleepHours += day.SleepHours
+}
+
+// averageCaffeineGroup convierte las sumas acumuladas de un tercil en promedios
+func averageCaffeineGroup(group *models.CaffeineGroupStats) {
+	if group.Count == 0 {
+		return
 	}
 
-	if mediumCount > 0 {
-		mediumCaffeine.MoodScore /= float64(mediumCount)
-		mediumCaffeine.EnergyLevel /= float64(mediumCount)
-		mediumCaffeine.AnxietyLevel /= float64(mediumCount)
-		mediumCaffeine.StressLevel /= float64(mediumCount)
-		mediumCaffeine.SleepHours /= float64(mediumCount)
+	n := float64(group.Count)
+	group.AvgCaffeine /= n
+	group.AvgMoodScore /= n
+	group.AvgEnergyLevel /= n
+	group.AvgAnxietyLevel /= n
+	group.AvgStressLevel /= n
+	group.AvgSleepHours /= n
+}
+
+// rangeBounds convierte un período resuelto en el primer y el último día, en la zona del usuario
+func (r *SQLiteRepo) rangeBounds(period models.DateRange) (time.Time, time.Time, error) {
+	start, err := time.ParseInLocation("2006-01-02", period.StartDate, r.Location())
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("fecha de inicio inválida: %w", err)
 	}
 
-	if highCount > 0 {
-		highCaffeine.MoodScore /= float64(highCount)
-		highCaffeine.EnergyLevel /= float64(highCount)
-		highCaffeine.AnxietyLevel /= float64(highCount)
-		highCaffeine.StressLevel /= float64(highCount)
-		highCaffeine.SleepHours /= float64(highCount)
+	end, err := time.ParseInLocation("2006-01-02", period.EndDate, r.Location())
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("fecha de fin inválida: %w", err)
 	}
 
-	// Construir resultado
-	lowCaffeineAvg := 0.0
-	if lowCount > 0 {
-		lowCaffeineAvg = lowSum / float64(lowCount)
-	}
-
-	mediumCaffeineAvg := 0.0
-	if mediumCount > 0 {
-		mediumCaffeineAvg = mediumSum / float64(mediumCount)
-	}
-
-	highCaffeineAvg := 0.0
-	if highCount > 0 {
-		highCaffeineAvg = highSum / float64(highCount)
-	}
-
-	result := map[string]interface{}{
-		"period":         "últimos 90 días",
-		"start_date":     startDateStr,
-		"end_date":       endDateStr,
-		"threshold_low":  lowThreshold,
-		"threshold_high": highThreshold,
-		"low_caffeine": map[string]interface{}{
-			"count":             lowCount,
-			"avg_caffeine":      lowCaffeineAvg,
-			"avg_mood_score":    lowCaffeine.MoodScore,
-			"avg_energy_level":  lowCaffeine.EnergyLevel,
-			"avg_anxiety_level": lowCaffeine.AnxietyLevel,
-			"avg_stress_level":  lowCaffeine.StressLevel,
-			"avg_sleep_hours":   lowCaffeine.SleepHours,
-		},
-		"medium_caffeine": map[string]interface{}{
-			"count":             mediumCount,
-			"avg_caffeine":      mediumCaffeineAvg,
-			"avg_mood_score":    mediumCaffeine.MoodScore,
-			"avg_energy_level":  mediumCaffeine.EnergyLevel,
-			"avg_anxiety_level": mediumCaffeine.AnxietyLevel,
-			"avg_stress_level":  mediumCaffeine.StressLevel,
-			"avg_sleep_hours":   mediumCaffeine.SleepHours,
-		},
-		"high_caffeine": map[string]interface{}{
-			"count":             highCount,
-			"avg_caffeine":      highCaffeineAvg,
-			"avg_mood_score":    highCaffeine.MoodScore,
-			"avg_energy_level":  highCaffeine.EnergyLevel,
-			"avg_anxiety_level": highCaffeine.AnxietyLevel,
-			"avg_stress_level":  highCaffeine.StressLevel,
-			"avg_sleep_hours":   highCaffeine.SleepHours,
-		},
-	}
-
-	return result, nil
+	return start, end, nil
+}
+
+// rangeDays devuelve el número de días de un período resuelto, ambos extremos incluidos
+func rangeDays(period models.DateRange) int {
+	start, err1 := time.Parse("2006-01-02", period.StartDate)
+	end, err2 := time.Parse("2006-01-02", period.EndDate)
+	if err1 != nil || err2 != nil || end.Before(start) {
+		return 0
+	}
+
+	return int(end.Sub(start).Hours()/24) + 1
+}
+
+// dailyCaffeineTotals obtiene el total de cafeína de cada día con consumos en el rango
+func (r *SQLiteRepo) dailyCaffeineTotals(startDate, endDate string) (map[string]float64, error) {
+	rows, err := r.db.Query(`
+		SELECT local_date, SUM(total_caffeine)
+		FROM caffeine_intake
+		WHERE local_date >= DATE(?) AND local_date <= DATE(?)
+		GROUP BY local_date
+	`, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener consumo diario de cafeína: %w", err)
+	}
+	defer rows.Close()
+
+	totals := make(map[string]float64)
+	for rows.Next() {
+		var date string
+		var total float64
+		if err := rows.Scan(&date, &total); err != nil {
+			return nil, fmt.Errorf("error al escanear consumo diario de cafeína: %w", err)
+		}
+		totals[date] = total
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar consumo diario de cafeína: %w", err)
+	}
+
+	return totals, nil
+}
+
+// moodDimension es una dimensión del estado de ánimo que se analiza, con el valor diario que
+// se usa (la media de los registros del día). Las opcionales valen 0 cuando no se registraron.
+type moodDimension struct {
+	name     string
+	value    func(models.DailyMood) float64
+	optional bool
+}
+
+// moodDimensions son las dimensiones del estado de ánimo que se analizan
+var moodDimensions = []moodDimension{
+	{"mood_score", func(d models.DailyMood) float64 { return d.MoodScore.Mean }, false},
+	{"energy_level", func(d models.DailyMood) float64 { return meanOf(d.EnergyLevel) }, true},
+	{"anxiety_level", func(d models.DailyMood) float64 { return meanOf(d.AnxietyLevel) }, true},
+	{"stress_level", func(d models.DailyMood) float64 { return meanOf(d.StressLevel) }, true},
+	{"sleep_hours", func(d models.DailyMood) float64 { return d.SleepHours }, true},
+}
+
+// caffeineMoodCorrelations correlaciona la cafeína diaria con cada dimensión del estado de
+// ánimo. Se usan todos los días con registro de ánimo (sin consumo cuenta como 0 mg); las
+// dimensiones opcionales sin valor (0) se excluyen de su serie.
+func caffeineMoodCorrelations(moodByDate map[string]models.DailyMood, caffeineByDate map[string]float64) []models.CorrelationResult {
+	// Orden determinista de los días
+	dates := make([]string, 0, len(moodByDate))
+	for date := range moodByDate {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	results := make([]models.CorrelationResult, 0, len(moodDimensions))
+	for _, dimension := range moodDimensions {
+		var caffeine, values []float64
+		for _, date := range dates {
+			value := dimension.value(moodByDate[date])
+			if dimension.optional && value == 0 {
+				continue
+			}
+			caffeine = append(caffeine, caffeineByDate[date])
+			values = append(values, value)
+		}
+
+		results = append(results, correlate(dimension.name, caffeine, values))
+	}
+
+	return results
 }
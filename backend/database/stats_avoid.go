@@ -0,0 +1,244 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+// ==================== HÁBITOS A EVITAR ====================
+
+// statsHabitLogs devuelve los registros con los que se evalúa un hábito en el rango. Los
+// hábitos a evitar no tienen registros: se genera uno por cada día desde que se sigue el
+// hábito hasta hoy, cumplido si no hubo recaídas y con el número de recaídas como recuento.
+// Así las rachas, el cumplimiento y las correlaciones tratan igual ambos tipos de hábito. En los
+// hábitos de recuento y cuantitativos, cada registro se evalúa con la meta vigente en su fecha.
+func (r *SQLiteRepo) statsHabitLogs(habit models.Habit, startDate, endDate string) ([]models.HabitLog, error) {
+	if !habit.Avoid {
+		logs, err := r.GetHabitLogs(habit.ID, startDate, endDate)
+		if err != nil {
+			return nil, err
+		}
+
+		versions, err := r.habitGoalVersions(habit)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, habitLog := range logs {
+			version, _ := goalVersionAt(versions, habitLog.Date.Format("2006-01-02"))
+			if met, decided := habit.WithGoal(version).MeetsGoal(habitLog.Count, habitLog.Value); decided {
+				logs[i].Completed = met
+			}
+		}
+
+		return logs, nil
+	}
+
+	from, to, err := r.avoidRange(habit, startDate, endDate)
+	if err != nil || from > to {
+		return nil, err
+	}
+
+	slips, err := r.GetHabitSlips(habit.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	slipsByDay := make(map[string]int)
+	for _, slip := range slips {
+		slipsByDay[slip.Date]++
+	}
+
+	first, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return nil, err
+	}
+	last, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return nil, err
+	}
+
+	// Igual que GetHabitLogs, de la fecha más reciente a la más antigua
+	var logs []models.HabitLog
+	for day := last; !day.Before(first); day = day.AddDate(0, 0, -1) {
+		count := slipsByDay[day.Format("2006-01-02")]
+		logs = append(logs, models.HabitLog{
+			HabitID:   habit.ID,
+			Date:      day,
+			Completed: count == 0,
+			Count:     count,
+			Value:     float64(count),
+		})
+	}
+
+	return logs, nil
+}
+
+// avoidTrackingStart devuelve el primer día en que se sigue un hábito a evitar: el de su
+// creación o el de su primera recaída, si es anterior
+func (r *SQLiteRepo) avoidTrackingStart(habit models.Habit) (string, error) {
+	start := r.DayOf(habit.CreatedAt)
+
+	var firstSlip sql.NullString
+	err := r.db.QueryRow("SELECT MIN(local_date) FROM habit_slips WHERE habit_id = ?", habit.ID).Scan(&firstSlip)
+	if err != nil {
+		return "", fmt.Errorf("error al obtener la primera recaída del hábito: %w", err)
+	}
+	if firstSlip.Valid && firstSlip.String < start {
+		start = firstSlip.String
+	}
+
+	return start, nil
+}
+
+// avoidRange recorta el rango a los días en que se sigue el hábito, sin pasar de hoy
+func (r *SQLiteRepo) avoidRange(habit models.Habit, startDate, endDate string) (string, string, error) {
+	trackingStart, err := r.avoidTrackingStart(habit)
+	if err != nil {
+		return "", "", err
+	}
+
+	if trackingStart > startDate {
+		startDate = trackingStart
+	}
+	if today := r.Today(); endDate > today {
+		endDate = today
+	}
+
+	return startDate, endDate, nil
+}
+
+// lastPeriodDecided indica si el último período ya está decidido aunque siga en curso: en un
+// hábito diario a evitar, una recaída hace fallar el día en cuanto ocurre
+func lastPeriodDecided(habit models.Habit, unit string) bool {
+	return habit.Avoid && unit == "day"
+}
+
+// lastHabitSlip obtiene la recaída más reciente de un hábito hasta la fecha indicada, o nil
+func (r *SQLiteRepo) lastHabitSlip(habitID int, endDate string) (*models.HabitSlip, error) {
+	query := `
+		SELECT id, habit_id, timestamp, local_date, COALESCE(notes, ''), created_at
+		FROM habit_slips
+		WHERE habit_id = ? AND local_date <= ?
+		ORDER BY timestamp DESC
+		LIMIT 1
+	`
+
+	slip, err := r.scanHabitSlip(r.db.QueryRow(query, habitID, endDate))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener la última recaída: %w", err)
+	}
+
+	return &slip, nil
+}
+
+// fillSlipStats completa las estadísticas de un hábito a evitar: recaídas y días limpios del
+// período, días desde la última recaída y frecuencia semanal de recaídas con su tendencia. Los
+// días justificados sin recaídas no cuentan como limpios: ya se cuentan en ExcusedDays.
+func (r *SQLiteRepo) fillSlipStats(stats *models.HabitStats, habit models.Habit, period models.DateRange) error {
+	stats.Avoid = true
+
+	today := r.Today()
+	last, err := r.lastHabitSlip(habit.ID, today)
+	if err != nil {
+		return err
+	}
+	if last != nil {
+		stats.LastSlipAt = &last.Timestamp
+		days := rangeDays(models.DateRange{StartDate: last.Date, EndDate: today}) - 1
+		stats.DaysSinceLastSlip = &days
+	}
+
+	from, to, err := r.avoidRange(habit, period.StartDate, period.EndDate)
+	if err != nil || from > to {
+		return err
+	}
+
+	slips, err := r.GetHabitSlips(habit.ID, from, to)
+	if err != nil {
+		return fmt.Errorf("error al obtener recaídas del hábito: %w", err)
+	}
+
+	slipsByDay := make(map[string]int)
+	for _, slip := range slips {
+		slipsByDay[slip.Date]++
+	}
+
+	excused, err := r.excusedDates(habit.ID, from, to)
+	if err != nil {
+		return fmt.Errorf("error al obtener días justificados del hábito: %w", err)
+	}
+	excusedClean := 0
+	for date := range excused {
+		if slipsByDay[date] == 0 {
+			excusedClean++
+		}
+	}
+
+	stats.TotalSlips = len(slips)
+	stats.SlipDays = len(slipsByDay)
+	stats.CleanDays = rangeDays(models.DateRange{StartDate: from, EndDate: to}) - stats.SlipDays - excusedClean
+	stats.SlipTrend = slipTrend(slipsByDay, from, to)
+	stats.SlipTrendSlope = trendSlope(stats.SlipTrend)
+
+	return nil
+}
+
+// slipTrend agrupa las recaídas por semanas ISO entre from y to (ambos incluidos)
+func slipTrend(slipsByDay map[string]int, from, to string) []models.SlipFrequency {
+	first, err1 := time.Parse("2006-01-02", from)
+	last, err2 := time.Parse("2006-01-02", to)
+	if err1 != nil || err2 != nil {
+		return nil
+	}
+
+	var trend []models.SlipFrequency
+	for week := periodStart(first, "week"); !week.After(last); week = nextPeriod(week, "week") {
+		frequency := models.SlipFrequency{
+			Period:    periodKey(week, "week"),
+			StartDate: week.Format("2006-01-02"),
+		}
+
+		for day := week; day.Before(nextPeriod(week, "week")); day = day.AddDate(0, 0, 1) {
+			if day.Before(first) || day.After(last) {
+				continue
+			}
+			frequency.Days++
+			if count := slipsByDay[day.Format("2006-01-02")]; count > 0 {
+				frequency.Slips += count
+				frequency.SlipDays++
+			}
+		}
+
+		frequency.Rate = float64(frequency.Slips) * 7 / float64(frequency.Days)
+		trend = append(trend, frequency)
+	}
+
+	return trend
+}
+
+// trendSlope calcula la pendiente por mínimos cuadrados de las recaídas por 7 días a lo largo
+// de las semanas: cuánto aumentan (o disminuyen, si es negativa) de una semana a la siguiente
+func trendSlope(trend []models.SlipFrequency) float64 {
+	n := float64(len(trend))
+	if n < 2 {
+		return 0
+	}
+
+	var sumX, sumY, sumXY, sumXX float64
+	for i, week := range trend {
+		x := float64(i)
+		sumX += x
+		sumY += week.Rate
+		sumXY += x * week.Rate
+		sumXX += x * x
+	}
+
+	return (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+}
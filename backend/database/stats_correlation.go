@@ -0,0 +1,224 @@
+package database
+
+import (
+	"math"
+	"sort"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+// ==================== CORRELACIONES ====================
+
+// minCorrelationSamples es el número mínimo de pares para calcular un coeficiente
+const minCorrelationSamples = 3
+
+// correlate calcula los coeficientes de Pearson y Spearman entre dos series con sus
+// valores p bilaterales. Con menos de minCorrelationSamples pares o varianza nula, los
+// coeficientes quedan a nil.
+func correlate(name string, x, y []float64) models.CorrelationResult {
+	result := models.CorrelationResult{
+		Dimension:  name,
+		SampleSize: len(x),
+	}
+
+	if len(x) < minCorrelationSamples || len(x) != len(y) {
+		return result
+	}
+
+	if r, ok := pearson(x, y); ok {
+		p := correlationPValue(r, len(x))
+		result.Pearson = &r
+		result.PearsonP = &p
+	}
+
+	if rho, ok := pearson(ranks(x), ranks(y)); ok {
+		p := correlationPValue(rho, len(x))
+		result.Spearman = &rho
+		result.SpearmanP = &p
+	}
+
+	return result
+}
+
+// pearson calcula el coeficiente de correlación de Pearson. Devuelve false si alguna
+// de las series no tiene varianza.
+func pearson(x, y []float64) (float64, bool) {
+	n := float64(len(x))
+
+	var meanX, meanY float64
+	for i := range x {
+		meanX += x[i]
+		meanY += y[i]
+	}
+	meanX /= n
+	meanY /= n
+
+	var cov, varX, varY float64
+	for i := range x {
+		dx := x[i] - meanX
+		dy := y[i] - meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+
+	if varX == 0 || varY == 0 {
+		return 0, false
+	}
+
+	r := cov / math.Sqrt(varX*varY)
+
+	// Acotar errores de redondeo
+	return math.Max(-1, math.Min(1, r)), true
+}
+
+// ranks devuelve el rango de cada valor (1 = menor), promediando los empates
+func ranks(values []float64) []float64 {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return values[order[a]] < values[order[b]]
+	})
+
+	result := make([]float64, len(values))
+	for i := 0; i < len(order); {
+		j := i
+		for j+1 < len(order) && values[order[j+1]] == values[order[i]] {
+			j++
+		}
+
+		// Las posiciones i..j están empatadas: rango medio
+		rank := float64(i+j)/2 + 1
+		for k := i; k <= j; k++ {
+			result[order[k]] = rank
+		}
+		i = j + 1
+	}
+
+	return result
+}
+
+// correlationPValue calcula el valor p bilateral de un coeficiente de correlación con
+// n pares, usando el estadístico t de Student con n-2 grados de libertad
+func correlationPValue(r float64, n int) float64 {
+	df := float64(n - 2)
+	if df <= 0 {
+		return 1
+	}
+
+	if math.Abs(r) >= 1 {
+		return 0
+	}
+
+	t := r * math.Sqrt(df/(1-r*r))
+
+	return studentTwoSidedP(t, df)
+}
+
+// studentTwoSidedP calcula P(|T| > |t|) para una t de Student con df grados de libertad
+func studentTwoSidedP(t, df float64) float64 {
+	// P(|T| > |t|) = I_x(df/2, 1/2) con x = df / (df + t²)
+	return regularizedIncompleteBeta(df/(df+t*t), df/2, 0.5)
+}
+
+// studentQuantile devuelve el valor t tal que P(|T| > t) = alpha, por bisección
+func studentQuantile(alpha, df float64) float64 {
+	low, high := 0.0, 1.0
+	for studentTwoSidedP(high, df) > alpha {
+		high *= 2
+	}
+
+	for i := 0; i < 100; i++ {
+		mid := (low + high) / 2
+		if studentTwoSidedP(mid, df) > alpha {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+
+	return (low + high) / 2
+}
+
+// regularizedIncompleteBeta calcula I_x(a, b) mediante su fracción continua
+func regularizedIncompleteBeta(x, a, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+
+	lgammaA, _ := math.Lgamma(a)
+	lgammaB, _ := math.Lgamma(b)
+	lgammaAB, _ := math.Lgamma(a + b)
+	front := math.Exp(lgammaAB - lgammaA - lgammaB + a*math.Log(x) + b*math.Log(1-x))
+
+	// La fracción continua converge rápido para x < (a+1)/(a+b+2); si no, usar la simetría
+	if x < (a+1)/(a+b+2) {
+		return front * betaContinuedFraction(x, a, b) / a
+	}
+	return 1 - front*betaContinuedFraction(1-x, b, a)/b
+}
+
+// betaContinuedFraction evalúa la fracción continua de la función beta incompleta
+// con el método de Lentz modificado
+func betaContinuedFraction(x, a, b float64) float64 {
+	const (
+		maxIterations = 200
+		epsilon       = 1e-14
+		tiny          = 1e-300
+	)
+
+	qab := a + b
+	qap := a + 1
+	qam := a - 1
+
+	c := 1.0
+	d := 1 - qab*x/qap
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+
+	for m := 1; m <= maxIterations; m++ {
+		fm := float64(m)
+		m2 := 2 * fm
+
+		// Paso par
+		aa := fm * (b - fm) * x / ((qam + m2) * (a + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+
+		// Paso impar
+		aa = -(a + fm) * (qab + fm) * x / ((a + m2) * (qap + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+
+		if math.Abs(delta-1) < epsilon {
+			break
+		}
+	}
+
+	return h
+}
@@ -0,0 +1,150 @@
+package database
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPearson(t *testing.T) {
+	tests := []struct {
+		name   string
+		x, y   []float64
+		want   float64
+		wantOK bool
+	}{
+		{name: "relación lineal creciente", x: []float64{1, 2, 3, 4, 5}, y: []float64{2, 4, 6, 8, 10}, want: 1, wantOK: true},
+		{name: "relación lineal decreciente", x: []float64{1, 2, 3, 4, 5}, y: []float64{10, 8, 6, 4, 2}, want: -1, wantOK: true},
+		{name: "relación parcial", x: []float64{1, 2, 3, 4, 5}, y: []float64{2, 1, 4, 3, 5}, want: 0.8, wantOK: true},
+		{name: "sin relación", x: []float64{1, 2, 3, 4}, y: []float64{1, 2, 2, 1}, want: 0, wantOK: true},
+		{name: "serie constante", x: []float64{1, 2, 3}, y: []float64{4, 4, 4}, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := pearson(tt.x, tt.y)
+			if ok != tt.wantOK || math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("pearson() = %.6f, %v; se esperaba %.6f, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRanks(t *testing.T) {
+	tests := []struct {
+		values []float64
+		want   []float64
+	}{
+		{values: []float64{30, 10, 20}, want: []float64{3, 1, 2}},
+		{values: []float64{10, 20, 20, 5}, want: []float64{2, 3.5, 3.5, 1}},
+		{values: []float64{3, 3, 3}, want: []float64{2, 2, 2}},
+		{values: []float64{}, want: []float64{}},
+	}
+
+	for _, tt := range tests {
+		got := ranks(tt.values)
+		if len(got) != len(tt.want) {
+			t.Fatalf("ranks(%v) = %v, se esperaba %v", tt.values, got, tt.want)
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("ranks(%v) = %v, se esperaba %v", tt.values, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestStudentTwoSidedP(t *testing.T) {
+	// Los valores de referencia con 1 y 2 grados de libertad tienen forma cerrada; el resto
+	// se ha obtenido integrando numéricamente la densidad de la t de Student
+	tests := []struct {
+		t, df float64
+		want  float64
+	}{
+		{t: 0, df: 5, want: 1},
+		{t: 1, df: 1, want: 0.5},                     // 1 - 2/π·atan(1)
+		{t: math.Sqrt(3), df: 1, want: 1.0 / 3},      // 1 - 2/π·atan(√3)
+		{t: 2, df: 2, want: 1 - 2/math.Sqrt(6)},      // 1 - t/√(2+t²)
+		{t: -2, df: 2, want: 1 - 2/math.Sqrt(6)},     // simétrica
+		{t: 2, df: 10, want: 0.07338803477},          // tablas: 0.0734
+		{t: 2.309401077, df: 3, want: 0.10408803866}, // r = 0.8 con 5 pares
+	}
+
+	for _, tt := range tests {
+		if got := studentTwoSidedP(tt.t, tt.df); math.Abs(got-tt.want) > 1e-8 {
+			t.Errorf("studentTwoSidedP(%.4f, %.4f) = %.10f, se esperaba %.10f", tt.t, tt.df, got, tt.want)
+		}
+	}
+}
+
+func TestStudentQuantile(t *testing.T) {
+	tests := []struct {
+		alpha, df float64
+		want      float64
+	}{
+		{alpha: 0.05, df: 1, want: 12.706204736},
+		{alpha: 0.05, df: 2, want: 4.302652730},
+		{alpha: 0.05, df: 10, want: 2.228138852},
+		{alpha: 0.01, df: 10, want: 3.169272673},
+	}
+
+	for _, tt := range tests {
+		if got := studentQuantile(tt.alpha, tt.df); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("studentQuantile(%.2f, %.0f) = %.9f, se esperaba %.9f", tt.alpha, tt.df, got, tt.want)
+		}
+	}
+}
+
+func TestCorrelate(t *testing.T) {
+	ptr := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name                string
+		x, y                []float64
+		pearson, pearsonP   *float64
+		spearman, spearmanP *float64
+	}{
+		{
+			// Con 4 pares el valor p es exactamente 1 - |r|
+			name: "cuatro pares", x: []float64{1, 2, 3, 4}, y: []float64{1, 3, 2, 4},
+			pearson: ptr(0.8), pearsonP: ptr(0.2), spearman: ptr(0.8), spearmanP: ptr(0.2),
+		},
+		{
+			// Monótona pero no lineal: Spearman es perfecto y Pearson no
+			name: "relación monótona", x: []float64{1, 2, 3, 4}, y: []float64{1, 8, 27, 64},
+			pearson: ptr(104 / math.Sqrt(5*2390)), pearsonP: ptr(1 - 104/math.Sqrt(5*2390)), spearman: ptr(1), spearmanP: ptr(0),
+		},
+		{
+			// Con 3 pares (1 grado de libertad) p = 1 - 2/π·atan(t)
+			name: "tres pares", x: []float64{1, 2, 3}, y: []float64{1, 3, 2},
+			pearson: ptr(0.5), pearsonP: ptr(2.0 / 3), spearman: ptr(0.5), spearmanP: ptr(2.0 / 3),
+		},
+		{name: "menos del mínimo de pares", x: []float64{1, 2}, y: []float64{2, 1}},
+		{name: "una serie constante", x: []float64{1, 2, 3, 4}, y: []float64{5, 5, 5, 5}},
+	}
+
+	check := func(t *testing.T, name string, got, want *float64) {
+		t.Helper()
+		switch {
+		case want == nil && got != nil:
+			t.Errorf("%s = %.6f, se esperaba nil", name, *got)
+		case want != nil && got == nil:
+			t.Errorf("%s = nil, se esperaba %.6f", name, *want)
+		case want != nil && math.Abs(*got-*want) > 1e-9:
+			t.Errorf("%s = %.10f, se esperaba %.10f", name, *got, *want)
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := correlate("mood_score", tt.x, tt.y)
+			if got.SampleSize != len(tt.x) {
+				t.Errorf("SampleSize = %d, se esperaba %d", got.SampleSize, len(tt.x))
+			}
+			check(t, "Pearson", got.Pearson, tt.pearson)
+			check(t, "PearsonP", got.PearsonP, tt.pearsonP)
+			check(t, "Spearman", got.Spearman, tt.spearman)
+			check(t, "SpearmanP", got.SpearmanP, tt.spearmanP)
+		})
+	}
+}
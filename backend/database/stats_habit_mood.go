@@ -0,0 +1,204 @@
+package database
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+	"github.com/kubaliski/habit-tracker/backend/schedule"
+)
+
+// ==================== HÁBITOS Y ESTADO DE ÁNIMO ====================
+
+// minGroupSamples es el número mínimo de días en cada grupo para comparar medias
+const minGroupSamples = 3
+
+// GetHabitMoodCorrelations compara, para cada hábito activo, el estado de ánimo de los
+// días en que se completó con el de los días en que no, dentro del período. Los hábitos se
+// ordenan de mayor a menor impacto.
+func (r *SQLiteRepo) GetHabitMoodCorrelations(period models.DateRange) (models.HabitMoodAnalysis, error) {
+	startDateStr, endDateStr := period.StartDate, period.EndDate
+
+	analysis := models.HabitMoodAnalysis{
+		WindowDays: rangeDays(period),
+		StartDate:  startDateStr,
+		EndDate:    endDateStr,
+		Habits:     []models.HabitMoodCorrelation{},
+	}
+
+	moodDays, err := r.GetDailyMoods(startDateStr, endDateStr)
+	if err != nil {
+		return analysis, fmt.Errorf("error al obtener registros de estado de ánimo: %w", err)
+	}
+
+	habits, err := r.GetAllHabits()
+	if err != nil {
+		return analysis, fmt.Errorf("error al obtener hábitos: %w", err)
+	}
+
+	for _, habit := range habits {
+		if !habit.Active {
+			continue
+		}
+
+		logs, err := r.statsHabitLogs(habit, startDateStr, endDateStr)
+		if err != nil {
+			return analysis, fmt.Errorf("error al obtener registros del hábito %d: %w", habit.ID, err)
+		}
+
+		versions, err := r.habitGoalVersions(habit)
+		if err != nil {
+			return analysis, fmt.Errorf("error al obtener el historial de metas del hábito %d: %w", habit.ID, err)
+		}
+
+		analysis.Habits = append(analysis.Habits, habitMoodCorrelation(habit, logs, versions, moodDays))
+	}
+
+	sort.SliceStable(analysis.Habits, func(i, j int) bool {
+		return analysis.Habits[i].Impact > analysis.Habits[j].Impact
+	})
+
+	return analysis, nil
+}
+
+// habitMoodCorrelation divide los días con registro de ánimo según se completara o no el
+// hábito y compara cada dimensión. Solo cuentan los días desde que existe el hábito (o
+// desde su primer registro, si es anterior) y en que tocaba según el calendario vigente ese día.
+func habitMoodCorrelation(habit models.Habit, logs []models.HabitLog, versions []models.HabitGoalVersion, moodDays []models.DailyMood) models.HabitMoodCorrelation {
+	firstDate := habit.CreatedAt.Format("2006-01-02")
+	completed := make(map[string]bool)
+	for _, habitLog := range logs {
+		date := habitLog.Date.Format("2006-01-02")
+		if date < firstDate {
+			firstDate = date
+		}
+		if habitLog.Completed {
+			completed[date] = true
+		}
+	}
+
+	result := models.HabitMoodCorrelation{
+		HabitID:   habit.ID,
+		HabitName: habit.Name,
+		Effects:   make([]models.HabitMoodEffect, 0, len(moodDimensions)),
+	}
+
+	for _, dimension := range moodDimensions {
+		var done, notDone []float64
+		for _, day := range moodDays {
+			date := day.Date
+			if date < firstDate {
+				continue
+			}
+			dayTime, err := time.Parse("2006-01-02", date)
+			version, _ := goalVersionAt(versions, date)
+			if err != nil || !schedule.Scheduled(version.Schedule, dayTime) {
+				continue
+			}
+
+			value := dimension.value(day)
+			if dimension.optional && value == 0 {
+				continue
+			}
+
+			if completed[date] {
+				done = append(done, value)
+			} else {
+				notDone = append(notDone, value)
+			}
+		}
+
+		effect := compareGroups(dimension.name, done, notDone)
+		if effect.Confidence != models.ConfidenceInsufficient {
+			result.Impact = math.Max(result.Impact, math.Abs(effect.EffectSize))
+		}
+		result.Effects = append(result.Effects, effect)
+	}
+
+	return result
+}
+
+// compareGroups compara las medias de dos grupos con la d de Cohen y la prueba t de Welch
+func compareGroups(name string, a, b []float64) models.HabitMoodEffect {
+	effect := models.HabitMoodEffect{
+		Dimension:        name,
+		CompletedDays:    len(a),
+		NotCompletedDays: len(b),
+		Confidence:       models.ConfidenceInsufficient,
+	}
+
+	meanA, varA := meanVariance(a)
+	meanB, varB := meanVariance(b)
+	effect.CompletedMean = meanA
+	effect.NotCompletedMean = meanB
+
+	if len(a) < minGroupSamples || len(b) < minGroupSamples {
+		return effect
+	}
+
+	nA, nB := float64(len(a)), float64(len(b))
+	diff := meanA - meanB
+	effect.MeanDifference = diff
+
+	// d de Cohen con la desviación típica combinada
+	pooled := math.Sqrt(((nA-1)*varA + (nB-1)*varB) / (nA + nB - 2))
+	if pooled > 0 {
+		effect.EffectSize = diff / pooled
+	}
+
+	// Prueba t de Welch con los grados de libertad de Welch-Satterthwaite
+	seA, seB := varA/nA, varB/nB
+	se := math.Sqrt(seA + seB)
+	if se == 0 {
+		// Ambos grupos son constantes: la diferencia es exacta
+		effect.CILow, effect.CIHigh = diff, diff
+		effect.PValue = 1
+		if diff != 0 {
+			effect.PValue = 0
+		}
+	} else {
+		df := (seA + seB) * (seA + seB) / (seA*seA/(nA-1) + seB*seB/(nB-1))
+		margin := studentQuantile(0.05, df) * se
+
+		effect.CILow = diff - margin
+		effect.CIHigh = diff + margin
+		effect.PValue = studentTwoSidedP(diff/se, df)
+	}
+
+	switch {
+	case effect.PValue < 0.01:
+		effect.Confidence = models.ConfidenceHigh
+	case effect.PValue < 0.05:
+		effect.Confidence = models.ConfidenceMedium
+	default:
+		effect.Confidence = models.ConfidenceLow
+	}
+
+	return effect
+}
+
+// meanVariance calcula la media y la varianza muestral (n-1) de una serie
+func meanVariance(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	var mean float64
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	if len(values) < 2 {
+		return mean, 0
+	}
+
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+
+	return mean, variance / float64(len(values)-1)
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+func TestCompareGroups(t *testing.T) {
+	tests := []struct {
+		name           string
+		a, b           []float64
+		wantDiff       float64
+		wantEffect     float64
+		wantP          float64
+		wantCI         [2]float64
+		wantConfidence string
+	}{
+		{
+			// Medias 7 y 4, varianzas 2.5 y 1: t = 3.2863 con 5.88 grados de libertad de Welch.
+			// Referencias obtenidas integrando numéricamente la densidad de la t de Student.
+			name: "grupos de distinto tamaño y varianza", a: []float64{5, 6, 7, 8, 9}, b: []float64{3, 4, 5},
+			wantDiff: 3, wantEffect: 3 / math.Sqrt2, wantP: 0.017177850667,
+			wantCI: [2]float64{0.755411315, 5.244588685}, wantConfidence: models.ConfidenceMedium,
+		},
+		{
+			// Welch da 4 grados de libertad: el margen es t(0.975, 4)·√(2/3)
+			name: "sin diferencia", a: []float64{1, 2, 3}, b: []float64{3, 2, 1},
+			wantP: 1, wantCI: [2]float64{-2.776445105 * math.Sqrt(2.0/3), 2.776445105 * math.Sqrt(2.0/3)},
+			wantConfidence: models.ConfidenceLow,
+		},
+		{
+			name: "grupos constantes distintos", a: []float64{5, 5, 5}, b: []float64{3, 3, 3, 3},
+			wantDiff: 2, wantP: 0, wantCI: [2]float64{2, 2}, wantConfidence: models.ConfidenceHigh,
+		},
+		{
+			name: "grupos constantes iguales", a: []float64{4, 4, 4}, b: []float64{4, 4, 4},
+			wantP: 1, wantConfidence: models.ConfidenceLow,
+		},
+		{
+			name: "un grupo con menos del mínimo de días", a: []float64{8, 9}, b: []float64{1, 2, 3},
+			wantConfidence: models.ConfidenceInsufficient,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := compareGroups("mood_score", tt.a, tt.b)
+
+			if got.CompletedDays != len(tt.a) || got.NotCompletedDays != len(tt.b) {
+				t.Errorf("días = %d y %d, se esperaban %d y %d", got.CompletedDays, got.NotCompletedDays, len(tt.a), len(tt.b))
+			}
+			if got.Confidence != tt.wantConfidence {
+				t.Errorf("Confidence = %s, se esperaba %s", got.Confidence, tt.wantConfidence)
+			}
+			for _, c := range []struct {
+				field     string
+				got, want float64
+				tolerance float64
+			}{
+				{"MeanDifference", got.MeanDifference, tt.wantDiff, 1e-12},
+				{"EffectSize", got.EffectSize, tt.wantEffect, 1e-12},
+				{"PValue", got.PValue, tt.wantP, 1e-8},
+				{"CILow", got.CILow, tt.wantCI[0], 1e-5},
+				{"CIHigh", got.CIHigh, tt.wantCI[1], 1e-5},
+			} {
+				if math.Abs(c.got-c.want) > c.tolerance {
+					t.Errorf("%s = %.9f, se esperaba %.9f", c.field, c.got, c.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+	"github.com/kubaliski/habit-tracker/backend/schedule"
+)
+
+// ==================== CORRELACIONES CON DESFASE ====================
+
+// laggedInput es una variable diaria que se correlaciona con el estado de ánimo de días
+// posteriores. Las fechas anteriores a from y las de skipped no tienen dato.
+type laggedInput struct {
+	key     string
+	label   string
+	from    string
+	values  map[string]float64 // los días sin entrada valen 0
+	skipped map[string]bool
+}
+
+// GetLaggedCorrelations correlaciona la cafeína total, la cafeína a partir de cutoffHour y
+// las compleciones de cada hábito activo (los días limpios, en los hábitos a evitar) del día D
+// con el estado de ánimo del día D+desfase, para desfases de 0 a maxLag días, dentro del período
+func (r *SQLiteRepo) GetLaggedCorrelations(period models.DateRange, maxLag, cutoffHour int) (models.LaggedCorrelationMatrix, error) {
+	startDateStr, endDateStr := period.StartDate, period.EndDate
+
+	matrix := models.LaggedCorrelationMatrix{
+		WindowDays: rangeDays(period),
+		MaxLag:     maxLag,
+		CutoffHour: cutoffHour,
+		StartDate:  startDateStr,
+		EndDate:    endDateStr,
+		Rows:       []models.LaggedCorrelationRow{},
+	}
+
+	moodDays, err := r.GetDailyMoods(startDateStr, endDateStr)
+	if err != nil {
+		return matrix, fmt.Errorf("error al obtener registros de estado de ánimo: %w", err)
+	}
+
+	intakes, err := r.GetCaffeineIntakeRange(startDateStr, endDateStr)
+	if err != nil {
+		return matrix, err
+	}
+
+	total, late := caffeineByDay(intakes, r.dayClock(), cutoffHour)
+
+	inputs := []laggedInput{
+		{key: "caffeine_total", label: "Cafeína total", from: startDateStr, values: total},
+		{key: "caffeine_after_cutoff", label: fmt.Sprintf("Cafeína desde las %02d:00", cutoffHour), from: startDateStr, values: late},
+	}
+
+	habits, err := r.GetAllHabits()
+	if err != nil {
+		return matrix, fmt.Errorf("error al obtener hábitos: %w", err)
+	}
+
+	for _, habit := range habits {
+		if !habit.Active {
+			continue
+		}
+
+		logs, err := r.statsHabitLogs(habit, startDateStr, endDateStr)
+		if err != nil {
+			return matrix, fmt.Errorf("error al obtener registros del hábito %d: %w", habit.ID, err)
+		}
+
+		versions, err := r.habitGoalVersions(habit)
+		if err != nil {
+			return matrix, fmt.Errorf("error al obtener el historial de metas del hábito %d: %w", habit.ID, err)
+		}
+
+		excused, err := r.excusedDates(habit.ID, startDateStr, endDateStr)
+		if err != nil {
+			return matrix, fmt.Errorf("error al obtener días justificados del hábito %d: %w", habit.ID, err)
+		}
+
+		inputs = append(inputs, habitLaggedInput(habit, logs, versions, excused, startDateStr, endDateStr))
+	}
+
+	for _, input := range inputs {
+		for _, dimension := range moodDimensions {
+			row := models.LaggedCorrelationRow{
+				Input:      input.key,
+				InputLabel: input.label,
+				Outcome:    dimension.name,
+				Lags:       make([]models.LaggedCorrelation, 0, maxLag+1),
+			}
+
+			for lag := 0; lag <= maxLag; lag++ {
+				row.Lags = append(row.Lags, laggedCorrelation(input, dimension, moodDays, lag))
+			}
+
+			matrix.Rows = append(matrix.Rows, row)
+		}
+	}
+
+	return matrix, nil
+}
+
+// caffeineByDay suma la cafeína de cada día lógico, en total y desde cutoffHour. La hora de
+// corte se mide desde el inicio del día lógico, así que lo consumido de madrugada, antes del
+// cambio de día, cuenta como tardío; con un corte anterior al cambio de día (las 2 con cambio a
+// las 4), solo cuenta lo consumido entre el corte y el cambio.
+func caffeineByDay(intakes []models.CaffeineIntake, clock dayClock, cutoffHour int) (map[string]float64, map[string]float64) {
+	cutoff := clock.minuteOfDay(cutoffHour, 0)
+
+	total := make(map[string]float64)
+	late := make(map[string]float64)
+	for _, intake := range intakes {
+		date := clock.day(intake.Timestamp)
+		total[date] += intake.TotalCaffeine
+
+		local := intake.Timestamp.In(clock.loc)
+		if clock.minuteOfDay(local.Hour(), local.Minute()) >= cutoff {
+			late[date] += intake.TotalCaffeine
+		}
+	}
+
+	return total, late
+}
+
+// habitLaggedInput convierte los registros de un hábito en una variable diaria: 1 si se
+// completó y 0 si no. En los hábitos a evitar statsHabitLogs ya da un registro por día, completado
+// si fue limpio, así que la serie es la de días limpios. Igual que en el análisis de impacto, solo
+// cuentan los días desde que existe el hábito; además se descartan los justificados y, salvo en
+// los hábitos a evitar, los que no tocaban según la meta vigente ese día.
+func habitLaggedInput(habit models.Habit, logs []models.HabitLog, versions []models.HabitGoalVersion, excused map[string]bool, startDate, endDate string) laggedInput {
+	input := laggedInput{
+		key:     fmt.Sprintf("habit:%d", habit.ID),
+		label:   habit.Name,
+		from:    habit.CreatedAt.Format("2006-01-02"),
+		values:  make(map[string]float64),
+		skipped: make(map[string]bool),
+	}
+	for _, habitLog := range logs {
+		date := habitLog.Date.Format("2006-01-02")
+		if date < input.from {
+			input.from = date
+		}
+		if habitLog.Completed {
+			input.values[date] = 1
+		}
+	}
+	if input.from < startDate {
+		input.from = startDate
+	}
+
+	first, err1 := time.Parse("2006-01-02", input.from)
+	last, err2 := time.Parse("2006-01-02", endDate)
+	if err1 != nil || err2 != nil {
+		return input
+	}
+
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		if excused[date] {
+			input.skipped[date] = true
+			continue
+		}
+		if habit.Avoid {
+			continue
+		}
+		if version, ok := goalVersionAt(versions, date); ok && !schedule.Scheduled(version.Schedule, day) {
+			input.skipped[date] = true
+		}
+	}
+
+	return input
+}
+
+// laggedCorrelation empareja cada día con registro de ánimo con el valor de la variable lag días antes
+func laggedCorrelation(input laggedInput, dimension moodDimension, moodDays []models.DailyMood, lag int) models.LaggedCorrelation {
+	var x, y []float64
+	for _, day := range moodDays {
+		outcome := dimension.value(day)
+		if dimension.optional && outcome == 0 {
+			continue
+		}
+
+		moodDate, err := time.Parse("2006-01-02", day.Date)
+		if err != nil {
+			continue
+		}
+		date := moodDate.AddDate(0, 0, -lag).Format("2006-01-02")
+		if date < input.from || input.skipped[date] {
+			continue
+		}
+
+		x = append(x, input.values[date])
+		y = append(y, outcome)
+	}
+
+	return models.LaggedCorrelation{
+		Lag:               lag,
+		CorrelationResult: correlate(dimension.name, x, y),
+	}
+}
@@ -0,0 +1,183 @@
+package database
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+func TestHabitLaggedInput(t *testing.T) {
+	created := time.Date(2026, time.September, 7, 10, 0, 0, 0, time.UTC) // lunes
+
+	// Lunes, miércoles y viernes
+	weekdays := []models.HabitGoalVersion{{
+		EffectiveDate: "2026-09-07",
+		Frequency:     models.FrequencyDaily,
+		Goal:          1,
+		Schedule:      models.HabitSchedule{Type: models.ScheduleWeekdays, Weekdays: 1<<time.Monday | 1<<time.Wednesday | 1<<time.Friday},
+	}}
+	daily := []models.HabitGoalVersion{testVersion("2026-09-07", models.FrequencyDaily, 1)}
+
+	tests := []struct {
+		name      string
+		avoid     bool
+		versions  []models.HabitGoalVersion
+		logs      []string
+		excused   map[string]bool
+		start     string
+		wantFrom  string
+		wantDone  []string
+		wantSkips []string
+	}{
+		{
+			name:     "diario sin días descartados",
+			versions: daily,
+			logs:     []string{"2026-09-07", "2026-09-09"},
+			start:    "2026-09-01", wantFrom: "2026-09-07",
+			wantDone: []string{"2026-09-07", "2026-09-09"},
+		},
+		{
+			name:     "los días justificados se descartan",
+			versions: daily,
+			logs:     []string{"2026-09-07"},
+			excused:  testExcused(t, "2026-09-09", "2026-09-10"),
+			start:    "2026-09-07", wantFrom: "2026-09-07",
+			wantDone:  []string{"2026-09-07"},
+			wantSkips: []string{"2026-09-09", "2026-09-10"},
+		},
+		{
+			name:     "los días que no tocaban se descartan",
+			versions: weekdays,
+			logs:     []string{"2026-09-07", "2026-09-11"},
+			start:    "2026-09-07", wantFrom: "2026-09-07",
+			wantDone:  []string{"2026-09-07", "2026-09-11"},
+			wantSkips: []string{"2026-09-08", "2026-09-10", "2026-09-12", "2026-09-13"},
+		},
+		{
+			name:     "un hábito a evitar cuenta todos los días salvo los justificados",
+			avoid:    true,
+			versions: weekdays,
+			// Días limpios según statsHabitLogs
+			logs:      []string{"2026-09-07", "2026-09-08", "2026-09-10"},
+			excused:   testExcused(t, "2026-09-12", "2026-09-12"),
+			start:     "2026-09-07",
+			wantFrom:  "2026-09-07",
+			wantDone:  []string{"2026-09-07", "2026-09-08", "2026-09-10"},
+			wantSkips: []string{"2026-09-12"},
+		},
+		{
+			name:     "un registro anterior a la creación adelanta el inicio",
+			versions: daily,
+			logs:     []string{"2026-09-05"},
+			start:    "2026-09-01", wantFrom: "2026-09-05",
+			wantDone: []string{"2026-09-05"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			habit := models.Habit{ID: 1, Name: "Hábito", Avoid: tt.avoid, CreatedAt: created}
+			input := habitLaggedInput(habit, testLogs(t, tt.logs...), tt.versions, tt.excused, tt.start, "2026-09-13")
+
+			if input.from != tt.wantFrom {
+				t.Errorf("from = %s, se esperaba %s", input.from, tt.wantFrom)
+			}
+
+			var done, skipped []string
+			for date, value := range input.values {
+				if value == 1 {
+					done = append(done, date)
+				}
+			}
+			for date := range input.skipped {
+				skipped = append(skipped, date)
+			}
+			sort.Strings(done)
+			sort.Strings(skipped)
+
+			if strings.Join(done, ",") != strings.Join(tt.wantDone, ",") {
+				t.Errorf("días completados = %v, se esperaban %v", done, tt.wantDone)
+			}
+			if strings.Join(skipped, ",") != strings.Join(tt.wantSkips, ",") {
+				t.Errorf("días descartados = %v, se esperaban %v", skipped, tt.wantSkips)
+			}
+		})
+	}
+}
+
+func TestLaggedCorrelationDropsSkippedDays(t *testing.T) {
+	input := laggedInput{
+		from:    "2026-09-01",
+		values:  map[string]float64{"2026-09-01": 1, "2026-09-03": 1},
+		skipped: map[string]bool{"2026-09-02": true},
+	}
+
+	var moodDays []models.DailyMood
+	for day := 1; day <= 5; day++ {
+		date := time.Date(2026, time.September, day, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+		moodDays = append(moodDays, models.DailyMood{Date: date, MoodScore: models.MoodAggregate{Mean: float64(day)}})
+	}
+
+	tests := []struct {
+		lag  int
+		want int
+	}{
+		{lag: 0, want: 4}, // sin el 2
+		{lag: 1, want: 3}, // el 1 empareja con un día anterior al inicio y el 3 con el 2
+		{lag: 2, want: 2}, // el 1 y el 2 emparejan con días anteriores al inicio y el 4 con el 2
+	}
+
+	for _, tt := range tests {
+		got := laggedCorrelation(input, moodDimensions[0], moodDays, tt.lag)
+		if got.SampleSize != tt.want {
+			t.Errorf("laggedCorrelation(lag %d) con %d días, se esperaban %d", tt.lag, got.SampleSize, tt.want)
+		}
+	}
+}
+
+func TestCaffeineByDay(t *testing.T) {
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		t.Skipf("sin datos de zonas horarias: %v", err)
+	}
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, time.October, day, hour, minute, 0, 0, madrid)
+	}
+
+	tests := []struct {
+		name       string
+		rollover   int
+		cutoffHour int
+		intake     time.Time
+		wantDate   string
+		wantLate   bool
+	}{
+		{name: "antes del corte", rollover: 0, cutoffHour: 16, intake: at(10, 15, 59), wantDate: "2026-10-10", wantLate: false},
+		{name: "en el corte", rollover: 0, cutoffHour: 16, intake: at(10, 16, 0), wantDate: "2026-10-10", wantLate: true},
+		{name: "madrugada antes del cambio de día", rollover: 4, cutoffHour: 16, intake: at(11, 2, 30), wantDate: "2026-10-10", wantLate: true},
+		{name: "mañana tras el cambio de día", rollover: 4, cutoffHour: 16, intake: at(11, 4, 0), wantDate: "2026-10-11", wantLate: false},
+		{name: "corte de madrugada: por la tarde no es tardío", rollover: 4, cutoffHour: 2, intake: at(10, 20, 0), wantDate: "2026-10-10", wantLate: false},
+		{name: "corte de madrugada: entre el corte y el cambio", rollover: 4, cutoffHour: 2, intake: at(11, 3, 0), wantDate: "2026-10-10", wantLate: true},
+		{name: "corte de madrugada: antes del corte", rollover: 4, cutoffHour: 2, intake: at(11, 1, 0), wantDate: "2026-10-10", wantLate: false},
+		{name: "corte a medianoche sin cambio de día", rollover: 0, cutoffHour: 0, intake: at(10, 8, 0), wantDate: "2026-10-10", wantLate: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := dayClock{loc: madrid, rolloverHour: tt.rollover}
+			intakes := []models.CaffeineIntake{{Timestamp: tt.intake.UTC(), TotalCaffeine: 80}}
+
+			total, late := caffeineByDay(intakes, clock, tt.cutoffHour)
+
+			if total[tt.wantDate] != 80 {
+				t.Errorf("total = %v, se esperaban 80 mg el %s", total, tt.wantDate)
+			}
+			if gotLate := late[tt.wantDate] == 80; gotLate != tt.wantLate {
+				t.Errorf("tardío = %v, se esperaba %v", gotLate, tt.wantLate)
+			}
+		})
+	}
+}
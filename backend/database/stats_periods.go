@@ -0,0 +1,123 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+// periodResult resume el cumplimiento de un hábito medido en su propia unidad (día, semana o mes)
+type periodResult struct {
+	TotalPeriods     int
+	CompletedPeriods int
+	CompletionRate   float64
+	CurrentStreak    int
+	MaxStreak        int
+}
+
+// periodUnit devuelve la unidad en la que se mide un hábito según su frecuencia
+func periodUnit(frequency string) string {
+	switch frequency {
+	case models.FrequencyWeekly:
+		return "week"
+	case models.FrequencyMonthly:
+		return "month"
+	default:
+		return "day"
+	}
+}
+
+// periodStart devuelve el inicio del período que contiene la fecha indicada.
+// Las semanas son ISO (empiezan en lunes).
+func periodStart(t time.Time, unit string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+
+	switch unit {
+	case "week":
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case "month":
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+	default:
+		return day
+	}
+}
+
+// nextPeriod devuelve el inicio del período siguiente
+func nextPeriod(start time.Time, unit string) time.Time {
+	switch unit {
+	case "week":
+		return start.AddDate(0, 0, 7)
+	case "month":
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// periodKey devuelve una clave legible para el período (2025-01-15, 2025-W03, 2025-01)
+func periodKey(t time.Time, unit string) string {
+	switch unit {
+	case "week":
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case "month":
+		return t.Format("2006-01")
+	default:
+		return t.Format("2006-01-02")
+	}
+}
+
+// lastPeriodOpen indica si el último período del calendario sigue abierto al final del rango:
+// porque el rango termina hoy (today, el día lógico actual; el período está en curso) o
+// porque termina antes que el período. Un período que acaba antes del último día del rango
+// (el último día que tocaba, en un calendario con días libres) ya está cerrado.
+func lastPeriodOpen(slot calendarSlot, end time.Time, today string) bool {
+	endDay := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, end.Location())
+	if slot.End.Before(endDay) {
+		return false
+	}
+
+	return endDay.Format("2006-01-02") >= today || slot.End.After(endDay)
+}
+
+// computePeriodStats calcula rachas y tasa de cumplimiento sobre el calendario denso del rango
+// (start y end son el primer y el último día; today es el día lógico actual), con la meta de
+// cada versión en los días en que estuvo vigente. Un período inicial parcial no se evalúa; el
+// último período, si está en curso o el rango no lo cubre entero, solo cuenta si ya está
+// cumplido, salvo que decided indique que ya no puede cumplirse. Los días que no tocan según el
+// calendario y los justificados no cuentan ni como cumplidos ni como fallos.
+func computePeriodStats(logs []models.HabitLog, versions []models.HabitGoalVersion, excused map[string]bool, start, end time.Time, today string, decided bool) periodResult {
+	var result periodResult
+
+	slots := buildCalendar(logs, versions, excused, start, end)
+
+	// El rango no cubre entero el primer período
+	startDay := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	if len(slots) > 0 && slots[0].Start.Before(startDay) {
+		slots = slots[1:]
+	}
+
+	lastOpen := !decided && len(slots) > 0 && lastPeriodOpen(slots[len(slots)-1], end, today)
+
+	current, runs := computeStreaks(slots, lastOpen)
+	result.CurrentStreak = current
+	result.MaxStreak = longestRun(runs).Length
+
+	for i, slot := range slots {
+		if i == len(slots)-1 && lastOpen && !slot.Done {
+			break
+		}
+		result.TotalPeriods++
+		if slot.Done {
+			result.CompletedPeriods++
+		}
+	}
+
+	if result.TotalPeriods > 0 {
+		result.CompletionRate = float64(result.CompletedPeriods) / float64(result.TotalPeriods) * 100
+	}
+
+	return result
+}
@@ -0,0 +1,216 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+	"github.com/kubaliski/habit-tracker/backend/schedule"
+)
+
+// calendarSlot representa un período del calendario denso (del día Start al End, incluidos) y
+// si se cumplió
+type calendarSlot struct {
+	Start time.Time
+	End   time.Time
+	Done  bool
+}
+
+// buildCalendar genera todos los períodos entre start y end, con o sin registro, evaluando cada
+// uno con la versión de la meta vigente el primer día que cubre (versions, ordenado por fecha).
+// Los días sin registro cuentan como no cumplidos. Para hábitos diarios basta con un registro
+// completado; para semanales y mensuales se necesitan tantos días completados como su objetivo.
+// Los días que no tocan según el calendario y los justificados (excused) no forman parte del
+// calendario. En semanas y meses, los días justificados reducen la meta en proporción a los días
+// del período que quedan; un período justificado entero no se evalúa. Si la meta cambia a mitad
+// de una semana o un mes, el período en curso se termina con la meta anterior, salvo que la
+// nueva sea semanal o mensual: entonces se evalúa la parte que queda del período con la meta
+// reducida en proporción.
+func buildCalendar(logs []models.HabitLog, versions []models.HabitGoalVersion, excused map[string]bool, start, end time.Time) []calendarSlot {
+	completed := make(map[string]bool)
+	for _, log := range logs {
+		if log.Completed {
+			completed[log.Date.Format("2006-01-02")] = true
+		}
+	}
+
+	var slots []calendarSlot
+	from := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	for first := true; !from.After(end); first = false {
+		version, _ := goalVersionAt(versions, from.Format("2006-01-02"))
+		unit := periodUnit(version.Frequency)
+
+		// El primer período es entero; tras un cambio de meta, solo lo que queda de él
+		period := periodStart(from, unit)
+		periodEnd := nextPeriod(period, unit).AddDate(0, 0, -1)
+		slotStart := from
+		if first {
+			slotStart = period
+		}
+		from = periodEnd.AddDate(0, 0, 1)
+
+		goal := version.Goal
+		if unit == "day" || goal <= 0 {
+			goal = 1
+		}
+
+		if unit == "day" && !schedule.Scheduled(version.Schedule, period) {
+			continue
+		}
+
+		days, available, done := 0, 0, 0
+		for day := period; !day.After(periodEnd); day = day.AddDate(0, 0, 1) {
+			days++
+			date := day.Format("2006-01-02")
+			if day.Before(slotStart) || excused[date] {
+				continue
+			}
+			available++
+			if completed[date] {
+				done++
+			}
+		}
+		if available == 0 {
+			continue
+		}
+
+		slots = append(slots, calendarSlot{
+			Start: slotStart,
+			End:   periodEnd,
+			Done:  done >= (goal*available+days-1)/days,
+		})
+	}
+
+	return slots
+}
+
+// computeStreaks calcula la racha actual, la más larga y todas las rachas históricas.
+// Si lastOpen es true, el último período del calendario está en curso: si todavía no se
+// ha cumplido no rompe la racha, que se mide hasta el período anterior.
+func computeStreaks(slots []calendarSlot, lastOpen bool) (current int, runs []models.StreakRun) {
+	var run *models.StreakRun
+
+	closeRun := func(last calendarSlot) {
+		if run != nil {
+			run.EndDate = last.End.Format("2006-01-02")
+			runs = append(runs, *run)
+			run = nil
+		}
+	}
+
+	for i, slot := range slots {
+		if !slot.Done {
+			if i > 0 {
+				closeRun(slots[i-1])
+			}
+			continue
+		}
+
+		if run == nil {
+			run = &models.StreakRun{StartDate: slot.Start.Format("2006-01-02")}
+		}
+		run.Length++
+	}
+
+	if len(slots) > 0 {
+		closeRun(slots[len(slots)-1])
+	}
+
+	// Recorrer hacia atrás desde el período en curso
+	i := len(slots) - 1
+	if i >= 0 && lastOpen && !slots[i].Done {
+		i--
+	}
+	for ; i >= 0 && slots[i].Done; i-- {
+		current++
+	}
+
+	return current, runs
+}
+
+// longestRun devuelve la racha más larga (la más reciente en caso de empate)
+func longestRun(runs []models.StreakRun) models.StreakRun {
+	var longest models.StreakRun
+	for _, run := range runs {
+		if run.Length >= longest.Length {
+			longest = run
+		}
+	}
+	return longest
+}
+
+// GetHabitStreaks calcula las rachas de un hábito desde su primer registro hasta hoy
+func (r *SQLiteRepo) GetHabitStreaks(habitID int) (models.HabitStreaks, error) {
+	habit, err := r.GetHabit(habitID)
+	if err != nil {
+		return models.HabitStreaks{}, fmt.Errorf("error al obtener información del hábito: %w", err)
+	}
+
+	unit := periodUnit(habit.Frequency)
+	result := models.HabitStreaks{
+		HabitID:   habit.ID,
+		HabitName: habit.Name,
+		Unit:      unit,
+		Runs:      []models.StreakRun{},
+	}
+
+	// Buscar el primer registro del hábito (los hábitos a evitar se siguen desde su creación)
+	var firstDate sql.NullString
+	if habit.Avoid {
+		start, err := r.avoidTrackingStart(habit)
+		if err != nil {
+			return models.HabitStreaks{}, err
+		}
+		firstDate = sql.NullString{String: start, Valid: true}
+	} else {
+		err = r.db.QueryRow("SELECT MIN(date) FROM habit_logs WHERE habit_id = ?", habitID).Scan(&firstDate)
+		if err != nil {
+			return models.HabitStreaks{}, fmt.Errorf("error al obtener el primer registro del hábito: %w", err)
+		}
+	}
+	if !firstDate.Valid {
+		return result, nil
+	}
+
+	start, err := time.Parse("2006-01-02", firstDate.String)
+	if err != nil {
+		return models.HabitStreaks{}, fmt.Errorf("fecha de registro inválida: %w", err)
+	}
+
+	today, err := time.Parse("2006-01-02", r.Today())
+	if err != nil {
+		return models.HabitStreaks{}, err
+	}
+
+	logs, err := r.statsHabitLogs(habit, firstDate.String, today.Format("2006-01-02"))
+	if err != nil {
+		return models.HabitStreaks{}, fmt.Errorf("error al obtener registros del hábito: %w", err)
+	}
+
+	excused, err := r.periodExcusedDates(habit.ID, start, today)
+	if err != nil {
+		return models.HabitStreaks{}, err
+	}
+
+	versions, err := r.habitGoalVersions(habit)
+	if err != nil {
+		return models.HabitStreaks{}, err
+	}
+
+	slots := buildCalendar(logs, versions, excused, start, today)
+	lastOpen := !lastPeriodDecided(habit, unit) && len(slots) > 0 &&
+		lastPeriodOpen(slots[len(slots)-1], today, today.Format("2006-01-02"))
+	current, runs := computeStreaks(slots, lastOpen)
+	longest := longestRun(runs)
+
+	result.CurrentStreak = current
+	result.LongestStreak = longest.Length
+	result.LongestStart = longest.StartDate
+	result.LongestEnd = longest.EndDate
+	if runs != nil {
+		result.Runs = runs
+	}
+
+	return result, nil
+}
@@ -0,0 +1,255 @@
+package database
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+	"github.com/kubaliski/habit-tracker/backend/schedule"
+)
+
+// testDay convierte una fecha YYYY-MM-DD en un instante a medianoche
+func testDay(t *testing.T, date string) time.Time {
+	t.Helper()
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		t.Fatalf("fecha inválida %q: %v", date, err)
+	}
+	return day
+}
+
+// testLogs crea registros completados en las fechas indicadas
+func testLogs(t *testing.T, dates ...string) []models.HabitLog {
+	t.Helper()
+	logs := make([]models.HabitLog, 0, len(dates))
+	for _, date := range dates {
+		logs = append(logs, models.HabitLog{Date: testDay(t, date), Completed: true, Count: 1})
+	}
+	return logs
+}
+
+// testExcused marca como justificados los días entre from y to, ambos incluidos
+func testExcused(t *testing.T, from, to string) map[string]bool {
+	t.Helper()
+	excused := make(map[string]bool)
+	for day := testDay(t, from); !day.After(testDay(t, to)); day = day.AddDate(0, 0, 1) {
+		excused[day.Format("2006-01-02")] = true
+	}
+	return excused
+}
+
+// testVersion crea una versión de la meta con el calendario derivado de la frecuencia
+func testVersion(effectiveDate, frequency string, goal int) models.HabitGoalVersion {
+	return models.HabitGoalVersion{
+		EffectiveDate: effectiveDate,
+		Frequency:     frequency,
+		Goal:          goal,
+		Schedule:      schedule.FromFrequency(frequency, goal),
+	}
+}
+
+func TestComputePeriodStats(t *testing.T) {
+	daily := []models.HabitGoalVersion{testVersion("2026-01-01", models.FrequencyDaily, 1)}
+	weekly := []models.HabitGoalVersion{testVersion("2026-01-01", models.FrequencyWeekly, 2)}
+	monthly := []models.HabitGoalVersion{testVersion("2026-01-01", models.FrequencyMonthly, 3)}
+
+	// Lunes, miércoles y viernes
+	weekdays := []models.HabitGoalVersion{{
+		EffectiveDate: "2026-01-01",
+		Frequency:     models.FrequencyDaily,
+		Goal:          1,
+		Schedule:      models.HabitSchedule{Type: models.ScheduleWeekdays, Weekdays: 1<<time.Monday | 1<<time.Wednesday | 1<<time.Friday},
+	}}
+
+	tests := []struct {
+		name     string
+		versions []models.HabitGoalVersion
+		logs     []string
+		excused  map[string]bool
+		start    string
+		end      string
+		today    string
+		decided  bool
+		want     periodResult
+	}{
+		{
+			name:     "diario con un fallo",
+			versions: daily,
+			logs:     []string{"2026-09-01", "2026-09-02", "2026-09-03", "2026-09-05"},
+			start:    "2026-09-01", end: "2026-09-05", today: "2026-09-05",
+			want: periodResult{TotalPeriods: 5, CompletedPeriods: 4, CompletionRate: 80, CurrentStreak: 1, MaxStreak: 3},
+		},
+		{
+			name:     "diario con hoy pendiente",
+			versions: daily,
+			logs:     []string{"2026-09-01", "2026-09-02", "2026-09-03", "2026-09-04"},
+			start:    "2026-09-01", end: "2026-09-05", today: "2026-09-05",
+			want: periodResult{TotalPeriods: 4, CompletedPeriods: 4, CompletionRate: 100, CurrentStreak: 4, MaxStreak: 4},
+		},
+		{
+			name:     "diario con hoy ya decidido",
+			versions: daily,
+			logs:     []string{"2026-09-01", "2026-09-02", "2026-09-03", "2026-09-04"},
+			start:    "2026-09-01", end: "2026-09-05", today: "2026-09-05", decided: true,
+			want: periodResult{TotalPeriods: 5, CompletedPeriods: 4, CompletionRate: 80, CurrentStreak: 0, MaxStreak: 4},
+		},
+		{
+			name:     "diario en un rango pasado",
+			versions: daily,
+			logs:     []string{"2026-09-01", "2026-09-02", "2026-09-03", "2026-09-04"},
+			start:    "2026-09-01", end: "2026-09-05", today: "2026-09-20",
+			want: periodResult{TotalPeriods: 5, CompletedPeriods: 4, CompletionRate: 80, CurrentStreak: 0, MaxStreak: 4},
+		},
+		{
+			name:     "diario con un hueco justificado",
+			versions: daily,
+			logs:     []string{"2026-09-01", "2026-09-02", "2026-09-04", "2026-09-05"},
+			excused:  testExcused(t, "2026-09-03", "2026-09-03"),
+			start:    "2026-09-01", end: "2026-09-05", today: "2026-09-05",
+			want: periodResult{TotalPeriods: 4, CompletedPeriods: 4, CompletionRate: 100, CurrentStreak: 4, MaxStreak: 4},
+		},
+		{
+			name:     "días de la semana sin los libres",
+			versions: weekdays,
+			logs:     []string{"2026-09-07", "2026-09-09", "2026-09-11"},
+			start:    "2026-09-07", end: "2026-09-13", today: "2026-09-20",
+			want: periodResult{TotalPeriods: 3, CompletedPeriods: 3, CompletionRate: 100, CurrentStreak: 3, MaxStreak: 3},
+		},
+		{
+			name:     "semanal con la semana en curso",
+			versions: weekly,
+			logs:     []string{"2026-09-08", "2026-09-10", "2026-09-15", "2026-09-22", "2026-09-24", "2026-09-29"},
+			start:    "2026-09-07", end: "2026-09-30", today: "2026-09-30",
+			want: periodResult{TotalPeriods: 3, CompletedPeriods: 2, CompletionRate: 200.0 / 3, CurrentStreak: 1, MaxStreak: 1},
+		},
+		{
+			name:     "semanal con la primera semana parcial",
+			versions: weekly,
+			logs:     []string{"2026-09-08", "2026-09-10", "2026-09-15", "2026-09-22", "2026-09-24", "2026-09-29"},
+			start:    "2026-09-10", end: "2026-09-30", today: "2026-09-30",
+			want: periodResult{TotalPeriods: 2, CompletedPeriods: 1, CompletionRate: 50, CurrentStreak: 1, MaxStreak: 1},
+		},
+		{
+			name:     "semanal con días justificados reduce la meta",
+			versions: weekly,
+			logs:     []string{"2026-09-12"},
+			excused:  testExcused(t, "2026-09-07", "2026-09-10"),
+			start:    "2026-09-07", end: "2026-09-13", today: "2026-09-20",
+			want: periodResult{TotalPeriods: 1, CompletedPeriods: 1, CompletionRate: 100, CurrentStreak: 1, MaxStreak: 1},
+		},
+		{
+			name:     "semanal con días justificados sigue pidiendo un día",
+			versions: weekly,
+			excused:  testExcused(t, "2026-09-07", "2026-09-10"),
+			start:    "2026-09-07", end: "2026-09-13", today: "2026-09-20",
+			want: periodResult{TotalPeriods: 1, CompletedPeriods: 0, CompletionRate: 0, CurrentStreak: 0, MaxStreak: 0},
+		},
+		{
+			name:     "semanal con una semana justificada entera",
+			versions: weekly,
+			logs:     []string{"2026-09-08", "2026-09-10", "2026-09-22", "2026-09-24"},
+			excused:  testExcused(t, "2026-09-14", "2026-09-20"),
+			start:    "2026-09-07", end: "2026-09-27", today: "2026-10-10",
+			want: periodResult{TotalPeriods: 2, CompletedPeriods: 2, CompletionRate: 100, CurrentStreak: 2, MaxStreak: 2},
+		},
+		{
+			name:     "mensual con el mes en curso",
+			versions: monthly,
+			logs:     []string{"2026-09-02", "2026-09-15", "2026-09-28", "2026-10-01"},
+			start:    "2026-09-01", end: "2026-10-05", today: "2026-10-05",
+			want: periodResult{TotalPeriods: 1, CompletedPeriods: 1, CompletionRate: 100, CurrentStreak: 1, MaxStreak: 1},
+		},
+		{
+			name: "cambio de diario a semanal a mitad de semana",
+			versions: []models.HabitGoalVersion{
+				testVersion("2026-09-01", models.FrequencyDaily, 1),
+				testVersion("2026-09-10", models.FrequencyWeekly, 2),
+			},
+			logs:  []string{"2026-09-07", "2026-09-08", "2026-09-09", "2026-09-11", "2026-09-12"},
+			start: "2026-09-07", end: "2026-09-13", today: "2026-09-20",
+			want: periodResult{TotalPeriods: 4, CompletedPeriods: 4, CompletionRate: 100, CurrentStreak: 4, MaxStreak: 4},
+		},
+		{
+			name: "meta semanal más alta solo desde su fecha",
+			versions: []models.HabitGoalVersion{
+				testVersion("2026-09-01", models.FrequencyWeekly, 1),
+				testVersion("2026-09-14", models.FrequencyWeekly, 3),
+			},
+			logs:  []string{"2026-09-08", "2026-09-15", "2026-09-16"},
+			start: "2026-09-07", end: "2026-09-20", today: "2026-09-30",
+			want: periodResult{TotalPeriods: 2, CompletedPeriods: 1, CompletionRate: 50, CurrentStreak: 0, MaxStreak: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := computePeriodStats(testLogs(t, tt.logs...), tt.versions, tt.excused,
+				testDay(t, tt.start), testDay(t, tt.end), tt.today, tt.decided)
+
+			rateOK := math.Abs(got.CompletionRate-tt.want.CompletionRate) < 1e-9
+			got.CompletionRate, tt.want.CompletionRate = 0, 0
+			if got != tt.want || !rateOK {
+				t.Errorf("computePeriodStats() = %+v, se esperaba %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeStreaksRuns(t *testing.T) {
+	versions := []models.HabitGoalVersion{testVersion("2026-01-01", models.FrequencyWeekly, 2)}
+	logs := testLogs(t, "2026-09-08", "2026-09-10", "2026-09-15", "2026-09-22", "2026-09-24", "2026-09-29")
+
+	slots := buildCalendar(logs, versions, nil, testDay(t, "2026-09-07"), testDay(t, "2026-09-30"))
+	if len(slots) != 4 {
+		t.Fatalf("buildCalendar() devolvió %d semanas, se esperaban 4", len(slots))
+	}
+
+	current, runs := computeStreaks(slots, true)
+	if current != 1 {
+		t.Errorf("racha actual = %d, se esperaba 1", current)
+	}
+
+	want := []models.StreakRun{
+		{StartDate: "2026-09-07", EndDate: "2026-09-13", Length: 1},
+		{StartDate: "2026-09-21", EndDate: "2026-09-27", Length: 1},
+	}
+	if len(runs) != len(want) {
+		t.Fatalf("rachas = %+v, se esperaban %+v", runs, want)
+	}
+	for i := range want {
+		if runs[i] != want[i] {
+			t.Errorf("racha %d = %+v, se esperaba %+v", i, runs[i], want[i])
+		}
+	}
+
+	// Con la semana en curso cerrada, su fallo rompe la racha
+	if current, _ := computeStreaks(slots, false); current != 0 {
+		t.Errorf("racha actual con la última semana cerrada = %d, se esperaba 0", current)
+	}
+}
+
+func TestLastPeriodOpen(t *testing.T) {
+	week := calendarSlot{Start: testDay(t, "2026-09-28"), End: testDay(t, "2026-10-04")}
+
+	tests := []struct {
+		name  string
+		slot  calendarSlot
+		end   string
+		today string
+		want  bool
+	}{
+		{name: "semana en curso", slot: week, end: "2026-09-30", today: "2026-09-30", want: true},
+		{name: "rango que corta la semana", slot: week, end: "2026-09-30", today: "2026-10-20", want: true},
+		{name: "semana cubierta entera", slot: week, end: "2026-10-04", today: "2026-10-20", want: false},
+		{name: "último día que tocaba antes del final", slot: calendarSlot{Start: testDay(t, "2026-09-11"), End: testDay(t, "2026-09-11")}, end: "2026-09-13", today: "2026-09-13", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lastPeriodOpen(tt.slot, testDay(t, tt.end), tt.today); got != tt.want {
+				t.Errorf("lastPeriodOpen() = %v, se esperaba %v", got, tt.want)
+			}
+		})
+	}
+}
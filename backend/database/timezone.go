@@ -0,0 +1,271 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+	"github.com/mattn/go-sqlite3"
+)
+
+// ==================== ZONA HORARIA Y DÍAS ====================
+
+// dayClock asigna instantes a días: en la zona horaria del usuario, y con el cambio de día a
+// la hora rolloverHour en lugar de a medianoche
+type dayClock struct {
+	loc          *time.Location
+	rolloverHour int
+}
+
+// day devuelve el día lógico (YYYY-MM-DD) al que pertenece un instante
+func (c dayClock) day(t time.Time) string {
+	local := t.In(c.loc)
+	if local.Hour() < c.rolloverHour {
+		local = time.Date(local.Year(), local.Month(), local.Day()-1, 0, 0, 0, 0, c.loc)
+	}
+	return local.Format("2006-01-02")
+}
+
+// start devuelve el instante en que empieza un día lógico
+func (c dayClock) start(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), c.rolloverHour, 0, 0, 0, c.loc)
+}
+
+// dayClock devuelve la configuración de días vigente (la zona del sistema si no hay ninguna)
+func (r *SQLiteRepo) dayClock() dayClock {
+	if clock := r.clock.Load(); clock != nil {
+		return *clock
+	}
+	return dayClock{loc: time.Local}
+}
+
+// Location devuelve la zona horaria configurada por el usuario (la del sistema si no hay ninguna)
+func (r *SQLiteRepo) Location() *time.Location {
+	return r.dayClock().loc
+}
+
+// Now devuelve el instante actual en la zona horaria del usuario
+func (r *SQLiteRepo) Now() time.Time {
+	return time.Now().In(r.Location())
+}
+
+// Today devuelve el día lógico actual (YYYY-MM-DD)
+func (r *SQLiteRepo) Today() string {
+	return r.DayOf(time.Now())
+}
+
+// DayOf devuelve el día lógico (YYYY-MM-DD) al que pertenece un instante, según la zona horaria
+// y la hora de cambio de día del usuario. Es la única regla para asignar instantes a días: la
+// usan las consultas, las estadísticas y los controladores.
+func (r *SQLiteRepo) DayOf(t time.Time) string {
+	return r.dayClock().day(t)
+}
+
+// localInstant convierte un instante guardado en UTC a la zona del usuario
+func (r *SQLiteRepo) localInstant(stored string) time.Time {
+	t, _ := time.Parse(time.RFC3339, stored)
+	return t.In(r.Location())
+}
+
+// moodSlotHours sitúa cada franja del día para ordenar los registros de ánimo sin hora
+var moodSlotHours = map[string]int{
+	models.MoodSlotMorning:   9,
+	models.MoodSlotAfternoon: 15,
+	models.MoodSlotEvening:   20,
+	models.MoodSlotNight:     23,
+}
+
+// moodEntryMinute devuelve los minutos transcurridos desde el inicio del día lógico hasta un
+// registro de ánimo: por su hora o por su franja. Los registros del día completo van primero (-1).
+func (c dayClock) moodEntryMinute(entry models.MoodEntry) int {
+	hour, minute := -1, 0
+	if entry.Timestamp != nil {
+		local := entry.Timestamp.In(c.loc)
+		hour, minute = local.Hour(), local.Minute()
+	} else if slotHour, ok := moodSlotHours[entry.Slot]; ok {
+		hour = slotHour
+	}
+
+	if hour < 0 {
+		return -1
+	}
+	return c.minuteOfDay(hour, minute)
+}
+
+// minuteOfDay devuelve los minutos transcurridos desde el inicio del día lógico hasta una
+// hora del reloj local: con el cambio de día a las 4, las 2:00 son el minuto 1320
+func (c dayClock) minuteOfDay(hour, minute int) int {
+	return (hour-c.rolloverHour+24)%24*60 + minute
+}
+
+// loadDayClock carga la zona horaria y la hora de cambio de día guardadas en los ajustes
+func (r *SQLiteRepo) loadDayClock() error {
+	name, err := r.getStringSetting(settingTimezone, "")
+	if err != nil {
+		return err
+	}
+
+	rolloverHour, err := r.getIntSetting(settingRolloverHour, 0)
+	if err != nil {
+		return err
+	}
+
+	loc, err := loadTimezone(name)
+	if err != nil {
+		return err
+	}
+
+	r.clock.Store(&dayClock{loc: loc, rolloverHour: rolloverHour})
+	return nil
+}
+
+// loadTimezone interpreta el nombre de una zona IANA; vacío es la zona del sistema
+func loadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("zona horaria desconocida %q: %w", name, err)
+	}
+	return loc, nil
+}
+
+// GetTimeSettings obtiene la zona horaria y la hora de cambio de día configuradas
+func (r *SQLiteRepo) GetTimeSettings() (models.TimeSettings, error) {
+	name, err := r.getStringSetting(settingTimezone, "")
+	if err != nil {
+		return models.TimeSettings{}, err
+	}
+
+	clock := r.dayClock()
+	return models.TimeSettings{
+		Timezone:          name,
+		EffectiveTimezone: clock.loc.String(),
+		RolloverHour:      clock.rolloverHour,
+	}, nil
+}
+
+// UpdateTimeSettings cambia la zona horaria o la hora de cambio de día y reasigna a su nuevo
+// día lógico, en la misma transacción, todas las filas con hora de localDateTables: consumos
+// de cafeína, recaídas y registros de ánimo
+func (r *SQLiteRepo) UpdateTimeSettings(input models.UpdateTimeSettingsInput) error {
+	if input.Timezone == nil && input.RolloverHour == nil {
+		return nil
+	}
+
+	clock := r.dayClock()
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	if input.Timezone != nil {
+		if clock.loc, err = loadTimezone(*input.Timezone); err != nil {
+			return err
+		}
+		if err := setSetting(tx, settingTimezone, *input.Timezone); err != nil {
+			return err
+		}
+	}
+
+	if input.RolloverHour != nil {
+		if *input.RolloverHour < 0 || *input.RolloverHour >= models.MaxRolloverHour {
+			return fmt.Errorf("la hora de cambio de día debe estar entre 0 y %d", models.MaxRolloverHour-1)
+		}
+		clock.rolloverHour = *input.RolloverHour
+		if err := setSetting(tx, settingRolloverHour, strconv.Itoa(clock.rolloverHour)); err != nil {
+			return err
+		}
+	}
+
+	for _, table := range localDateTables {
+		if err := normalizeLocalDates(tx, table.name, table.column, clock); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error al confirmar transacción: %w", err)
+	}
+
+	r.clock.Store(&clock)
+	return nil
+}
+
+// localDateTables son las tablas con instantes en UTC y la columna con su día lógico. En
+// mood_entries solo los registros con hora tienen instante; los demás conservan su fecha.
+var localDateTables = []struct {
+	name   string
+	column string
+}{
+	{"caffeine_intake", "local_date"},
+	{"habit_slips", "local_date"},
+	{"mood_entries", "date"},
+}
+
+// normalizeLocalDates guarda todos los instantes de la tabla en UTC y recalcula su día lógico
+// (en la columna indicada) con la configuración dada. Las filas sin instante no se tocan.
+func normalizeLocalDates(tx *sql.Tx, table, column string, clock dayClock) error {
+	rows, err := tx.Query(fmt.Sprintf("SELECT id, timestamp FROM %s WHERE timestamp IS NOT NULL", table))
+	if err != nil {
+		return fmt.Errorf("error al leer %s: %w", table, err)
+	}
+
+	type storedTime struct {
+		id        int
+		timestamp time.Time
+	}
+
+	var records []storedTime
+	for rows.Next() {
+		var id int
+		var stored string
+		if err := rows.Scan(&id, &stored); err != nil {
+			rows.Close()
+			return fmt.Errorf("error al escanear %s: %w", table, err)
+		}
+
+		timestamp, err := parseStoredTimestamp(stored)
+		if err != nil {
+			rows.Close()
+			return fmt.Errorf("instante inválido en %s %d: %w", table, id, err)
+		}
+		records = append(records, storedTime{id, timestamp})
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error al iterar %s: %w", table, err)
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET timestamp = ?, %s = ? WHERE id = ?", table, column)
+	for _, record := range records {
+		if _, err := tx.Exec(query, record.timestamp.UTC(), clock.day(record.timestamp), record.id); err != nil {
+			return fmt.Errorf("error al normalizar %s %d: %w", table, record.id, err)
+		}
+	}
+
+	return nil
+}
+
+// parseStoredTimestamp interpreta un instante tal como lo devuelve el driver (RFC 3339) o
+// en cualquiera de los formatos de texto que acepta SQLite
+func parseStoredTimestamp(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+
+	for _, layout := range sqlite3.SQLiteTimestampFormats {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("formato de fecha desconocido: %q", value)
+}
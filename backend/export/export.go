@@ -0,0 +1,335 @@
+// Package export genera y lee las exportaciones completas de datos de la aplicación
+package export
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/database"
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+// Límites de fecha usados cuando no se filtra por rango
+const (
+	minDate = "0001-01-01"
+	maxDate = "9999-12-31"
+)
+
+// Service reúne los datos del repositorio en un documento de exportación
+type Service struct {
+	Repo database.Repository
+}
+
+// NewService crea un nuevo servicio de exportación
+func NewService(repo database.Repository) *Service {
+	return &Service{
+		Repo: repo,
+	}
+}
+
+// Collect obtiene todos los datos que cumplen los filtros indicados
+func (s *Service) Collect(options models.ExportOptions) (models.ExportDocument, error) {
+	domains := options.Domains
+	if len(domains) == 0 {
+		domains = []string{models.ExportDomainHabits, models.ExportDomainMood, models.ExportDomainCaffeine}
+	}
+
+	startDate, endDate := options.StartDate, options.EndDate
+	if startDate == "" {
+		startDate = minDate
+	}
+	if endDate == "" {
+		endDate = maxDate
+	}
+
+	schemaVersion, err := s.Repo.GetSchemaVersion()
+	if err != nil {
+		return models.ExportDocument{}, err
+	}
+
+	doc := models.ExportDocument{
+		FormatVersion:     models.ExportFormatVersion,
+		SchemaVersion:     schemaVersion,
+		ExportedAt:        time.Now(),
+		StartDate:         options.StartDate,
+		EndDate:           options.EndDate,
+		Domains:           domains,
+		Habits:            []models.Habit{},
+		HabitGoalVersions: []models.HabitGoalVersion{},
+		HabitLogs:         []models.HabitLog{},
+		HabitSlips:        []models.HabitSlip{},
+		Excuses:           []models.Excuse{},
+		MoodEntries:       []models.MoodEntry{},
+		CaffeineBeverages: []models.CaffeineBeverage{},
+		CaffeineIntake:    []models.CaffeineIntake{},
+	}
+
+	for _, domain := range domains {
+		switch domain {
+		case models.ExportDomainHabits:
+			habits, err := s.Repo.GetAllHabits()
+			if err != nil {
+				return models.ExportDocument{}, err
+			}
+
+			for _, habit := range habits {
+				versions, err := s.Repo.GetHabitGoalVersions(habit.ID)
+				if err != nil {
+					return models.ExportDocument{}, err
+				}
+				doc.HabitGoalVersions = append(doc.HabitGoalVersions, versions...)
+
+				logs, err := s.Repo.GetHabitLogs(habit.ID, startDate, endDate)
+				if err != nil {
+					return models.ExportDocument{}, err
+				}
+				doc.HabitLogs = append(doc.HabitLogs, logs...)
+
+				slips, err := s.Repo.GetHabitSlips(habit.ID, startDate, endDate)
+				if err != nil {
+					return models.ExportDocument{}, err
+				}
+				doc.HabitSlips = append(doc.HabitSlips, slips...)
+			}
+			doc.Habits = append(doc.Habits, habits...)
+
+			excuses, err := s.Repo.GetExcuses(0, startDate, endDate)
+			if err != nil {
+				return models.ExportDocument{}, err
+			}
+			doc.Excuses = append(doc.Excuses, excuses...)
+
+		case models.ExportDomainMood:
+			entries, err := s.Repo.GetAllMoodEntries(startDate, endDate)
+			if err != nil {
+				return models.ExportDocument{}, err
+			}
+			doc.MoodEntries = append(doc.MoodEntries, entries...)
+
+		case models.ExportDomainCaffeine:
+			beverages, err := s.Repo.GetAllCaffeineBeverages(true)
+			if err != nil {
+				return models.ExportDocument{}, err
+			}
+
+			intakes, err := s.Repo.GetCaffeineIntakeRange(startDate, endDate)
+			if err != nil {
+				return models.ExportDocument{}, err
+			}
+			doc.CaffeineBeverages = append(doc.CaffeineBeverages, beverages...)
+			doc.CaffeineIntake = append(doc.CaffeineIntake, intakes...)
+
+		default:
+			return models.ExportDocument{}, fmt.Errorf("dominio de exportación desconocido: %s", domain)
+		}
+	}
+
+	return doc, nil
+}
+
+// Counts devuelve el número de registros por tabla de un documento
+func Counts(doc models.ExportDocument) map[string]int {
+	return map[string]int{
+		"habits":              len(doc.Habits),
+		"habit_goal_versions": len(doc.HabitGoalVersions),
+		"habit_logs":          len(doc.HabitLogs),
+		"habit_slips":         len(doc.HabitSlips),
+		"excuses":             len(doc.Excuses),
+		"mood_entries":        len(doc.MoodEntries),
+		"caffeine_beverages":  len(doc.CaffeineBeverages),
+		"caffeine_intake":     len(doc.CaffeineIntake),
+	}
+}
+
+// WriteJSON escribe el documento como un único JSON
+func WriteJSON(w io.Writer, doc models.ExportDocument) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("error al escribir exportación JSON: %w", err)
+	}
+
+	return nil
+}
+
+// WriteCSVZip escribe el documento como un zip con un CSV por tabla y un manifest.json
+func WriteCSVZip(w io.Writer, doc models.ExportDocument) error {
+	archive := zip.NewWriter(w)
+
+	// El manifiesto conserva los metadatos sin las tablas
+	manifest := doc
+	manifest.Habits = nil
+	manifest.HabitGoalVersions = nil
+	manifest.HabitLogs = nil
+	manifest.HabitSlips = nil
+	manifest.Excuses = nil
+	manifest.MoodEntries = nil
+	manifest.CaffeineBeverages = nil
+	manifest.CaffeineIntake = nil
+
+	file, err := archive.Create("manifest.json")
+	if err != nil {
+		return fmt.Errorf("error al crear manifest.json: %w", err)
+	}
+	if err := WriteJSON(file, manifest); err != nil {
+		return err
+	}
+
+	for _, table := range csvTables(doc) {
+		file, err := archive.Create(table.name + ".csv")
+		if err != nil {
+			return fmt.Errorf("error al crear %s.csv: %w", table.name, err)
+		}
+
+		writer := csv.NewWriter(file)
+		if err := writer.Write(table.header); err != nil {
+			return fmt.Errorf("error al escribir %s.csv: %w", table.name, err)
+		}
+		if err := writer.WriteAll(table.rows); err != nil {
+			return fmt.Errorf("error al escribir %s.csv: %w", table.name, err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("error al cerrar el archivo zip: %w", err)
+	}
+
+	return nil
+}
+
+// csvTable representa un archivo CSV dentro del zip
+type csvTable struct {
+	name   string
+	header []string
+	rows   [][]string
+}
+
+// Cabeceras de los archivos CSV, en el mismo orden que las columnas de la base de datos
+var (
+	habitsHeader     = []string{"id", "name", "description", "category", "frequency", "goal", "schedule_type", "schedule_weekdays", "schedule_interval", "schedule_anchor", "measure", "unit", "target", "avoid", "created_at", "updated_at", "active"}
+	goalsHeader      = []string{"id", "habit_id", "effective_date", "frequency", "goal", "schedule_type", "schedule_weekdays", "schedule_interval", "schedule_anchor", "target", "created_at"}
+	habitLogsHeader  = []string{"id", "habit_id", "date", "completed", "count", "value", "notes"}
+	habitSlipsHeader = []string{"id", "habit_id", "timestamp", "notes", "created_at"}
+	excusesHeader    = []string{"id", "habit_id", "start_date", "end_date", "reason", "notes", "created_at"}
+	moodHeader       = []string{"id", "date", "timestamp", "slot", "mood_score", "energy_level", "anxiety_level", "stress_level", "sleep_hours", "notes", "created_at"}
+	moodTagsHeader   = []string{"mood_id", "tag"}
+	beveragesHeader  = []string{"id", "name", "caffeine_content", "standard_unit", "standard_unit_value", "category", "image_path", "active"}
+	intakeHeader     = []string{"id", "timestamp", "beverage_id", "beverage_name", "amount", "unit", "total_caffeine", "perceived_effects", "related_activity", "notes", "created_at"}
+)
+
+// csvTables convierte el documento en filas CSV
+func csvTables(doc models.ExportDocument) []csvTable {
+	habits := csvTable{name: "habits", header: habitsHeader}
+	for _, h := range doc.Habits {
+		habits.rows = append(habits.rows, []string{
+			strconv.Itoa(h.ID), h.Name, h.Description, h.Category, h.Frequency, strconv.Itoa(h.Goal),
+			h.Schedule.Type, strconv.Itoa(h.Schedule.Weekdays), strconv.Itoa(h.Schedule.Interval), h.Schedule.Anchor,
+			h.Measure, h.Unit, formatFloat(h.Target), strconv.FormatBool(h.Avoid), formatTime(h.CreatedAt), formatTime(h.UpdatedAt), strconv.FormatBool(h.Active),
+		})
+	}
+
+	versions := csvTable{name: "habit_goal_versions", header: goalsHeader}
+	for _, v := range doc.HabitGoalVersions {
+		versions.rows = append(versions.rows, []string{
+			strconv.Itoa(v.ID), strconv.Itoa(v.HabitID), v.EffectiveDate, v.Frequency, strconv.Itoa(v.Goal),
+			v.Schedule.Type, strconv.Itoa(v.Schedule.Weekdays), strconv.Itoa(v.Schedule.Interval), v.Schedule.Anchor,
+			formatFloat(v.Target), formatTime(v.CreatedAt),
+		})
+	}
+
+	logs := csvTable{name: "habit_logs", header: habitLogsHeader}
+	for _, l := range doc.HabitLogs {
+		logs.rows = append(logs.rows, []string{
+			strconv.Itoa(l.ID), strconv.Itoa(l.HabitID), l.Date.Format("2006-01-02"),
+			strconv.FormatBool(l.Completed), strconv.Itoa(l.Count), formatFloat(l.Value), l.Notes,
+		})
+	}
+
+	slips := csvTable{name: "habit_slips", header: habitSlipsHeader}
+	for _, sl := range doc.HabitSlips {
+		slips.rows = append(slips.rows, []string{
+			strconv.Itoa(sl.ID), strconv.Itoa(sl.HabitID), formatTime(sl.Timestamp), sl.Notes, formatTime(sl.CreatedAt),
+		})
+	}
+
+	// Los días justificados de todos los hábitos no tienen habit_id
+	excuses := csvTable{name: "excuses", header: excusesHeader}
+	for _, e := range doc.Excuses {
+		habitID := ""
+		if e.HabitID != nil {
+			habitID = strconv.Itoa(*e.HabitID)
+		}
+		excuses.rows = append(excuses.rows, []string{
+			strconv.Itoa(e.ID), habitID, e.StartDate, e.EndDate, e.Reason, e.Notes, formatTime(e.CreatedAt),
+		})
+	}
+
+	mood := csvTable{name: "mood_entries", header: moodHeader}
+	tags := csvTable{name: "mood_tags", header: moodTagsHeader}
+	for _, m := range doc.MoodEntries {
+		mood.rows = append(mood.rows, []string{
+			strconv.Itoa(m.ID), m.Date.Format("2006-01-02"), formatOptionalTime(m.Timestamp), m.Slot, strconv.Itoa(m.MoodScore),
+			strconv.Itoa(m.EnergyLevel), strconv.Itoa(m.AnxietyLevel), strconv.Itoa(m.StressLevel),
+			formatFloat(m.SleepHours), m.Notes, formatTime(m.CreatedAt),
+		})
+		for _, tag := range m.Tags {
+			tags.rows = append(tags.rows, []string{strconv.Itoa(m.ID), tag})
+		}
+	}
+
+	beverages := csvTable{name: "caffeine_beverages", header: beveragesHeader}
+	for _, b := range doc.CaffeineBeverages {
+		beverages.rows = append(beverages.rows, []string{
+			strconv.Itoa(b.ID), b.Name, formatFloat(b.CaffeineContent), b.StandardUnit,
+			formatFloat(b.StandardUnitValue), b.Category, b.ImagePath, strconv.FormatBool(b.Active),
+		})
+	}
+
+	intake := csvTable{name: "caffeine_intake", header: intakeHeader}
+	for _, i := range doc.CaffeineIntake {
+		intake.rows = append(intake.rows, []string{
+			strconv.Itoa(i.ID), formatTime(i.Timestamp), strconv.Itoa(i.BeverageID), i.BeverageName,
+			formatFloat(i.Amount), i.Unit, formatFloat(i.TotalCaffeine), i.PerceivedEffects,
+			i.RelatedActivity, i.Notes, formatTime(i.CreatedAt),
+		})
+	}
+
+	return []csvTable{habits, versions, logs, slips, excuses, mood, tags, beverages, intake}
+}
+
+// formatTime formatea una fecha en RFC 3339, o vacío si no está definida
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// formatOptionalTime formatea un instante opcional; vacío si no hay
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return formatTime(*t)
+}
+
+// formatFloat formatea un número sin ceros innecesarios
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// FileName devuelve el nombre de archivo predeterminado para una exportación
+func FileName(format string, at time.Time) string {
+	extension := "json"
+	if strings.EqualFold(format, models.ExportFormatCSV) {
+		extension = "zip"
+	}
+	return fmt.Sprintf("habit-tracker-%s.%s", at.Format("20060102-150405"), extension)
+}
@@ -0,0 +1,244 @@
+package export
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/kubaliski/habit-tracker/backend/database"
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+// newSeededRepo crea una base de datos temporal con registros de todos los dominios
+func newSeededRepo(t *testing.T) *database.SQLiteRepo {
+	t.Helper()
+	repo, err := database.NewSQLiteRepo(filepath.Join(t.TempDir(), "habits.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteRepo: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	mustID := func(id int, err error) int {
+		t.Helper()
+		must(err)
+		return id
+	}
+
+	reading := mustID(repo.CreateHabit(models.NewHabitInput{
+		Name: "Leer", Description: "Antes de dormir, sin pantallas", Frequency: models.FrequencyDaily,
+		Goal: 1, Measure: models.MeasureDuration, Unit: "min", Target: 20,
+	}))
+	smoking := mustID(repo.CreateHabit(models.NewHabitInput{Name: "Fumar", Frequency: models.FrequencyDaily, Goal: 1, Avoid: true}))
+
+	must(repo.LogHabit(reading, models.NewHabitLogInput{Date: "2026-10-01", Completed: true, Value: 25, Notes: "Capítulo 3, \"el faro\"\ny parte del 4"}))
+	must(repo.LogHabit(reading, models.NewHabitLogInput{Date: "2026-10-02", Value: 10}))
+	mustID(repo.CreateHabitSlip(smoking, models.NewHabitSlipInput{Timestamp: "2026-10-02T22:15:00+02:00", Notes: "Cena"}))
+	mustID(repo.CreateExcuse(models.NewExcuseInput{StartDate: "2026-10-05", EndDate: "2026-10-07", Reason: "sick"}))
+	mustID(repo.CreateExcuse(models.NewExcuseInput{HabitID: &reading, StartDate: "2026-10-09", EndDate: "2026-10-09", Reason: "rest"}))
+
+	mustID(repo.CreateMoodEntry(models.NewMoodEntryInput{
+		Date: "2026-10-01", Timestamp: "2026-10-01T09:30:00+02:00", MoodScore: 7, EnergyLevel: 6,
+		AnxietyLevel: 3, StressLevel: 4, SleepHours: 7.5, Tags: []string{"trabajo", "deporte"},
+	}))
+	mustID(repo.CreateMoodEntry(models.NewMoodEntryInput{Date: "2026-10-02", Slot: models.MoodSlotEvening, MoodScore: 5}))
+
+	coffee := mustID(repo.CreateCaffeineBeverage(models.NewCaffeineBeverageInput{
+		Name: "Café de filtro", CaffeineContent: 0.4, StandardUnit: "ml", StandardUnitValue: 250, Category: "café",
+	}))
+	mustID(repo.CreateCaffeineIntake(models.NewCaffeineIntakeInput{
+		Timestamp: "2026-10-01T08:00:00+02:00", BeverageID: coffee, Amount: 250, Unit: "ml", RelatedActivity: "trabajo",
+	}))
+
+	return repo
+}
+
+// csvSnapshot representa un documento por sus filas CSV, que es lo que conservan ambos formatos
+func csvSnapshot(doc models.ExportDocument) map[string][][]string {
+	snapshot := make(map[string][][]string)
+	for _, table := range csvTables(doc) {
+		snapshot[table.name] = table.rows
+	}
+	return snapshot
+}
+
+// contentSnapshot es como csvSnapshot, pero sin depender de los IDs, que se reasignan al
+// importar: las referencias se sustituyen por el nombre o la clave del registro y las filas se ordenan.
+// updated_at tampoco se compara, porque la importación lo actualiza.
+func contentSnapshot(doc models.ExportDocument) map[string][]string {
+	refs := map[string]map[string]string{"id": {}, "habit_id": {}, "mood_id": {}, "beverage_id": {}}
+	for _, h := range doc.Habits {
+		refs["habit_id"][strconv.Itoa(h.ID)] = h.Name
+	}
+	for _, m := range doc.MoodEntries {
+		refs["mood_id"][strconv.Itoa(m.ID)] = m.Date.Format("2006-01-02") + " " + formatOptionalTime(m.Timestamp) + " " + m.Slot
+	}
+	for _, b := range doc.CaffeineBeverages {
+		refs["beverage_id"][strconv.Itoa(b.ID)] = b.Name
+	}
+
+	snapshot := make(map[string][]string)
+	for _, table := range csvTables(doc) {
+		rows := []string{}
+		for _, row := range table.rows {
+			row = slices.Clone(row)
+			for i, column := range table.header {
+				if column == "updated_at" {
+					row[i] = ""
+				}
+				if ref, ok := refs[column]; ok {
+					row[i] = ref[row[i]]
+				}
+			}
+			rows = append(rows, strings.Join(row, "|"))
+		}
+		sort.Strings(rows)
+		snapshot[table.name] = rows
+	}
+	return snapshot
+}
+
+func TestExportRoundtrip(t *testing.T) {
+	repo := newSeededRepo(t)
+	doc, err := NewService(repo).Collect(models.ExportOptions{})
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+
+	// Las bebidas incluyen las predeterminadas, así que no se comprueban
+	for table, count := range map[string]int{
+		"habits": 2, "habit_goal_versions": 2, "habit_logs": 2, "habit_slips": 1, "excuses": 2,
+		"mood_entries": 2, "caffeine_intake": 1,
+	} {
+		if got := Counts(doc)[table]; got != count {
+			t.Errorf("Counts()[%s] = %d, se esperaban %d", table, got, count)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		write func(*bytes.Buffer, models.ExportDocument) error
+		read  func([]byte) (models.ExportDocument, error)
+	}{
+		{
+			name:  "JSON",
+			write: func(buf *bytes.Buffer, doc models.ExportDocument) error { return WriteJSON(buf, doc) },
+			read:  func(data []byte) (models.ExportDocument, error) { return ReadJSON(bytes.NewReader(data)) },
+		},
+		{
+			name:  "CSV",
+			write: func(buf *bytes.Buffer, doc models.ExportDocument) error { return WriteCSVZip(buf, doc) },
+			read: func(data []byte) (models.ExportDocument, error) {
+				return ReadCSVZip(bytes.NewReader(data), int64(len(data)))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.write(&buf, doc); err != nil {
+				t.Fatalf("escribir: %v", err)
+			}
+			read, err := tt.read(buf.Bytes())
+			if err != nil {
+				t.Fatalf("leer: %v", err)
+			}
+
+			if read.FormatVersion != doc.FormatVersion || read.SchemaVersion != doc.SchemaVersion ||
+				!reflect.DeepEqual(read.Domains, doc.Domains) {
+				t.Errorf("metadatos = v%d/%d %v, se esperaban v%d/%d %v",
+					read.FormatVersion, read.SchemaVersion, read.Domains, doc.FormatVersion, doc.SchemaVersion, doc.Domains)
+			}
+
+			want, got := csvSnapshot(doc), csvSnapshot(read)
+			for table := range want {
+				if !reflect.DeepEqual(got[table], want[table]) {
+					t.Errorf("%s:\n obtenido %q\n esperado %q", table, got[table], want[table])
+				}
+			}
+		})
+	}
+}
+
+func TestReadRejectsNewerFormat(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, models.ExportDocument{FormatVersion: models.ExportFormatVersion + 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadJSON(&buf); err == nil {
+		t.Error("ReadJSON debería rechazar un formato posterior al actual")
+	}
+}
+
+func TestExportImportRoundtrip(t *testing.T) {
+	doc, err := NewService(newSeededRepo(t)).Collect(models.ExportOptions{})
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+
+	for _, format := range []string{models.ExportFormatJSON, models.ExportFormatCSV} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			path := filepath.Join(t.TempDir(), FileName(format, doc.ExportedAt))
+			write := WriteJSON
+			if format == models.ExportFormatCSV {
+				write = WriteCSVZip
+			}
+			if err := write(&buf, doc); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			read, err := ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+
+			target, err := database.NewSQLiteRepo(filepath.Join(t.TempDir(), "habits.db"))
+			if err != nil {
+				t.Fatalf("NewSQLiteRepo: %v", err)
+			}
+			defer target.Close()
+
+			if _, err := target.ImportDocument(read, models.ImportStrategySkip, false); err != nil {
+				t.Fatalf("ImportDocument: %v", err)
+			}
+
+			imported, err := NewService(target).Collect(models.ExportOptions{})
+			if err != nil {
+				t.Fatalf("Collect: %v", err)
+			}
+			want, got := contentSnapshot(doc), contentSnapshot(imported)
+			for table := range want {
+				if !reflect.DeepEqual(got[table], want[table]) {
+					t.Errorf("%s:\n importado %q\n esperado  %q", table, got[table], want[table])
+				}
+			}
+
+			// Importar de nuevo el mismo archivo no inserta nada
+			report, err := target.ImportDocument(read, models.ImportStrategySkip, false)
+			if err != nil {
+				t.Fatalf("ImportDocument: %v", err)
+			}
+			for table, stats := range report.Tables {
+				if stats.Inserted != 0 {
+					t.Errorf("segunda importación de %s = %+v, no se esperaban inserciones", table, stats)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,375 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+// ReadFile lee una exportación desde disco, detectando si es JSON o un zip de CSV
+func ReadFile(path string) (models.ExportDocument, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return models.ExportDocument{}, fmt.Errorf("error al leer el archivo de importación: %w", err)
+	}
+
+	// Los archivos zip empiezan por la firma "PK"
+	if bytes.HasPrefix(data, []byte("PK")) {
+		return ReadCSVZip(bytes.NewReader(data), int64(len(data)))
+	}
+
+	return ReadJSON(bytes.NewReader(data))
+}
+
+// ReadJSON lee un documento de exportación en formato JSON
+func ReadJSON(r io.Reader) (models.ExportDocument, error) {
+	var doc models.ExportDocument
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return models.ExportDocument{}, fmt.Errorf("error al leer exportación JSON: %w", err)
+	}
+
+	if err := checkFormatVersion(doc); err != nil {
+		return models.ExportDocument{}, err
+	}
+
+	return doc, nil
+}
+
+// ReadCSVZip lee un documento de exportación desde un zip con manifest.json y un CSV por tabla
+func ReadCSVZip(r io.ReaderAt, size int64) (models.ExportDocument, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return models.ExportDocument{}, fmt.Errorf("error al abrir el archivo zip: %w", err)
+	}
+
+	files := make(map[string]*zip.File)
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	manifest, ok := files["manifest.json"]
+	if !ok {
+		return models.ExportDocument{}, fmt.Errorf("el archivo zip no contiene manifest.json")
+	}
+
+	reader, err := manifest.Open()
+	if err != nil {
+		return models.ExportDocument{}, fmt.Errorf("error al abrir manifest.json: %w", err)
+	}
+	doc, err := ReadJSON(reader)
+	reader.Close()
+	if err != nil {
+		return models.ExportDocument{}, err
+	}
+
+	tables := make(map[string][]map[string]string)
+	for _, name := range []string{"habits", "habit_goal_versions", "habit_logs", "habit_slips", "excuses", "mood_entries", "mood_tags", "caffeine_beverages", "caffeine_intake"} {
+		f, ok := files[name+".csv"]
+		if !ok {
+			continue // Dominio no incluido en la exportación
+		}
+
+		records, err := readCSVRecords(f)
+		if err != nil {
+			return models.ExportDocument{}, fmt.Errorf("error al leer %s.csv: %w", name, err)
+		}
+		tables[name] = records
+	}
+
+	if err := parseCSVTables(&doc, tables); err != nil {
+		return models.ExportDocument{}, err
+	}
+
+	return doc, nil
+}
+
+// checkFormatVersion rechaza exportaciones generadas por una versión más reciente
+func checkFormatVersion(doc models.ExportDocument) error {
+	if doc.FormatVersion > models.ExportFormatVersion {
+		return fmt.Errorf("formato de exportación v%d no soportado (máximo v%d)", doc.FormatVersion, models.ExportFormatVersion)
+	}
+	return nil
+}
+
+// readCSVRecords lee un CSV y devuelve cada fila como un mapa columna → valor
+func readCSVRecords(f *zip.File) ([]map[string]string, error) {
+	reader, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	rows, err := csv.NewReader(reader).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	header := rows[0]
+	records := make([]map[string]string, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		record := make(map[string]string, len(header))
+		for i, column := range header {
+			if i < len(row) {
+				record[column] = row[i]
+			}
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// csvParser acumula el primer error al convertir valores de un CSV
+type csvParser struct {
+	table string
+	err   error
+}
+
+func (p *csvParser) int(record map[string]string, column string) int {
+	if p.err != nil || record[column] == "" {
+		return 0
+	}
+	v, err := strconv.Atoi(record[column])
+	if err != nil {
+		p.err = fmt.Errorf("valor inválido en %s.%s: %q", p.table, column, record[column])
+	}
+	return v
+}
+
+func (p *csvParser) float(record map[string]string, column string) float64 {
+	if p.err != nil || record[column] == "" {
+		return 0
+	}
+	v, err := strconv.ParseFloat(record[column], 64)
+	if err != nil {
+		p.err = fmt.Errorf("valor inválido en %s.%s: %q", p.table, column, record[column])
+	}
+	return v
+}
+
+func (p *csvParser) bool(record map[string]string, column string) bool {
+	if p.err != nil || record[column] == "" {
+		return false
+	}
+	v, err := strconv.ParseBool(record[column])
+	if err != nil {
+		p.err = fmt.Errorf("valor inválido en %s.%s: %q", p.table, column, record[column])
+	}
+	return v
+}
+
+func (p *csvParser) time(record map[string]string, column string) time.Time {
+	if p.err != nil || record[column] == "" {
+		return time.Time{}
+	}
+	v, err := time.Parse(time.RFC3339, record[column])
+	if err != nil {
+		p.err = fmt.Errorf("valor inválido en %s.%s: %q", p.table, column, record[column])
+	}
+	return v
+}
+
+// optionalTime interpreta un instante que puede faltar (columna vacía o inexistente)
+func (p *csvParser) optionalTime(record map[string]string, column string) *time.Time {
+	if record[column] == "" {
+		return nil
+	}
+	v := p.time(record, column)
+	return &v
+}
+
+func (p *csvParser) date(record map[string]string, column string) time.Time {
+	if p.err != nil {
+		return time.Time{}
+	}
+	v, err := time.Parse("2006-01-02", record[column])
+	if err != nil {
+		p.err = fmt.Errorf("valor inválido en %s.%s: %q", p.table, column, record[column])
+	}
+	return v
+}
+
+// parseCSVTables convierte las filas CSV en las tablas del documento
+func parseCSVTables(doc *models.ExportDocument, tables map[string][]map[string]string) error {
+	p := &csvParser{table: "habits"}
+	for _, r := range tables["habits"] {
+		schedule := models.HabitSchedule{
+			Type:     r["schedule_type"],
+			Weekdays: p.int(r, "schedule_weekdays"),
+			Interval: p.int(r, "schedule_interval"),
+			Anchor:   r["schedule_anchor"],
+		}
+		doc.Habits = append(doc.Habits, models.Habit{
+			ID:          p.int(r, "id"),
+			Name:        r["name"],
+			Description: r["description"],
+			Category:    r["category"],
+			Frequency:   r["frequency"],
+			Goal:        p.int(r, "goal"),
+			Schedule:    schedule,
+			Measure:     r["measure"],
+			Unit:        r["unit"],
+			Target:      p.float(r, "target"),
+			Avoid:       p.bool(r, "avoid"),
+			CreatedAt:   p.time(r, "created_at"),
+			UpdatedAt:   p.time(r, "updated_at"),
+			Active:      p.bool(r, "active"),
+		})
+	}
+	if p.err != nil {
+		return p.err
+	}
+
+	p = &csvParser{table: "habit_goal_versions"}
+	for _, r := range tables["habit_goal_versions"] {
+		schedule := models.HabitSchedule{
+			Type:     r["schedule_type"],
+			Weekdays: p.int(r, "schedule_weekdays"),
+			Interval: p.int(r, "schedule_interval"),
+			Anchor:   r["schedule_anchor"],
+		}
+		doc.HabitGoalVersions = append(doc.HabitGoalVersions, models.HabitGoalVersion{
+			ID:            p.int(r, "id"),
+			HabitID:       p.int(r, "habit_id"),
+			EffectiveDate: r["effective_date"],
+			Frequency:     r["frequency"],
+			Goal:          p.int(r, "goal"),
+			Schedule:      schedule,
+			Target:        p.float(r, "target"),
+			CreatedAt:     p.time(r, "created_at"),
+		})
+	}
+	if p.err != nil {
+		return p.err
+	}
+
+	p = &csvParser{table: "habit_logs"}
+	for _, r := range tables["habit_logs"] {
+		doc.HabitLogs = append(doc.HabitLogs, models.HabitLog{
+			ID:        p.int(r, "id"),
+			HabitID:   p.int(r, "habit_id"),
+			Date:      p.date(r, "date"),
+			Completed: p.bool(r, "completed"),
+			Count:     p.int(r, "count"),
+			Value:     p.float(r, "value"),
+			Notes:     r["notes"],
+		})
+	}
+	if p.err != nil {
+		return p.err
+	}
+
+	p = &csvParser{table: "habit_slips"}
+	for _, r := range tables["habit_slips"] {
+		doc.HabitSlips = append(doc.HabitSlips, models.HabitSlip{
+			ID:        p.int(r, "id"),
+			HabitID:   p.int(r, "habit_id"),
+			Timestamp: p.time(r, "timestamp"),
+			Notes:     r["notes"],
+			CreatedAt: p.time(r, "created_at"),
+		})
+	}
+	if p.err != nil {
+		return p.err
+	}
+
+	p = &csvParser{table: "excuses"}
+	for _, r := range tables["excuses"] {
+		var habitID *int
+		if r["habit_id"] != "" {
+			id := p.int(r, "habit_id")
+			habitID = &id
+		}
+		doc.Excuses = append(doc.Excuses, models.Excuse{
+			ID:        p.int(r, "id"),
+			HabitID:   habitID,
+			StartDate: r["start_date"],
+			EndDate:   r["end_date"],
+			Reason:    r["reason"],
+			Notes:     r["notes"],
+			CreatedAt: p.time(r, "created_at"),
+		})
+	}
+	if p.err != nil {
+		return p.err
+	}
+
+	p = &csvParser{table: "mood_tags"}
+	tags := make(map[int][]string)
+	for _, r := range tables["mood_tags"] {
+		moodID := p.int(r, "mood_id")
+		tags[moodID] = append(tags[moodID], r["tag"])
+	}
+	if p.err != nil {
+		return p.err
+	}
+
+	p = &csvParser{table: "mood_entries"}
+	for _, r := range tables["mood_entries"] {
+		id := p.int(r, "id")
+		doc.MoodEntries = append(doc.MoodEntries, models.MoodEntry{
+			ID:           id,
+			Date:         p.date(r, "date"),
+			Timestamp:    p.optionalTime(r, "timestamp"),
+			Slot:         r["slot"],
+			MoodScore:    p.int(r, "mood_score"),
+			EnergyLevel:  p.int(r, "energy_level"),
+			AnxietyLevel: p.int(r, "anxiety_level"),
+			StressLevel:  p.int(r, "stress_level"),
+			SleepHours:   p.float(r, "sleep_hours"),
+			Notes:        r["notes"],
+			Tags:         tags[id],
+			CreatedAt:    p.time(r, "created_at"),
+		})
+	}
+	if p.err != nil {
+		return p.err
+	}
+
+	p = &csvParser{table: "caffeine_beverages"}
+	for _, r := range tables["caffeine_beverages"] {
+		doc.CaffeineBeverages = append(doc.CaffeineBeverages, models.CaffeineBeverage{
+			ID:                p.int(r, "id"),
+			Name:              r["name"],
+			CaffeineContent:   p.float(r, "caffeine_content"),
+			StandardUnit:      r["standard_unit"],
+			StandardUnitValue: p.float(r, "standard_unit_value"),
+			Category:          r["category"],
+			ImagePath:         r["image_path"],
+			Active:            p.bool(r, "active"),
+		})
+	}
+	if p.err != nil {
+		return p.err
+	}
+
+	p = &csvParser{table: "caffeine_intake"}
+	for _, r := range tables["caffeine_intake"] {
+		doc.CaffeineIntake = append(doc.CaffeineIntake, models.CaffeineIntake{
+			ID:               p.int(r, "id"),
+			Timestamp:        p.time(r, "timestamp"),
+			BeverageID:       p.int(r, "beverage_id"),
+			BeverageName:     r["beverage_name"],
+			Amount:           p.float(r, "amount"),
+			Unit:             r["unit"],
+			TotalCaffeine:    p.float(r, "total_caffeine"),
+			PerceivedEffects: r["perceived_effects"],
+			RelatedActivity:  r["related_activity"],
+			Notes:            r["notes"],
+			CreatedAt:        p.time(r, "created_at"),
+		})
+	}
+
+	return p.err
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// BackupInfo describe una copia de seguridad de la base de datos
+type BackupInfo struct {
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"created_at"`
+	Size      int64     `json:"size"` // bytes
+}
+
+// BackupRetention define cuántas copias se conservan en la rotación diaria, semanal y mensual
+type BackupRetention struct {
+	Daily   int `json:"daily"`   // última copia de cada uno de los N días más recientes
+	Weekly  int `json:"weekly"`  // última copia de cada una de las N semanas más recientes
+	Monthly int `json:"monthly"` // última copia de cada uno de los N meses más recientes
+}
+
+// RestoreResult resume una restauración de copia de seguridad
+type RestoreResult struct {
+	RestoredFrom  string `json:"restored_from"`
+	SafetyBackup  string `json:"safety_backup"`  // copia del estado anterior (pre-restore-*.db); la rotación no la borra
+	SchemaVersion int    `json:"schema_version"` // versión del esquema de la copia restaurada
+}
@@ -73,3 +73,112 @@ type UpdateCaffeineIntakeInput struct {
 	RelatedActivity  string  `json:"related_activity"`
 	Notes            string  `json:"notes"`
 }
+
+// CaffeineSettings contiene los parámetros del modelo de metabolismo de la cafeína
+type CaffeineSettings struct {
+	HalfLifeHours     float64 `json:"half_life_hours"`    // vida media de eliminación
+	AbsorptionMinutes int     `json:"absorption_minutes"` // tiempo hasta el pico de concentración
+	Bedtime           string  `json:"bedtime"`            // hora objetivo de dormir (HH:MM)
+	BedtimeThreshold  float64 `json:"bedtime_threshold"`  // mg residuales tolerados al acostarse
+}
+
+// UpdateCaffeineSettingsInput representa los datos para actualizar los ajustes de cafeína
+type UpdateCaffeineSettingsInput struct {
+	HalfLifeHours     float64 `json:"half_life_hours"`
+	AbsorptionMinutes *int    `json:"absorption_minutes"` // Puntero para permitir 0 (absorción inmediata)
+	Bedtime           string  `json:"bedtime"`
+	BedtimeThreshold  float64 `json:"bedtime_threshold"`
+}
+
+// CaffeineLevelPoint representa la cafeína estimada en el organismo en un instante
+type CaffeineLevelPoint struct {
+	Time  time.Time `json:"time"`
+	Level float64   `json:"level"` // mg activos
+}
+
+// CaffeineCurve representa la evolución de la cafeína activa a lo largo de un día
+type CaffeineCurve struct {
+	Date              string               `json:"date"`
+	ResolutionMinutes int                  `json:"resolution_minutes"`
+	Settings          CaffeineSettings     `json:"settings"`
+	Points            []CaffeineLevelPoint `json:"points"`
+	PeakLevel         float64              `json:"peak_level"`
+	PeakTime          time.Time            `json:"peak_time"`
+}
+
+// CaffeineThresholdEstimate indica cuándo la cafeína activa bajará de un umbral
+type CaffeineThresholdEstimate struct {
+	Threshold    float64   `json:"threshold"`     // mg
+	CurrentLevel float64   `json:"current_level"` // mg activos ahora
+	BelowAt      time.Time `json:"below_at"`      // momento estimado en que bajará del umbral
+	MinutesUntil int       `json:"minutes_until"` // 0 si ya está por debajo
+}
+
+// Niveles de aviso por cafeína residual a la hora de dormir
+const (
+	CaffeineWarningNone     = "none"     // por debajo de la mitad del umbral
+	CaffeineWarningLow      = "low"      // entre la mitad y el umbral
+	CaffeineWarningHigh     = "high"     // por encima del umbral
+	CaffeineWarningCritical = "critical" // el doble del umbral o más
+)
+
+// BedtimeCaffeineProjection proyecta la cafeína residual a la hora de dormir
+type BedtimeCaffeineProjection struct {
+	Bedtime           time.Time `json:"bedtime"`             // próxima hora de dormir evaluada
+	Threshold         float64   `json:"threshold"`           // mg residuales tolerados
+	IntakeCaffeine    float64   `json:"intake_caffeine"`     // mg del consumo evaluado (0 si no hay)
+	ResidualWithout   float64   `json:"residual_without"`    // mg residuales sin el consumo evaluado
+	ResidualAtBedtime float64   `json:"residual_at_bedtime"` // mg residuales incluyendo el consumo evaluado
+	WarningLevel      string    `json:"warning_level"`       // none, low, high, critical
+	Message           string    `json:"message"`
+}
+
+// Límites de consumo de cafeína
+const (
+	CaffeineLimitDaily  = "daily"
+	CaffeineLimitWeekly = "weekly"
+	CaffeineLimitIntake = "intake"
+)
+
+// CaffeineLimits contiene los límites de consumo configurados (0 = sin límite)
+type CaffeineLimits struct {
+	DailyLimit  float64 `json:"daily_limit"`  // mg por día
+	IntakeLimit float64 `json:"intake_limit"` // mg por consumo individual
+	WeeklyLimit float64 `json:"weekly_limit"` // mg por semana ISO
+}
+
+// UpdateCaffeineLimitsInput representa los datos para actualizar los límites de cafeína
+type UpdateCaffeineLimitsInput struct {
+	DailyLimit  *float64 `json:"daily_limit"` // Punteros para permitir 0 (desactivar el límite)
+	IntakeLimit *float64 `json:"intake_limit"`
+	WeeklyLimit *float64 `json:"weekly_limit"`
+}
+
+// CaffeineBudget resume el consumo frente a los límites para una fecha
+type CaffeineBudget struct {
+	Date            string         `json:"date"`
+	Limits          CaffeineLimits `json:"limits"`
+	DailyTotal      float64        `json:"daily_total"`
+	DailyRemaining  float64        `json:"daily_remaining"` // negativo si se ha superado
+	WeeklyTotal     float64        `json:"weekly_total"`
+	WeeklyRemaining float64        `json:"weekly_remaining"`
+	WeekStart       string         `json:"week_start"`
+	WeekEnd         string         `json:"week_end"`
+	Exceeded        []string       `json:"exceeded"` // límites superados: daily, weekly
+}
+
+// CaffeineIntakeResult devuelve el consumo guardado junto con el presupuesto restante
+type CaffeineIntakeResult struct {
+	CaffeineIntake
+	Budget        CaffeineBudget `json:"budget"`
+	LimitsCrossed []string       `json:"limits_crossed"` // límites superados por este consumo
+}
+
+// CaffeineLimitEvent es el contenido del evento emitido al superar un límite
+type CaffeineLimitEvent struct {
+	Limit    string  `json:"limit"` // daily, weekly, intake
+	Value    float64 `json:"value"` // límite configurado en mg
+	Total    float64 `json:"total"` // mg consumidos en el período (o en el consumo)
+	Date     string  `json:"date"`
+	IntakeID int     `json:"intake_id"`
+}
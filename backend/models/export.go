@@ -0,0 +1,96 @@
+package models
+
+import "time"
+
+// ExportFormatVersion es la versión del formato de exportación. Cambios:
+//   - 2: registros de ánimo con hora (timestamp) o franja (slot), varios por día
+//   - 3: tipo de medición, unidad y objetivo de los hábitos; valor decimal de los registros
+//   - 4: hábitos a evitar (avoid) y sus recaídas (habit_slips)
+//   - 5: calendario de los hábitos (schedule)
+//   - 6: días justificados (excuses)
+//   - 7: historial de metas de los hábitos (habit_goal_versions)
+const ExportFormatVersion = 7
+
+// Dominios de datos que se pueden exportar
+const (
+	ExportDomainHabits   = "habits"
+	ExportDomainMood     = "mood"
+	ExportDomainCaffeine = "caffeine"
+)
+
+// Formatos de exportación admitidos
+const (
+	ExportFormatJSON = "json"
+	ExportFormatCSV  = "csv"
+)
+
+// ExportOptions representa los filtros y el destino de una exportación
+type ExportOptions struct {
+	Format    string   `json:"format"`     // json o csv (zip de archivos CSV)
+	Path      string   `json:"path"`       // ruta de destino; vacía para usar la carpeta de exportaciones
+	StartDate string   `json:"start_date"` // YYYY-MM-DD, opcional
+	EndDate   string   `json:"end_date"`   // YYYY-MM-DD, opcional
+	Domains   []string `json:"domains"`    // habits, mood, caffeine; vacío para exportar todo
+}
+
+// ExportDocument es el documento completo de exportación
+type ExportDocument struct {
+	FormatVersion     int                `json:"format_version"`
+	SchemaVersion     int                `json:"schema_version"`
+	ExportedAt        time.Time          `json:"exported_at"`
+	StartDate         string             `json:"start_date"`
+	EndDate           string             `json:"end_date"`
+	Domains           []string           `json:"domains"`
+	Habits            []Habit            `json:"habits"`
+	HabitGoalVersions []HabitGoalVersion `json:"habit_goal_versions"`
+	HabitLogs         []HabitLog         `json:"habit_logs"`
+	HabitSlips        []HabitSlip        `json:"habit_slips"`
+	Excuses           []Excuse           `json:"excuses"`
+	MoodEntries       []MoodEntry        `json:"mood_entries"`
+	CaffeineBeverages []CaffeineBeverage `json:"caffeine_beverages"`
+	CaffeineIntake    []CaffeineIntake   `json:"caffeine_intake"`
+}
+
+// ExportResult resume una exportación realizada
+type ExportResult struct {
+	Path   string         `json:"path"`
+	Format string         `json:"format"`
+	Counts map[string]int `json:"counts"` // registros exportados por tabla
+}
+
+// Estrategias para resolver conflictos al importar
+const (
+	ImportStrategySkip      = "skip"      // conservar el registro existente
+	ImportStrategyOverwrite = "overwrite" // reemplazar con el registro importado
+	ImportStrategyMerge     = "merge"     // combinar ambos registros
+)
+
+// ImportOptions representa el origen y el modo de una importación
+type ImportOptions struct {
+	Path     string `json:"path"`     // archivo .json o .zip generado por la exportación
+	Strategy string `json:"strategy"` // skip, overwrite o merge
+	DryRun   bool   `json:"dry_run"`  // calcular el informe sin guardar cambios
+}
+
+// ImportTableReport resume los cambios de una tabla durante la importación
+type ImportTableReport struct {
+	Inserted int `json:"inserted"`
+	Updated  int `json:"updated"`
+	Skipped  int `json:"skipped"`
+}
+
+// ImportConflict describe un registro importado que ya existía
+type ImportConflict struct {
+	Table  string `json:"table"`
+	Key    string `json:"key"`    // clave natural del registro (nombre, fecha, ...)
+	Action string `json:"action"` // skipped, updated, merged
+}
+
+// ImportReport resume el resultado de una importación
+type ImportReport struct {
+	DryRun    bool                         `json:"dry_run"`
+	Strategy  string                       `json:"strategy"`
+	Committed bool                         `json:"committed"`
+	Tables    map[string]ImportTableReport `json:"tables"`
+	Conflicts []ImportConflict             `json:"conflicts"`
+}
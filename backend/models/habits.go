@@ -2,17 +2,103 @@ package models
 
 import "time"
 
+// Frecuencias admitidas para un hábito
+const (
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+)
+
+// Tipos de calendario de un hábito. Los calendarios fijos (diario, días de la semana e
+// intervalo) dicen qué días toca; los de cuota (semanal y mensual) piden un número de días
+// cumplidos por semana ISO o por mes, el que indique Goal, y cualquier día sirve.
+const (
+	ScheduleDaily    = "daily"    // todos los días
+	ScheduleWeekdays = "weekdays" // días concretos de la semana
+	ScheduleInterval = "interval" // cada N días desde una fecha de referencia
+	ScheduleWeekly   = "weekly"   // X días por semana
+	ScheduleMonthly  = "monthly"  // X días al mes
+)
+
+// HabitSchedule es el calendario de un hábito: qué días toca cumplirlo
+type HabitSchedule struct {
+	Type     string `json:"type"`
+	Weekdays int    `json:"weekdays,omitempty"` // máscara de bits: 1<<time.Weekday (weekdays)
+	Interval int    `json:"interval,omitempty"` // cada cuántos días (interval)
+	Anchor   string `json:"anchor,omitempty"`   // primer día del intervalo, YYYY-MM-DD (interval)
+	Times    int    `json:"times,omitempty"`    // días por semana o por mes (weekly, monthly); es el Goal del hábito
+}
+
+// Tipos de medición de un hábito. Los hábitos cuantitativos (cantidad y duración) registran
+// un valor decimal por día y se cumplen al alcanzar el objetivo diario (Target).
+const (
+	MeasureBoolean  = "boolean"  // hecho o no hecho
+	MeasureCount    = "count"    // número de veces
+	MeasureQuantity = "quantity" // cantidad decimal en una unidad libre (L, km, páginas)
+	MeasureDuration = "duration" // minutos
+)
+
+// DurationUnit es la unidad de los hábitos de duración
+const DurationUnit = "min"
+
 // Habit representa un hábito que el usuario quiere seguir
 type Habit struct {
-	ID          int       `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Category    string    `json:"category"`
-	Frequency   string    `json:"frequency"` // daily, weekly, monthly
-	Goal        int       `json:"goal"`      // objetivo diario (veces)
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	Active      bool      `json:"active"`
+	ID          int           `json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Category    string        `json:"category"`
+	Frequency   string        `json:"frequency"` // daily, weekly, monthly (se deriva del calendario)
+	Goal        int           `json:"goal"`      // objetivo diario (veces), o días por semana o mes en las cuotas
+	Schedule    HabitSchedule `json:"schedule"`
+	Measure     string        `json:"measure"` // boolean, count, quantity, duration
+	Unit        string        `json:"unit"`    // unidad de los valores (L, km, min...)
+	Target      float64       `json:"target"`  // objetivo diario en la unidad del hábito
+	Avoid       bool          `json:"avoid"`   // hábito a evitar: se cumple cada día sin recaídas
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+	Active      bool          `json:"active"`
+}
+
+// Quantitative indica si el hábito se mide con un valor decimal frente a un objetivo diario
+func (h Habit) Quantitative() bool {
+	return h.Measure == MeasureQuantity || h.Measure == MeasureDuration
+}
+
+// HabitGoalVersion es la meta de un hábito vigente desde una fecha: frecuencia, objetivo,
+// calendario y objetivo diario de los cuantitativos. Cada día se evalúa con la versión más
+// reciente que ya estuviera en vigor; los días anteriores a la primera, con la primera.
+type HabitGoalVersion struct {
+	ID            int           `json:"id"`
+	HabitID       int           `json:"habit_id"`
+	EffectiveDate string        `json:"effective_date"` // YYYY-MM-DD
+	Frequency     string        `json:"frequency"`
+	Goal          int           `json:"goal"`
+	Schedule      HabitSchedule `json:"schedule"`
+	Target        float64       `json:"target"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+// WithGoal devuelve el hábito con la meta de una versión
+func (h Habit) WithGoal(v HabitGoalVersion) Habit {
+	h.Frequency = v.Frequency
+	h.Goal = v.Goal
+	h.Schedule = v.Schedule
+	h.Target = v.Target
+	return h
+}
+
+// MeetsGoal indica si lo registrado en un día cumple la meta del hábito: el objetivo diario en
+// los cuantitativos y el objetivo de veces en los de recuento. En los de sí/no no se deduce de
+// los valores, así que decided es false.
+func (h Habit) MeetsGoal(count int, value float64) (met bool, decided bool) {
+	switch {
+	case h.Quantitative():
+		return value >= h.Target, true
+	case h.Measure == MeasureCount:
+		return count >= h.Goal, true
+	default:
+		return false, false
+	}
 }
 
 // HabitLog representa un registro diario de un hábito
@@ -22,32 +108,145 @@ type HabitLog struct {
 	Date      time.Time `json:"date"`
 	Completed bool      `json:"completed"`
 	Count     int       `json:"count"` // número de veces completado
+	Value     float64   `json:"value"` // valor registrado en la unidad del hábito
 	Notes     string    `json:"notes"`
 }
 
 // NewHabitInput representa los datos de entrada para crear un nuevo hábito
 type NewHabitInput struct {
-	Name        string `json:"name" binding:"required"`
-	Description string `json:"description"`
-	Category    string `json:"category"`
-	Frequency   string `json:"frequency" binding:"required"`
-	Goal        int    `json:"goal"`
+	Name        string         `json:"name" binding:"required"`
+	Description string         `json:"description"`
+	Category    string         `json:"category"`
+	Frequency   string         `json:"frequency" binding:"required"`
+	Goal        int            `json:"goal"`
+	Schedule    *HabitSchedule `json:"schedule"` // si se omite, se deriva de la frecuencia
+	Measure     string         `json:"measure"`  // boolean por defecto
+	Unit        string         `json:"unit"`
+	Target      float64        `json:"target"`
+	Avoid       bool           `json:"avoid"`
 }
 
 // UpdateHabitInput representa los datos de entrada para actualizar un hábito
 type UpdateHabitInput struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Category    string `json:"category"`
-	Frequency   string `json:"frequency"`
-	Goal        int    `json:"goal"`
-	Active      *bool  `json:"active"` // Puntero para distinguir entre falso y no proporcionado
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Category    string         `json:"category"`
+	Frequency   string         `json:"frequency"`
+	Goal        int            `json:"goal"`
+	Schedule    *HabitSchedule `json:"schedule"`
+	Measure     string         `json:"measure"`
+	Unit        string         `json:"unit"`
+	Target      float64        `json:"target"`
+	Avoid       *bool          `json:"avoid"`
+	Active      *bool          `json:"active"` // Puntero para distinguir entre falso y no proporcionado
+
+	// Día desde el que rigen los cambios de frecuencia, objetivo, calendario u objetivo diario;
+	// hoy si se omite. Rige hasta el siguiente cambio de meta, si lo hay: los días anteriores y
+	// los cambios posteriores conservan su meta.
+	EffectiveDate string `json:"effective_date"`
 }
 
 // NewHabitLogInput representa los datos de entrada para registrar un hábito
 type NewHabitLogInput struct {
-	Date      string `json:"date" binding:"required"`
-	Completed bool   `json:"completed"`
-	Count     int    `json:"count"`
+	Date      string  `json:"date" binding:"required"`
+	Completed bool    `json:"completed"`
+	Count     int     `json:"count"`
+	Value     float64 `json:"value"` // valor en la unidad del hábito (hábitos cuantitativos)
+	Notes     string  `json:"notes"`
+}
+
+// HabitSlip es una recaída en un hábito a evitar. Puede haber varias en un mismo día.
+type HabitSlip struct {
+	ID        int       `json:"id"`
+	HabitID   int       `json:"habit_id"`
+	Timestamp time.Time `json:"timestamp"`
+	Date      string    `json:"date"` // día lógico de la recaída (YYYY-MM-DD)
+	Notes     string    `json:"notes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewHabitSlipInput representa los datos para registrar una recaída
+type NewHabitSlipInput struct {
+	Timestamp string `json:"timestamp"` // RFC 3339; el instante actual si se omite
+	Notes     string `json:"notes"`
+}
+
+// DueHabit es un hábito que toca en un día, con lo registrado ese día. En los calendarios
+// de cuota incluye el progreso del período.
+type DueHabit struct {
+	Habit        Habit     `json:"habit"`
+	Date         string    `json:"date"`
+	Completed    bool      `json:"completed"`               // cumplido ese día (sin recaídas, si es a evitar)
+	Log          *HabitLog `json:"log,omitempty"`           // registro del día, si existe
+	PeriodDone   int       `json:"period_done,omitempty"`   // días cumplidos en la semana o el mes
+	PeriodTarget int       `json:"period_target,omitempty"` // días que pide la cuota
+}
+
+// Motivos de un día justificado
+const (
+	ExcuseVacation = "vacation" // vacaciones o viaje
+	ExcuseSick     = "sick"     // enfermedad
+	ExcuseRest     = "rest"     // día de descanso
+	ExcuseOther    = "other"
+)
+
+// Excuse justifica un día o un rango de días, para un hábito o para todos. Los días justificados
+// no cuentan ni como cumplidos ni como fallos: no rompen las rachas ni bajan el cumplimiento.
+type Excuse struct {
+	ID        int       `json:"id"`
+	HabitID   *int      `json:"habit_id"`   // nil si justifica todos los hábitos
+	StartDate string    `json:"start_date"` // YYYY-MM-DD
+	EndDate   string    `json:"end_date"`   // YYYY-MM-DD, incluido
+	Reason    string    `json:"reason"`     // vacation, sick, rest, other
+	Notes     string    `json:"notes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewExcuseInput representa los datos para justificar días
+type NewExcuseInput struct {
+	HabitID   *int   `json:"habit_id"` // nil para todos los hábitos
+	StartDate string `json:"start_date" binding:"required"`
+	EndDate   string `json:"end_date"` // el mismo día de inicio si se omite
+	Reason    string `json:"reason"`   // other por defecto
 	Notes     string `json:"notes"`
 }
+
+// AllWeekdays es la máscara de días que incluye todos los días de la semana
+const AllWeekdays = 127
+
+// HabitReminder representa un recordatorio programado para un hábito
+type HabitReminder struct {
+	ID           int        `json:"id"`
+	HabitID      int        `json:"habit_id"`
+	Time         string     `json:"time"`     // hora local HH:MM
+	Weekdays     int        `json:"weekdays"` // máscara de bits: 1<<time.Weekday (domingo = bit 0)
+	Active       bool       `json:"active"`
+	SnoozedUntil *time.Time `json:"snoozed_until"`
+	DismissedOn  string     `json:"dismissed_on"`  // fecha en que se descartó (YYYY-MM-DD)
+	LastFiredOn  string     `json:"last_fired_on"` // última fecha en que se disparó (YYYY-MM-DD)
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// NewHabitReminderInput representa los datos para crear un recordatorio
+type NewHabitReminderInput struct {
+	Time     string `json:"time" binding:"required"`
+	Weekdays int    `json:"weekdays"` // 0 equivale a todos los días
+}
+
+// UpdateHabitReminderInput representa los datos para actualizar un recordatorio
+type UpdateHabitReminderInput struct {
+	Time     string `json:"time"`
+	Weekdays int    `json:"weekdays"`
+	Active   *bool  `json:"active"` // Puntero para distinguir entre falso y no proporcionado
+}
+
+// ReminderNotification es el contenido de un recordatorio disparado
+type ReminderNotification struct {
+	ReminderID int       `json:"reminder_id"`
+	HabitID    int       `json:"habit_id"`
+	HabitName  string    `json:"habit_name"`
+	Time       string    `json:"time"`
+	Date       string    `json:"date"`
+	FiredAt    time.Time `json:"fired_at"`
+	Snoozed    bool      `json:"snoozed"` // true si procede de un aplazamiento
+}
@@ -6,3 +6,8 @@ package models
 // - habits.go: Modelos relacionados con hábitos y su seguimiento
 // - mood.go: Modelos para el registro del estado de ánimo
 // - caffeine.go: Modelos para el seguimiento del consumo de cafeína
+// - stats.go: Resultados de estadísticas y análisis
+// - export.go: Formato de exportación e importación de datos
+// - backup.go: Copias de seguridad y restauración
+// - server.go: Configuración del servidor REST local
+// - time.go: Zona horaria y asignación de registros a días
@@ -2,23 +2,41 @@ package models
 
 import "time"
 
-// MoodEntry representa un registro del estado de ánimo
+// Franjas del día de un registro de estado de ánimo sin hora exacta
+const (
+	MoodSlotMorning   = "morning"
+	MoodSlotAfternoon = "afternoon"
+	MoodSlotEvening   = "evening"
+	MoodSlotNight     = "night"
+)
+
+// MoodSlots son las franjas del día admitidas, en orden
+var MoodSlots = []string{MoodSlotMorning, MoodSlotAfternoon, MoodSlotEvening, MoodSlotNight}
+
+// MoodEntry representa un registro del estado de ánimo. Un día puede tener varios: cada uno
+// lleva la hora exacta (Timestamp), una franja del día (Slot) o ninguna de las dos (registro
+// del día completo, como los anteriores a los registros intradía).
 type MoodEntry struct {
-	ID           int       `json:"id"`
-	Date         time.Time `json:"date"`
-	MoodScore    int       `json:"mood_score"`    // 1-10
-	EnergyLevel  int       `json:"energy_level"`  // 1-10
-	AnxietyLevel int       `json:"anxiety_level"` // 1-10
-	StressLevel  int       `json:"stress_level"`  // 1-10
-	SleepHours   float64   `json:"sleep_hours"`
-	Notes        string    `json:"notes"`
-	Tags         []string  `json:"tags"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID           int        `json:"id"`
+	Date         time.Time  `json:"date"`
+	Timestamp    *time.Time `json:"timestamp,omitempty"`
+	Slot         string     `json:"slot,omitempty"`
+	MoodScore    int        `json:"mood_score"`    // 1-10
+	EnergyLevel  int        `json:"energy_level"`  // 1-10
+	AnxietyLevel int        `json:"anxiety_level"` // 1-10
+	StressLevel  int        `json:"stress_level"`  // 1-10
+	SleepHours   float64    `json:"sleep_hours"`
+	Notes        string     `json:"notes"`
+	Tags         []string   `json:"tags"`
+	CreatedAt    time.Time  `json:"created_at"`
 }
 
-// NewMoodEntryInput representa los datos de entrada para crear un registro de estado de ánimo
+// NewMoodEntryInput representa los datos de entrada para crear un registro de estado de ánimo.
+// Si se indica Timestamp, la fecha es la de su día lógico.
 type NewMoodEntryInput struct {
 	Date         string   `json:"date" binding:"required"`
+	Timestamp    string   `json:"timestamp"` // RFC 3339, opcional
+	Slot         string   `json:"slot"`      // morning, afternoon, evening o night, opcional
 	MoodScore    int      `json:"mood_score" binding:"required,min=1,max=10"`
 	EnergyLevel  int      `json:"energy_level" binding:"min=1,max=10"`
 	AnxietyLevel int      `json:"anxiety_level" binding:"min=1,max=10"`
@@ -38,3 +56,25 @@ type UpdateMoodEntryInput struct {
 	Notes        string   `json:"notes"`
 	Tags         []string `json:"tags"`
 }
+
+// MoodAggregate resume los valores de una dimensión del estado de ánimo en un día
+type MoodAggregate struct {
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Last  float64 `json:"last"` // el del último registro del día
+}
+
+// DailyMood agrega los registros de estado de ánimo de un día. Las dimensiones opcionales son
+// nil si ningún registro del día las incluye. Las estadísticas y correlaciones usan la media.
+type DailyMood struct {
+	Date         string         `json:"date"`
+	Entries      int            `json:"entries"`
+	MoodScore    MoodAggregate  `json:"mood_score"`
+	EnergyLevel  *MoodAggregate `json:"energy_level,omitempty"`
+	AnxietyLevel *MoodAggregate `json:"anxiety_level,omitempty"`
+	StressLevel  *MoodAggregate `json:"stress_level,omitempty"`
+	SleepHours   float64        `json:"sleep_hours"` // el último valor registrado en el día; 0 = sin registrar
+	Tags         []string       `json:"tags"`        // sin repetir, en orden de aparición
+}
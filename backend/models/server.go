@@ -0,0 +1,18 @@
+package models
+
+// DefaultServerAddress es la dirección predeterminada del servidor REST (solo accesible desde el equipo)
+const DefaultServerAddress = "127.0.0.1:8765"
+
+// ServerSettings representa la configuración del servidor REST local
+type ServerSettings struct {
+	Enabled bool   `json:"enabled"` // arrancar el servidor al iniciar la aplicación
+	Address string `json:"address"` // host:puerto; usar 0.0.0.0 para aceptar conexiones de la red local
+	Token   string `json:"token"`   // token que deben enviar los clientes en la cabecera Authorization
+}
+
+// UpdateServerSettingsInput representa los datos para actualizar la configuración del servidor REST
+type UpdateServerSettingsInput struct {
+	Enabled         *bool  `json:"enabled"` // Puntero para distinguir entre falso y no proporcionado
+	Address         string `json:"address"`
+	RegenerateToken bool   `json:"regenerate_token"` // invalidar el token actual y crear uno nuevo
+}
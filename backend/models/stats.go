@@ -0,0 +1,268 @@
+package models
+
+import "time"
+
+// NoDataMessage es el mensaje de las estadísticas de un período sin registros
+const NoDataMessage = "No hay datos para el período solicitado"
+
+// HabitStats contiene las estadísticas de un hábito en un período. El cumplimiento y las
+// rachas se miden en la unidad del hábito (día, semana o mes); los totales y promedios de
+// valores, en la unidad de medida del hábito (MeasureUnit).
+type HabitStats struct {
+	HabitID          int     `json:"habit_id"`
+	HabitName        string  `json:"habit_name"`
+	Period           string  `json:"period"`
+	Frequency        string  `json:"frequency"`
+	Goal             int     `json:"goal"`
+	Unit             string  `json:"unit"` // day, week, month
+	StartDate        string  `json:"start_date"`
+	EndDate          string  `json:"end_date"`
+	TotalDays        int     `json:"total_days"` // días con registro
+	CompletedDays    int     `json:"completed_days"`
+	TotalPeriods     int     `json:"total_periods"`
+	CompletedPeriods int     `json:"completed_periods"`
+	CompletionRate   float64 `json:"completion_rate"` // porcentaje de períodos cumplidos
+	TotalCount       int     `json:"total_count"`
+	AverageCount     float64 `json:"average_count"`
+	Measure          string  `json:"measure"`
+	MeasureUnit      string  `json:"measure_unit"`
+	Target           float64 `json:"target"` // objetivo diario (solo hábitos cuantitativos)
+	TotalValue       float64 `json:"total_value"`
+	AverageValue     float64 `json:"average_value"`   // por día con registro
+	DailyAverage     float64 `json:"daily_average"`   // por día transcurrido del período
+	TargetMetDays    int     `json:"target_met_days"` // días en que se alcanzó el objetivo
+	GoalAttainment   float64 `json:"goal_attainment"` // porcentaje del objetivo acumulado del período
+	MaxStreak        int     `json:"max_streak"`
+	CurrentStreak    int     `json:"current_streak"`
+	ExcusedDays      int     `json:"excused_days"` // días justificados del período, fuera de la evaluación
+
+	// Hábitos a evitar: un día cumplido es un día sin recaídas, y las rachas son de días limpios
+	Avoid             bool            `json:"avoid"`
+	TotalSlips        int             `json:"total_slips,omitempty"`
+	SlipDays          int             `json:"slip_days,omitempty"`
+	CleanDays         int             `json:"clean_days,omitempty"`           // días sin recaídas, sin contar los justificados
+	LastSlipAt        *time.Time      `json:"last_slip_at,omitempty"`         // última recaída, aunque sea anterior al período
+	DaysSinceLastSlip *int            `json:"days_since_last_slip,omitempty"` // nil si nunca ha recaído
+	SlipTrend         []SlipFrequency `json:"slip_trend,omitempty"`           // recaídas por semana del período
+	SlipTrendSlope    float64         `json:"slip_trend_slope,omitempty"`     // variación semanal de recaídas por semana (negativa = mejora)
+}
+
+// SlipFrequency cuenta las recaídas de una semana ISO. Las semanas parciales del principio y
+// del final del período tienen menos días: Rate normaliza a recaídas por 7 días.
+type SlipFrequency struct {
+	Period    string  `json:"period"` // 2025-W03
+	StartDate string  `json:"start_date"`
+	Days      int     `json:"days"`
+	Slips     int     `json:"slips"`
+	SlipDays  int     `json:"slip_days"`
+	Rate      float64 `json:"rate"`
+}
+
+// TagCount es el número de registros de estado de ánimo con una etiqueta
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// MoodStats contiene las estadísticas del estado de ánimo en un período. Si no hay
+// registros, HasData es false, Message lo explica y los promedios valen 0.
+type MoodStats struct {
+	Period          string     `json:"period"`
+	StartDate       string     `json:"start_date"`
+	EndDate         string     `json:"end_date"`
+	HasData         bool       `json:"has_data"`
+	Message         string     `json:"message,omitempty"`
+	TotalEntries    int        `json:"total_entries"`  // registros, contando todos los del día
+	TotalDays       int        `json:"total_days"`     // días con al menos un registro
+	AvgMoodScore    float64    `json:"avg_mood_score"` // promedios de las medias diarias
+	AvgEnergyLevel  float64    `json:"avg_energy_level"`
+	AvgAnxietyLevel float64    `json:"avg_anxiety_level"`
+	AvgStressLevel  float64    `json:"avg_stress_level"`
+	AvgSleepHours   float64    `json:"avg_sleep_hours"` // solo días con horas de sueño registradas
+	CommonTags      []TagCount `json:"common_tags"`     // las 5 más frecuentes
+}
+
+// BeverageUsage resume el consumo de una bebida en un período
+type BeverageUsage struct {
+	Name            string  `json:"name"`
+	Count           int     `json:"count"`
+	TotalCaffeine   float64 `json:"total_caffeine"`
+	AverageCaffeine float64 `json:"average_caffeine"`
+	PercentOfTotal  float64 `json:"percent_of_total"`
+}
+
+// CaffeineStats contiene las estadísticas del consumo de cafeína en un período. Si no hay
+// consumos, HasData es false y Message lo explica.
+type CaffeineStats struct {
+	Period               string          `json:"period"`
+	StartDate            string          `json:"start_date"`
+	EndDate              string          `json:"end_date"`
+	HasData              bool            `json:"has_data"`
+	Message              string          `json:"message,omitempty"`
+	TotalIntakes         int             `json:"total_intakes"`
+	UniqueDays           int             `json:"unique_days"`
+	TotalCaffeine        float64         `json:"total_caffeine"`
+	AvgCaffeinePerIntake float64         `json:"avg_caffeine_per_intake"`
+	AvgDailyCaffeine     float64         `json:"avg_daily_caffeine"` // sobre los días con consumo
+	MaxDailyCaffeine     float64         `json:"max_daily_caffeine"`
+	MaxDailyDate         string          `json:"max_daily_date"`
+	CommonBeverages      []BeverageUsage `json:"common_beverages"` // las 5 más frecuentes
+}
+
+// CaffeineGroupStats promedia el estado de ánimo de los días de un tercil de consumo
+type CaffeineGroupStats struct {
+	Count           int     `json:"count"`
+	AvgCaffeine     float64 `json:"avg_caffeine"`
+	AvgMoodScore    float64 `json:"avg_mood_score"`
+	AvgEnergyLevel  float64 `json:"avg_energy_level"`
+	AvgAnxietyLevel float64 `json:"avg_anxiety_level"`
+	AvgStressLevel  float64 `json:"avg_stress_level"`
+	AvgSleepHours   float64 `json:"avg_sleep_hours"`
+}
+
+// MinCorrelationDays es el número mínimo de días con cafeína y con estado de ánimo para
+// analizar correlaciones
+const MinCorrelationDays = 10
+
+// CorrelationStats relaciona el consumo diario de cafeína con el estado de ánimo. Si no hay
+// suficientes días, HasData es false, Message lo explica y solo se rellenan los recuentos.
+type CorrelationStats struct {
+	Period         string              `json:"period"`
+	WindowDays     int                 `json:"window_days"`
+	StartDate      string              `json:"start_date"`
+	EndDate        string              `json:"end_date"`
+	HasData        bool                `json:"has_data"`
+	Message        string              `json:"message,omitempty"`
+	CaffeineDays   int                 `json:"caffeine_days"`
+	MoodDays       int                 `json:"mood_days"`
+	Correlations   []CorrelationResult `json:"correlations"`
+	ThresholdLow   float64             `json:"threshold_low"`
+	ThresholdHigh  float64             `json:"threshold_high"`
+	LowCaffeine    CaffeineGroupStats  `json:"low_caffeine"`
+	MediumCaffeine CaffeineGroupStats  `json:"medium_caffeine"`
+	HighCaffeine   CaffeineGroupStats  `json:"high_caffeine"`
+}
+
+// StreakRun representa una racha de períodos consecutivos cumplidos
+type StreakRun struct {
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Length    int    `json:"length"` // número de períodos (días, semanas o meses)
+}
+
+// HabitStreaks resume las rachas de un hábito a lo largo de todo su historial
+type HabitStreaks struct {
+	HabitID       int         `json:"habit_id"`
+	HabitName     string      `json:"habit_name"`
+	Unit          string      `json:"unit"` // day, week, month
+	CurrentStreak int         `json:"current_streak"`
+	LongestStreak int         `json:"longest_streak"`
+	LongestStart  string      `json:"longest_start"`
+	LongestEnd    string      `json:"longest_end"`
+	Runs          []StreakRun `json:"runs"`
+}
+
+// DefaultCorrelationWindowDays es la ventana predeterminada del análisis de correlaciones
+const DefaultCorrelationWindowDays = 90
+
+// CorrelationResult contiene los coeficientes de correlación entre dos series diarias.
+// Los coeficientes son nulos si no hay suficientes datos o una de las series es constante.
+type CorrelationResult struct {
+	Dimension  string   `json:"dimension"`   // mood_score, energy_level, anxiety_level, stress_level, sleep_hours
+	SampleSize int      `json:"sample_size"` // número de días con ambos valores
+	Pearson    *float64 `json:"pearson"`
+	PearsonP   *float64 `json:"pearson_p"` // valor p bilateral
+	Spearman   *float64 `json:"spearman"`
+	SpearmanP  *float64 `json:"spearman_p"`
+}
+
+// Niveles de confianza de una diferencia entre grupos
+const (
+	ConfidenceHigh         = "high"         // p < 0.01
+	ConfidenceMedium       = "medium"       // p < 0.05
+	ConfidenceLow          = "low"          // p >= 0.05
+	ConfidenceInsufficient = "insufficient" // menos de 3 días en alguno de los grupos
+)
+
+// HabitMoodEffect compara una dimensión del estado de ánimo entre los días en que se
+// completó un hábito y los días en que no
+type HabitMoodEffect struct {
+	Dimension        string  `json:"dimension"`
+	CompletedDays    int     `json:"completed_days"`
+	NotCompletedDays int     `json:"not_completed_days"`
+	CompletedMean    float64 `json:"completed_mean"`
+	NotCompletedMean float64 `json:"not_completed_mean"`
+	MeanDifference   float64 `json:"mean_difference"` // completado - no completado
+	EffectSize       float64 `json:"effect_size"`     // d de Cohen
+	CILow            float64 `json:"ci_low"`          // intervalo de confianza del 95% de la diferencia
+	CIHigh           float64 `json:"ci_high"`
+	PValue           float64 `json:"p_value"` // prueba t de Welch bilateral
+	Confidence       string  `json:"confidence"`
+}
+
+// HabitMoodCorrelation resume el efecto de un hábito sobre el estado de ánimo
+type HabitMoodCorrelation struct {
+	HabitID   int               `json:"habit_id"`
+	HabitName string            `json:"habit_name"`
+	Impact    float64           `json:"impact"` // mayor |d de Cohen| entre las dimensiones con datos suficientes
+	Effects   []HabitMoodEffect `json:"effects"`
+}
+
+// HabitMoodAnalysis contiene el efecto de cada hábito activo, ordenado por impacto
+type HabitMoodAnalysis struct {
+	WindowDays int                    `json:"window_days"`
+	StartDate  string                 `json:"start_date"`
+	EndDate    string                 `json:"end_date"`
+	Habits     []HabitMoodCorrelation `json:"habits"`
+}
+
+// Valores por defecto del análisis de correlaciones con desfase
+const (
+	DefaultCorrelationMaxLag = 3  // días de desfase analizados por defecto
+	MaxCorrelationLag        = 14 // desfase máximo admitido
+	DefaultCaffeineCutoff    = 14 // hora a partir de la cual la cafeína se considera tardía
+)
+
+// LaggedCorrelationOptions configura el análisis de correlaciones con desfase. Los campos
+// nulos o a cero toman los valores por defecto.
+type LaggedCorrelationOptions struct {
+	WindowDays int  `json:"window_days"`
+	MaxLag     *int `json:"max_lag"`     // 0..MaxCorrelationLag
+	CutoffHour *int `json:"cutoff_hour"` // 0..23
+}
+
+// LaggedCorrelation es la correlación entre una variable y un resultado separados por Lag días
+type LaggedCorrelation struct {
+	Lag int `json:"lag"`
+	CorrelationResult
+}
+
+// LaggedCorrelationRow contiene las correlaciones de una variable con un resultado para
+// cada desfase, del 0 al máximo
+type LaggedCorrelationRow struct {
+	Input      string              `json:"input"` // caffeine_total, caffeine_after_cutoff o habit:<id>
+	InputLabel string              `json:"input_label"`
+	Outcome    string              `json:"outcome"` // dimensión del estado de ánimo
+	Lags       []LaggedCorrelation `json:"lags"`
+}
+
+// LaggedCorrelationMatrix es la matriz de correlaciones entre variables del día D y el
+// estado de ánimo del día D+desfase
+type LaggedCorrelationMatrix struct {
+	WindowDays int                    `json:"window_days"`
+	MaxLag     int                    `json:"max_lag"`
+	CutoffHour int                    `json:"cutoff_hour"`
+	StartDate  string                 `json:"start_date"`
+	EndDate    string                 `json:"end_date"`
+	Rows       []LaggedCorrelationRow `json:"rows"`
+}
+
+// DefaultStatsPeriod es el período de las estadísticas cuando no se indica ninguno
+const DefaultStatsPeriod = "month"
+
+// DateRange es un período de estadísticas ya resuelto a fechas (YYYY-MM-DD, ambas incluidas)
+type DateRange struct {
+	Period    string `json:"period"` // especificación normalizada (month, 2025-Q3, 2025-01-01..2025-01-31...)
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+}
@@ -0,0 +1,19 @@
+package models
+
+// MaxRolloverHour es la hora de cambio de día más tardía admitida (exclusiva)
+const MaxRolloverHour = 12
+
+// TimeSettings representa la zona horaria y la hora de cambio de día con las que se asignan
+// los registros a días. Con RolloverHour = 4, lo que se registra a las 02:30 cuenta para el
+// día anterior.
+type TimeSettings struct {
+	Timezone          string `json:"timezone"`           // zona IANA (Europe/Madrid); vacío = la del sistema
+	EffectiveTimezone string `json:"effective_timezone"` // zona que se está usando realmente
+	RolloverHour      int    `json:"rollover_hour"`      // hora local a la que empieza el día (0..MaxRolloverHour-1)
+}
+
+// UpdateTimeSettingsInput representa los datos para actualizar la zona horaria y el cambio de día
+type UpdateTimeSettingsInput struct {
+	Timezone     *string `json:"timezone"` // Puntero para distinguir entre vacío (sistema) y no proporcionado
+	RolloverHour *int    `json:"rollover_hour"`
+}
@@ -0,0 +1,228 @@
+// Package period convierte las especificaciones de período de las estadísticas en rangos de
+// fechas. Todas las estadísticas resuelven su período aquí.
+//
+// Especificaciones admitidas (sin distinguir mayúsculas):
+//
+//	week, month, year              ventana móvil hasta hoy, incluido (igual que 7d, 1m y 1y)
+//	30d, 12w, 6m, 2y               ventana móvil de N días, semanas, meses o años, hoy incluido
+//	today, yesterday               un día
+//	this-week, last-week           semana ISO (de lunes a domingo)
+//	this-month, last-month         mes natural
+//	this-quarter, last-quarter     trimestre natural
+//	this-year, last-year           año natural
+//	2025, 2025-Q3, Q3, 2025-03     año, trimestre (Q3 es del año en curso), mes
+//	2025-W07, 2025-03-14           semana ISO, día
+//	2025-01-01..2025-03-31         rango explícito, ambos extremos incluidos
+//
+// El final del rango nunca pasa de hoy: los días futuros no cuentan como incumplidos.
+package period
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+const dateLayout = "2006-01-02"
+
+var (
+	rollingPattern = regexp.MustCompile(`^(\d+)([dwmy])$`)
+	quarterPattern = regexp.MustCompile(`^(?:(\d{4})-)?q([1-4])$`)
+	weekPattern    = regexp.MustCompile(`^(\d{4})-w(\d{2})$`)
+	yearPattern    = regexp.MustCompile(`^\d{4}$`)
+	monthPattern   = regexp.MustCompile(`^\d{4}-\d{2}$`)
+)
+
+// Resolve convierte una especificación de período en un rango de fechas relativo a now.
+// Una especificación vacía equivale a models.DefaultStatsPeriod.
+func Resolve(spec string, now time.Time) (models.DateRange, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		spec = models.DefaultStatsPeriod
+	}
+
+	start, end, err := bounds(strings.ToLower(spec), today(now))
+	if err != nil {
+		return models.DateRange{}, err
+	}
+
+	return build(spec, start, end, today(now))
+}
+
+// LastDays devuelve la ventana móvil de los últimos days días, hoy incluido
+func LastDays(days int, now time.Time) models.DateRange {
+	end := today(now)
+	start := end.AddDate(0, 0, -(days - 1))
+
+	return models.DateRange{
+		Period:    fmt.Sprintf("%dd", days),
+		StartDate: start.Format(dateLayout),
+		EndDate:   end.Format(dateLayout),
+	}
+}
+
+// bounds calcula el primer y el último día de una especificación en minúsculas
+func bounds(spec string, today time.Time) (time.Time, time.Time, error) {
+	switch spec {
+	case "week":
+		return today.AddDate(0, 0, -6), today, nil
+	case "month":
+		return today.AddDate(0, -1, 1), today, nil
+	case "year":
+		return today.AddDate(-1, 0, 1), today, nil
+	case "today":
+		return today, today, nil
+	case "yesterday":
+		day := today.AddDate(0, 0, -1)
+		return day, day, nil
+	case "this-week", "last-week":
+		start := weekStart(today)
+		if spec == "last-week" {
+			start = start.AddDate(0, 0, -7)
+		}
+		return start, start.AddDate(0, 0, 6), nil
+	case "this-month", "last-month":
+		start := date(today.Year(), today.Month(), 1, today.Location())
+		if spec == "last-month" {
+			start = start.AddDate(0, -1, 0)
+		}
+		return start, start.AddDate(0, 1, -1), nil
+	case "this-quarter", "last-quarter":
+		start := quarterStart(today.Year(), (int(today.Month())-1)/3+1, today.Location())
+		if spec == "last-quarter" {
+			start = start.AddDate(0, -3, 0)
+		}
+		return start, start.AddDate(0, 3, -1), nil
+	case "this-year", "last-year":
+		year := today.Year()
+		if spec == "last-year" {
+			year--
+		}
+		start := date(year, time.January, 1, today.Location())
+		return start, start.AddDate(1, 0, -1), nil
+	}
+
+	if from, to, ok := strings.Cut(spec, ".."); ok {
+		start, err := parseDate(from, today.Location())
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		end, err := parseDate(to, today.Location())
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		return start, end, nil
+	}
+
+	if m := rollingPattern.FindStringSubmatch(spec); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n <= 0 || n > 3650 {
+			return time.Time{}, time.Time{}, fmt.Errorf("ventana inválida: %s", spec)
+		}
+		// La ventana incluye hoy: 30d son 30 días
+		switch m[2] {
+		case "d":
+			return today.AddDate(0, 0, -n+1), today, nil
+		case "w":
+			return today.AddDate(0, 0, -7*n+1), today, nil
+		case "m":
+			return today.AddDate(0, -n, 1), today, nil
+		default:
+			return today.AddDate(-n, 0, 1), today, nil
+		}
+	}
+
+	if m := quarterPattern.FindStringSubmatch(spec); m != nil {
+		year := today.Year()
+		if m[1] != "" {
+			year, _ = strconv.Atoi(m[1])
+		}
+		quarter, _ := strconv.Atoi(m[2])
+		start := quarterStart(year, quarter, today.Location())
+		return start, start.AddDate(0, 3, -1), nil
+	}
+
+	if m := weekPattern.FindStringSubmatch(spec); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		week, _ := strconv.Atoi(m[2])
+
+		// El 4 de enero siempre cae en la semana 1 ISO
+		start := weekStart(date(year, time.January, 4, today.Location())).AddDate(0, 0, 7*(week-1))
+		if _, w := start.ISOWeek(); week < 1 || w != week {
+			return time.Time{}, time.Time{}, fmt.Errorf("semana inválida: %s", spec)
+		}
+		return start, start.AddDate(0, 0, 6), nil
+	}
+
+	if yearPattern.MatchString(spec) {
+		year, _ := strconv.Atoi(spec)
+		start := date(year, time.January, 1, today.Location())
+		return start, start.AddDate(1, 0, -1), nil
+	}
+
+	if monthPattern.MatchString(spec) {
+		start, err := time.ParseInLocation("2006-01", spec, today.Location())
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("mes inválido: %s", spec)
+		}
+		return start, start.AddDate(0, 1, -1), nil
+	}
+
+	if day, err := parseDate(spec, today.Location()); err == nil {
+		return day, day, nil
+	}
+
+	return time.Time{}, time.Time{}, fmt.Errorf("período no reconocido: %s", spec)
+}
+
+// build valida el rango, recorta el final a hoy y lo convierte en un DateRange
+func build(spec string, start, end, today time.Time) (models.DateRange, error) {
+	if end.Before(start) {
+		return models.DateRange{}, errors.New("la fecha de fin es anterior a la de inicio")
+	}
+
+	if start.After(today) {
+		return models.DateRange{}, fmt.Errorf("el período %s todavía no ha empezado", spec)
+	}
+
+	if end.After(today) {
+		end = today
+	}
+
+	return models.DateRange{
+		Period:    spec,
+		StartDate: start.Format(dateLayout),
+		EndDate:   end.Format(dateLayout),
+	}, nil
+}
+
+// today devuelve el día de now a medianoche, en la zona horaria de now
+func today(now time.Time) time.Time {
+	return date(now.Year(), now.Month(), now.Day(), now.Location())
+}
+
+func date(year int, month time.Month, day int, loc *time.Location) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
+
+// weekStart devuelve el lunes de la semana ISO del día indicado
+func weekStart(day time.Time) time.Time {
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+func quarterStart(year, quarter int, loc *time.Location) time.Time {
+	return date(year, time.Month(3*(quarter-1)+1), 1, loc)
+}
+
+func parseDate(value string, loc *time.Location) (time.Time, error) {
+	day, err := time.ParseInLocation(dateLayout, strings.TrimSpace(value), loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("fecha inválida: %s (usa YYYY-MM-DD)", value)
+	}
+	return day, nil
+}
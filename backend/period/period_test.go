@@ -0,0 +1,97 @@
+package period
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolve(t *testing.T) {
+	// Domingo 18 de octubre de 2026, a media tarde
+	now := time.Date(2026, time.October, 18, 15, 30, 0, 0, time.UTC)
+	january := time.Date(2026, time.January, 15, 9, 0, 0, 0, time.UTC)
+	nextYear := time.Date(2027, time.February, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		spec    string
+		now     time.Time
+		start   string
+		end     string
+		wantErr bool
+	}{
+		{name: "por defecto", spec: "", now: now, start: "2026-09-19", end: "2026-10-18"},
+		{name: "semana móvil", spec: "week", now: now, start: "2026-10-12", end: "2026-10-18"},
+		{name: "mes móvil", spec: "month", now: now, start: "2026-09-19", end: "2026-10-18"},
+		{name: "año móvil", spec: "year", now: now, start: "2025-10-19", end: "2026-10-18"},
+		{name: "días incluyen hoy", spec: "30d", now: now, start: "2026-09-19", end: "2026-10-18"},
+		{name: "semanas incluyen hoy", spec: "12w", now: now, start: "2026-07-27", end: "2026-10-18"},
+		{name: "meses incluyen hoy", spec: "1m", now: now, start: "2026-09-19", end: "2026-10-18"},
+		{name: "ventana vacía", spec: "0d", now: now, wantErr: true},
+		{name: "hoy", spec: "today", now: now, start: "2026-10-18", end: "2026-10-18"},
+		{name: "ayer", spec: "yesterday", now: now, start: "2026-10-17", end: "2026-10-17"},
+		{name: "semana en curso recortada", spec: "this-week", now: now, start: "2026-10-12", end: "2026-10-18"},
+		{name: "semana pasada", spec: "last-week", now: now, start: "2026-10-05", end: "2026-10-11"},
+		{name: "mes pasado", spec: "last-month", now: now, start: "2026-09-01", end: "2026-09-30"},
+		{name: "trimestre en curso recortado", spec: "this-quarter", now: now, start: "2026-10-01", end: "2026-10-18"},
+		{name: "trimestre pasado en enero", spec: "last-quarter", now: january, start: "2025-10-01", end: "2025-12-31"},
+		{name: "año pasado en enero", spec: "last-year", now: january, start: "2025-01-01", end: "2025-12-31"},
+		{name: "trimestre sin año", spec: "Q3", now: now, start: "2026-07-01", end: "2026-09-30"},
+		{name: "trimestre sin año en curso", spec: "q4", now: now, start: "2026-10-01", end: "2026-10-18"},
+		{name: "primer trimestre sin año", spec: "Q1", now: now, start: "2026-01-01", end: "2026-03-31"},
+		{name: "trimestre con año", spec: "2025-Q2", now: now, start: "2025-04-01", end: "2025-06-30"},
+		{name: "año", spec: "2025", now: now, start: "2025-01-01", end: "2025-12-31"},
+		{name: "mes", spec: "2025-02", now: now, start: "2025-02-01", end: "2025-02-28"},
+		{name: "día", spec: "2025-03-14", now: now, start: "2025-03-14", end: "2025-03-14"},
+		{name: "semana 1 empieza el año anterior", spec: "2025-W01", now: now, start: "2024-12-30", end: "2025-01-05"},
+		{name: "semana 53 de un año de 52", spec: "2025-W53", now: now, wantErr: true},
+		{name: "semana 53 todavía futura", spec: "2026-W53", now: now, wantErr: true},
+		{name: "semana 53 de un año de 53", spec: "2026-W53", now: nextYear, start: "2026-12-28", end: "2027-01-03"},
+		{name: "semana 0", spec: "2026-W00", now: now, wantErr: true},
+		{name: "rango explícito", spec: "2025-01-01..2025-03-31", now: now, start: "2025-01-01", end: "2025-03-31"},
+		{name: "rango recortado a hoy", spec: "2026-10-01..2026-12-31", now: now, start: "2026-10-01", end: "2026-10-18"},
+		{name: "rango invertido", spec: "2025-03-31..2025-01-01", now: now, wantErr: true},
+		{name: "rango con fecha inválida", spec: "2025-01-01..ayer", now: now, wantErr: true},
+		{name: "día futuro", spec: "2026-10-19", now: now, wantErr: true},
+		{name: "mes futuro", spec: "2026-11", now: now, wantErr: true},
+		{name: "rango futuro", spec: "2026-11-01..2026-11-30", now: now, wantErr: true},
+		{name: "desconocido", spec: "fortnight", now: now, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resolve(tt.spec, tt.now)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Resolve(%q) = %+v, se esperaba un error", tt.spec, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve(%q): %v", tt.spec, err)
+			}
+			if got.StartDate != tt.start || got.EndDate != tt.end {
+				t.Errorf("Resolve(%q) = %s..%s, se esperaba %s..%s", tt.spec, got.StartDate, got.EndDate, tt.start, tt.end)
+			}
+		})
+	}
+}
+
+func TestLastDays(t *testing.T) {
+	now := time.Date(2026, time.October, 18, 23, 59, 0, 0, time.UTC)
+
+	tests := []struct {
+		days  int
+		start string
+	}{
+		{days: 1, start: "2026-10-18"},
+		{days: 7, start: "2026-10-12"},
+		{days: 90, start: "2026-07-21"},
+	}
+
+	for _, tt := range tests {
+		got := LastDays(tt.days, now)
+		if got.StartDate != tt.start || got.EndDate != "2026-10-18" {
+			t.Errorf("LastDays(%d) = %s..%s, se esperaba %s..2026-10-18", tt.days, got.StartDate, got.EndDate, tt.start)
+		}
+	}
+}
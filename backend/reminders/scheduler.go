@@ -0,0 +1,206 @@
+// Package reminders contiene el planificador que dispara los recordatorios de hábitos
+package reminders
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/database"
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+// DefaultInterval es la frecuencia con la que se revisan los recordatorios
+const DefaultInterval = 30 * time.Second
+
+// Notifier entrega un recordatorio al usuario (evento de Wails, notificación del sistema, etc.)
+type Notifier interface {
+	Notify(notification models.ReminderNotification) error
+}
+
+// NotifierFunc permite usar una función como Notifier
+type NotifierFunc func(notification models.ReminderNotification) error
+
+// Notify llama a la función
+func (f NotifierFunc) Notify(notification models.ReminderNotification) error {
+	return f(notification)
+}
+
+// Scheduler revisa periódicamente los recordatorios y los dispara cuando corresponde
+type Scheduler struct {
+	Repo      database.Repository
+	Notifiers []Notifier
+	Interval  time.Duration
+	Now       func() time.Time // Reemplazable para pruebas
+
+	mu      sync.Mutex
+	stop    chan struct{}
+	done    chan struct{}
+	running bool
+}
+
+// NewScheduler crea un nuevo planificador de recordatorios
+func NewScheduler(repo database.Repository, notifiers ...Notifier) *Scheduler {
+	return &Scheduler{
+		Repo:      repo,
+		Notifiers: notifiers,
+		Interval:  DefaultInterval,
+		Now:       repo.Now,
+	}
+}
+
+// Start arranca la goroutine del planificador. Llamarlo varias veces no tiene efecto.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return
+	}
+
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	s.running = true
+
+	go s.loop(s.stop, s.done)
+}
+
+// Stop detiene el planificador y espera a que termine la revisión en curso
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	close(s.stop)
+	done := s.done
+	s.running = false
+	s.mu.Unlock()
+
+	<-done
+}
+
+// loop ejecuta las revisiones hasta que se cierra el canal stop
+func (s *Scheduler) loop(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	s.Check()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.Check()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Check dispara los recordatorios pendientes en este momento
+func (s *Scheduler) Check() {
+	now := s.Now()
+	today := s.Repo.DayOf(now)
+
+	reminders, err := s.Repo.GetActiveHabitReminders()
+	if err != nil {
+		log.Printf("Error al obtener recordatorios: %v", err)
+		return
+	}
+
+	for _, reminder := range reminders {
+		snoozed, due := isDue(reminder, now, s.Repo.DayOf)
+		if !due {
+			continue
+		}
+
+		// Solo se recuerdan hábitos que siguen pendientes hoy
+		habit, pending, err := s.pendingToday(reminder.HabitID, today)
+		if err != nil {
+			log.Printf("Error al comprobar el hábito %d: %v", reminder.HabitID, err)
+			continue
+		}
+
+		if err := s.Repo.MarkHabitReminderFired(reminder.ID, today); err != nil {
+			log.Printf("Error al actualizar el recordatorio %d: %v", reminder.ID, err)
+			continue
+		}
+
+		if !pending {
+			continue
+		}
+
+		s.notify(models.ReminderNotification{
+			ReminderID: reminder.ID,
+			HabitID:    habit.ID,
+			HabitName:  habit.Name,
+			Time:       reminder.Time,
+			Date:       today,
+			FiredAt:    now,
+			Snoozed:    snoozed,
+		})
+	}
+}
+
+// pendingToday indica si el hábito sigue pendiente en la fecha indicada: toca según su
+// calendario (con la cuota del período sin cumplir), no está justificado ese día y no está
+// completado. Los hábitos a evitar no tienen nada que hacer, así que nunca están pendientes.
+func (s *Scheduler) pendingToday(habitID int, date string) (models.Habit, bool, error) {
+	entry, due, err := s.Repo.GetDueHabit(habitID, date)
+	if err != nil {
+		return models.Habit{}, false, err
+	}
+
+	return entry.Habit, due && !entry.Habit.Avoid && !entry.Completed, nil
+}
+
+// notify entrega la notificación a todos los notificadores configurados
+func (s *Scheduler) notify(notification models.ReminderNotification) {
+	for _, notifier := range s.Notifiers {
+		if err := notifier.Notify(notification); err != nil {
+			log.Printf("Error al notificar el recordatorio %d: %v", notification.ReminderID, err)
+		}
+	}
+}
+
+// isDue indica si un recordatorio debe dispararse ahora y si procede de un aplazamiento.
+// dayOf asigna instantes a días lógicos: un recordatorio anterior a la hora de cambio de día
+// pertenece al día que termina, no al que empieza a medianoche.
+func isDue(reminder models.HabitReminder, now time.Time, dayOf func(time.Time) string) (snoozed bool, due bool) {
+	today := dayOf(now)
+
+	if reminder.DismissedOn == today {
+		return false, false
+	}
+
+	// Un aplazamiento vencido se dispara aunque ya se hubiera disparado hoy
+	if reminder.SnoozedUntil != nil {
+		return true, !reminder.SnoozedUntil.After(now)
+	}
+
+	day, err := time.ParseInLocation("2006-01-02", today, now.Location())
+	if err != nil {
+		return false, false
+	}
+
+	if reminder.Weekdays&(1<<uint(day.Weekday())) == 0 {
+		return false, false
+	}
+
+	if reminder.LastFiredOn == today {
+		return false, false
+	}
+
+	clock, err := time.Parse("15:04", reminder.Time)
+	if err != nil {
+		return false, false
+	}
+
+	fireAt := time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+	if dayOf(fireAt) != today {
+		fireAt = fireAt.AddDate(0, 0, 1)
+	}
+	return false, !fireAt.After(now)
+}
@@ -0,0 +1,105 @@
+package reminders
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+func TestIsDue(t *testing.T) {
+	// El día lógico cambia a las 04:00
+	dayOf := func(t time.Time) string {
+		return t.Add(-4 * time.Hour).Format("2006-01-02")
+	}
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, time.October, day, hour, minute, 0, 0, time.UTC)
+	}
+	snooze := func(day, hour, minute int) *time.Time {
+		until := at(day, hour, minute)
+		return &until
+	}
+
+	const everyDay = 1<<7 - 1
+	const wednesday = 1 << time.Wednesday
+	const thursday = 1 << time.Thursday
+
+	// El 14 de octubre de 2026 es miércoles
+	tests := []struct {
+		name        string
+		reminder    models.HabitReminder
+		now         time.Time
+		wantSnoozed bool
+		wantDue     bool
+	}{
+		{
+			name:     "antes de la hora",
+			reminder: models.HabitReminder{Time: "09:00", Weekdays: everyDay},
+			now:      at(14, 8, 59),
+		},
+		{
+			name:     "a la hora",
+			reminder: models.HabitReminder{Time: "09:00", Weekdays: everyDay},
+			now:      at(14, 9, 0), wantDue: true,
+		},
+		{
+			name:     "ya disparado hoy",
+			reminder: models.HabitReminder{Time: "09:00", Weekdays: everyDay, LastFiredOn: "2026-10-14"},
+			now:      at(14, 12, 0),
+		},
+		{
+			name:     "disparado ayer",
+			reminder: models.HabitReminder{Time: "09:00", Weekdays: everyDay, LastFiredOn: "2026-10-13"},
+			now:      at(14, 12, 0), wantDue: true,
+		},
+		{
+			name:     "otro día de la semana",
+			reminder: models.HabitReminder{Time: "09:00", Weekdays: thursday},
+			now:      at(14, 12, 0),
+		},
+		{
+			name:     "descartado hoy, aunque esté aplazado",
+			reminder: models.HabitReminder{Time: "09:00", Weekdays: everyDay, DismissedOn: "2026-10-14", SnoozedUntil: snooze(14, 10, 0)},
+			now:      at(14, 12, 0),
+		},
+		{
+			name:     "aplazamiento pendiente",
+			reminder: models.HabitReminder{Time: "09:00", Weekdays: everyDay, LastFiredOn: "2026-10-14", SnoozedUntil: snooze(14, 10, 0)},
+			now:      at(14, 9, 30), wantSnoozed: true,
+		},
+		{
+			name:     "aplazamiento vencido, aunque ya se disparara hoy",
+			reminder: models.HabitReminder{Time: "09:00", Weekdays: everyDay, LastFiredOn: "2026-10-14", SnoozedUntil: snooze(14, 10, 0)},
+			now:      at(14, 10, 0), wantSnoozed: true, wantDue: true,
+		},
+		{
+			name:     "de madrugada, pertenece al día que termina",
+			reminder: models.HabitReminder{Time: "02:00", Weekdays: wednesday},
+			now:      at(15, 2, 30), wantDue: true,
+		},
+		{
+			name:     "de madrugada, el jueves natural aún es miércoles",
+			reminder: models.HabitReminder{Time: "02:00", Weekdays: thursday},
+			now:      at(15, 2, 30),
+		},
+		{
+			name:     "de madrugada, todavía no ha llegado",
+			reminder: models.HabitReminder{Time: "02:00", Weekdays: wednesday},
+			now:      at(14, 23, 0),
+		},
+		{
+			name:     "hora inválida",
+			reminder: models.HabitReminder{Time: "9h", Weekdays: everyDay},
+			now:      at(14, 12, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snoozed, due := isDue(tt.reminder, tt.now, dayOf)
+			if snoozed != tt.wantSnoozed || due != tt.wantDue {
+				t.Errorf("isDue() = (%v, %v), se esperaba (%v, %v)", snoozed, due, tt.wantSnoozed, tt.wantDue)
+			}
+		})
+	}
+}
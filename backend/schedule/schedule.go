@@ -0,0 +1,178 @@
+// Package schedule interpreta el calendario de los hábitos: qué días toca cumplir cada uno.
+// Lo usan el controlador de hábitos (qué toca hoy) y las estadísticas, donde los días que no
+// tocan no cuentan ni como cumplidos ni como fallos.
+//
+// Calendarios admitidos:
+//
+//	daily      todos los días
+//	weekdays   días concretos de la semana (máscara 1<<time.Weekday, como los recordatorios)
+//	interval   cada N días a partir de una fecha de referencia
+//	weekly     X días por semana ISO (de lunes a domingo)
+//	monthly    X días por mes natural
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+const dateLayout = "2006-01-02"
+
+// FromFrequency deriva el calendario de la frecuencia y el objetivo, para los hábitos creados
+// sin calendario: las frecuencias semanal y mensual son cuotas de goal días
+func FromFrequency(frequency string, goal int) models.HabitSchedule {
+	switch frequency {
+	case models.FrequencyWeekly:
+		return models.HabitSchedule{Type: models.ScheduleWeekly, Times: goal}
+	case models.FrequencyMonthly:
+		return models.HabitSchedule{Type: models.ScheduleMonthly, Times: goal}
+	default:
+		return models.HabitSchedule{Type: models.ScheduleDaily}
+	}
+}
+
+// Frequency devuelve la frecuencia equivalente a un calendario. Los calendarios fijos se
+// evalúan día a día, así que equivalen a la frecuencia diaria.
+func Frequency(s models.HabitSchedule) string {
+	switch s.Type {
+	case models.ScheduleWeekly:
+		return models.FrequencyWeekly
+	case models.ScheduleMonthly:
+		return models.FrequencyMonthly
+	default:
+		return models.FrequencyDaily
+	}
+}
+
+// IsQuota indica si el calendario pide un número de días por semana o por mes
+func IsQuota(s models.HabitSchedule) bool {
+	return s.Type == models.ScheduleWeekly || s.Type == models.ScheduleMonthly
+}
+
+// Validate comprueba que un calendario está completo y es coherente
+func Validate(s models.HabitSchedule) error {
+	switch s.Type {
+	case models.ScheduleDaily:
+		return nil
+	case models.ScheduleWeekdays:
+		if s.Weekdays <= 0 || s.Weekdays > models.AllWeekdays {
+			return errors.New("máscara de días de la semana inválida")
+		}
+	case models.ScheduleInterval:
+		if s.Interval < 1 {
+			return errors.New("el intervalo debe ser de al menos 1 día")
+		}
+		if _, err := time.Parse(dateLayout, s.Anchor); err != nil {
+			return errors.New("fecha de referencia del intervalo inválida. Usar YYYY-MM-DD")
+		}
+	case models.ScheduleWeekly:
+		if s.Times < 1 || s.Times > 7 {
+			return errors.New("la cuota semanal debe estar entre 1 y 7 días")
+		}
+	case models.ScheduleMonthly:
+		if s.Times < 1 || s.Times > 31 {
+			return errors.New("la cuota mensual debe estar entre 1 y 31 días")
+		}
+	default:
+		return fmt.Errorf("tipo de calendario inválido: %q. Usar daily, weekdays, interval, weekly o monthly", s.Type)
+	}
+
+	return nil
+}
+
+// Scheduled indica si un día toca según el calendario. En las cuotas cualquier día sirve:
+// que toque o no depende de lo ya cumplido en el período (ver DueDates).
+func Scheduled(s models.HabitSchedule, day time.Time) bool {
+	switch s.Type {
+	case models.ScheduleWeekdays:
+		return s.Weekdays&(1<<uint(day.Weekday())) != 0
+	case models.ScheduleInterval:
+		anchor, err := time.Parse(dateLayout, s.Anchor)
+		if err != nil || s.Interval < 1 {
+			return false
+		}
+		days := daysBetween(anchor, day)
+		return days >= 0 && days%s.Interval == 0
+	default:
+		return true
+	}
+}
+
+// DueDates devuelve los días del rango (ambos extremos incluidos) en que toca el hábito. En las
+// cuotas toca cada día del período hasta el que completa la cuota, incluido; completed indica
+// los días cumplidos (YYYY-MM-DD) y debe cubrir también el principio del primer período.
+func DueDates(s models.HabitSchedule, start, end time.Time, completed map[string]bool) []string {
+	start = truncateDay(start)
+	end = truncateDay(end)
+
+	var due []string
+	if !IsQuota(s) {
+		for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+			if Scheduled(s, day) {
+				due = append(due, day.Format(dateLayout))
+			}
+		}
+		return due
+	}
+
+	for period := PeriodStart(s, start); !period.After(end); period = nextPeriod(s, period) {
+		done := 0
+		for day := period; day.Before(nextPeriod(s, period)) && !day.After(end); day = day.AddDate(0, 0, 1) {
+			if done >= s.Times {
+				break
+			}
+			date := day.Format(dateLayout)
+			if !day.Before(start) {
+				due = append(due, date)
+			}
+			if completed[date] {
+				done++
+			}
+		}
+	}
+
+	return due
+}
+
+// PeriodStart devuelve el inicio del período de la cuota que contiene el día (el lunes de su
+// semana ISO o el día 1 de su mes); en los calendarios fijos, el propio día
+func PeriodStart(s models.HabitSchedule, day time.Time) time.Time {
+	day = truncateDay(day)
+
+	switch s.Type {
+	case models.ScheduleWeekly:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case models.ScheduleMonthly:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+	default:
+		return day
+	}
+}
+
+// nextPeriod devuelve el inicio del período siguiente de la cuota
+func nextPeriod(s models.HabitSchedule, start time.Time) time.Time {
+	switch s.Type {
+	case models.ScheduleWeekly:
+		return start.AddDate(0, 0, 7)
+	case models.ScheduleMonthly:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// daysBetween cuenta los días de calendario de from a to, sin verse afectado por los cambios
+// de horario
+func daysBetween(from, to time.Time) int {
+	a := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	b := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
+
+// truncateDay devuelve el día a medianoche, en su zona horaria
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
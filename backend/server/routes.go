@@ -0,0 +1,822 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+// handlerFunc es un manejador que devuelve el código de estado, el cuerpo y un error.
+// Si hay error, el código indica cómo se informa al cliente.
+type handlerFunc func(r *http.Request) (int, interface{}, error)
+
+// handle adapta un handlerFunc a http.HandlerFunc
+func handle(f handlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status, body, err := f(r)
+		if err != nil {
+			writeError(w, status, err)
+			return
+		}
+
+		if status == http.StatusNoContent {
+			w.WriteHeader(status)
+			return
+		}
+
+		writeJSON(w, status, body)
+	}
+}
+
+// routes registra todas las rutas de la API.
+// Convención de códigos: 200/201/204 en éxito, 400 si los datos no son válidos, 401 sin
+// token válido y 404 si el recurso de la ruta no existe.
+func (s *Server) routes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/health", handle(func(r *http.Request) (int, interface{}, error) {
+		return http.StatusOK, map[string]string{"status": "ok"}, nil
+	}))
+
+	// Hábitos
+	mux.HandleFunc("GET /api/habits", handle(s.listHabits))
+	mux.HandleFunc("POST /api/habits", handle(s.createHabit))
+	mux.HandleFunc("GET /api/habits/due", handle(s.listDueHabits))
+	mux.HandleFunc("GET /api/habits/{id}", handle(s.getHabit))
+	mux.HandleFunc("PUT /api/habits/{id}", handle(s.updateHabit))
+	mux.HandleFunc("DELETE /api/habits/{id}", handle(s.deleteHabit))
+	mux.HandleFunc("GET /api/habits/{id}/goals", handle(s.listHabitGoals))
+	mux.HandleFunc("GET /api/habits/{id}/logs", handle(s.listHabitLogs))
+	mux.HandleFunc("POST /api/habits/{id}/logs", handle(s.logHabit))
+	mux.HandleFunc("POST /api/habits/{id}/complete", handle(s.completeHabit))
+	mux.HandleFunc("DELETE /api/habits/{id}/complete", handle(s.uncompleteHabit))
+	mux.HandleFunc("GET /api/habits/{id}/slips", handle(s.listHabitSlips))
+	mux.HandleFunc("POST /api/habits/{id}/slips", handle(s.recordSlip))
+	mux.HandleFunc("DELETE /api/habits/{id}/slips/{slip}", handle(s.deleteHabitSlip))
+
+	// Días justificados
+	mux.HandleFunc("GET /api/excuses", handle(s.listExcuses))
+	mux.HandleFunc("POST /api/excuses", handle(s.createExcuse))
+	mux.HandleFunc("DELETE /api/excuses/{id}", handle(s.deleteExcuse))
+
+	// Estado de ánimo
+	mux.HandleFunc("GET /api/mood", handle(s.listMood))
+	mux.HandleFunc("POST /api/mood", handle(s.createMood))
+	mux.HandleFunc("GET /api/mood/date/{date}", handle(s.getMoodByDate))
+	mux.HandleFunc("GET /api/mood/checkins", handle(s.listMoodCheckIns))
+	mux.HandleFunc("POST /api/mood/checkins", handle(s.createMoodCheckIn))
+	mux.HandleFunc("GET /api/mood/daily", handle(s.listDailyMoods))
+	mux.HandleFunc("GET /api/mood/{id}", handle(s.getMood))
+	mux.HandleFunc("PUT /api/mood/{id}", handle(s.updateMood))
+	mux.HandleFunc("DELETE /api/mood/{id}", handle(s.deleteMood))
+
+	// Cafeína
+	mux.HandleFunc("GET /api/caffeine/beverages", handle(s.listBeverages))
+	mux.HandleFunc("POST /api/caffeine/beverages", handle(s.createBeverage))
+	mux.HandleFunc("GET /api/caffeine/beverages/{id}", handle(s.getBeverage))
+	mux.HandleFunc("PUT /api/caffeine/beverages/{id}", handle(s.updateBeverage))
+	mux.HandleFunc("DELETE /api/caffeine/beverages/{id}", handle(s.deleteBeverage))
+	mux.HandleFunc("GET /api/caffeine/intake", handle(s.listIntake))
+	mux.HandleFunc("POST /api/caffeine/intake", handle(s.createIntake))
+	mux.HandleFunc("GET /api/caffeine/intake/{id}", handle(s.getIntake))
+	mux.HandleFunc("PUT /api/caffeine/intake/{id}", handle(s.updateIntake))
+	mux.HandleFunc("DELETE /api/caffeine/intake/{id}", handle(s.deleteIntake))
+	mux.HandleFunc("GET /api/caffeine/daily", handle(s.dailyCaffeine))
+	mux.HandleFunc("GET /api/caffeine/budget", handle(s.caffeineBudget))
+	mux.HandleFunc("GET /api/caffeine/level", handle(s.caffeineLevel))
+	mux.HandleFunc("GET /api/caffeine/bedtime", handle(s.bedtimeStatus))
+
+	// Estadísticas
+	mux.HandleFunc("GET /api/stats/habits/{id}", handle(s.habitStats))
+	mux.HandleFunc("GET /api/stats/habits/{id}/streaks", handle(s.habitStreaks))
+	mux.HandleFunc("GET /api/stats/mood", handle(s.moodStats))
+	mux.HandleFunc("GET /api/stats/caffeine", handle(s.caffeineStats))
+	mux.HandleFunc("GET /api/stats/correlations", handle(s.correlationStats))
+	mux.HandleFunc("GET /api/stats/habits-mood", handle(s.habitMoodStats))
+	mux.HandleFunc("GET /api/stats/lagged-correlations", handle(s.laggedCorrelationStats))
+}
+
+// ==================== HÁBITOS ====================
+
+func (s *Server) listHabits(r *http.Request) (int, interface{}, error) {
+	habits, err := s.Habits.GetAllHabits()
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusOK, habits, nil
+}
+
+func (s *Server) listDueHabits(r *http.Request) (int, interface{}, error) {
+	due, err := s.Habits.GetDueHabits(r.URL.Query().Get("date"))
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	return http.StatusOK, due, nil
+}
+
+func (s *Server) createHabit(r *http.Request) (int, interface{}, error) {
+	var input models.NewHabitInput
+	if err := readJSON(r, &input); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	habit, err := s.Habits.CreateHabit(input)
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	return http.StatusCreated, habit, nil
+}
+
+func (s *Server) getHabit(r *http.Request) (int, interface{}, error) {
+	habit, status, err := s.habitFromPath(r)
+	if err != nil {
+		return status, nil, err
+	}
+	return http.StatusOK, habit, nil
+}
+
+func (s *Server) updateHabit(r *http.Request) (int, interface{}, error) {
+	habit, status, err := s.habitFromPath(r)
+	if err != nil {
+		return status, nil, err
+	}
+
+	var input models.UpdateHabitInput
+	if err := readJSON(r, &input); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	updated, err := s.Habits.UpdateHabit(habit.ID, input)
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	return http.StatusOK, updated, nil
+}
+
+func (s *Server) deleteHabit(r *http.Request) (int, interface{}, error) {
+	habit, status, err := s.habitFromPath(r)
+	if err != nil {
+		return status, nil, err
+	}
+
+	if err := s.Habits.DeleteHabit(habit.ID); err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusNoContent, nil, nil
+}
+
+func (s *Server) listHabitLogs(r *http.Request) (int, interface{}, error) {
+	habit, status, err := s.habitFromPath(r)
+	if err != nil {
+		return status, nil, err
+	}
+
+	query := r.URL.Query()
+	logs, err := s.Habits.GetHabitLogs(habit.ID, query.Get("start_date"), query.Get("end_date"))
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	if logs == nil {
+		logs = []models.HabitLog{}
+	}
+	return http.StatusOK, logs, nil
+}
+
+func (s *Server) logHabit(r *http.Request) (int, interface{}, error) {
+	habit, status, err := s.habitFromPath(r)
+	if err != nil {
+		return status, nil, err
+	}
+
+	var input models.NewHabitLogInput
+	if err := readJSON(r, &input); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	if err := s.Habits.LogHabit(habit.ID, input); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	return http.StatusNoContent, nil, nil
+}
+
+func (s *Server) completeHabit(r *http.Request) (int, interface{}, error) {
+	habit, status, err := s.habitFromPath(r)
+	if err != nil {
+		return status, nil, err
+	}
+
+	if err := s.Habits.CompleteHabit(habit.ID, r.URL.Query().Get("date")); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	return http.StatusNoContent, nil, nil
+}
+
+func (s *Server) uncompleteHabit(r *http.Request) (int, interface{}, error) {
+	habit, status, err := s.habitFromPath(r)
+	if err != nil {
+		return status, nil, err
+	}
+
+	if err := s.Habits.UncompleteHabit(habit.ID, r.URL.Query().Get("date")); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	return http.StatusNoContent, nil, nil
+}
+
+func (s *Server) listHabitGoals(r *http.Request) (int, interface{}, error) {
+	habit, status, err := s.habitFromPath(r)
+	if err != nil {
+		return status, nil, err
+	}
+
+	versions, err := s.Habits.GetHabitGoalHistory(habit.ID)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	if versions == nil {
+		versions = []models.HabitGoalVersion{}
+	}
+	return http.StatusOK, versions, nil
+}
+
+func (s *Server) listHabitSlips(r *http.Request) (int, interface{}, error) {
+	habit, status, err := s.habitFromPath(r)
+	if err != nil {
+		return status, nil, err
+	}
+
+	query := r.URL.Query()
+	slips, err := s.Habits.GetHabitSlips(habit.ID, query.Get("start_date"), query.Get("end_date"))
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	if slips == nil {
+		slips = []models.HabitSlip{}
+	}
+	return http.StatusOK, slips, nil
+}
+
+func (s *Server) recordSlip(r *http.Request) (int, interface{}, error) {
+	habit, status, err := s.habitFromPath(r)
+	if err != nil {
+		return status, nil, err
+	}
+
+	var input models.NewHabitSlipInput
+	if err := readJSON(r, &input); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	slip, err := s.Habits.RecordSlip(habit.ID, input)
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	return http.StatusCreated, slip, nil
+}
+
+func (s *Server) deleteHabitSlip(r *http.Request) (int, interface{}, error) {
+	habit, status, err := s.habitFromPath(r)
+	if err != nil {
+		return status, nil, err
+	}
+
+	id, err := pathID(r, "slip")
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	slip, err := s.Habits.Repo.GetHabitSlip(id)
+	if err != nil || slip.HabitID != habit.ID {
+		return http.StatusNotFound, nil, errors.New("recaída no encontrada")
+	}
+
+	if err := s.Habits.DeleteHabitSlip(id); err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusNoContent, nil, nil
+}
+
+func (s *Server) listExcuses(r *http.Request) (int, interface{}, error) {
+	query := r.URL.Query()
+
+	habitID := 0
+	if value := query.Get("habit_id"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return http.StatusBadRequest, nil, fmt.Errorf("habit_id inválido: %s", value)
+		}
+		habitID = parsed
+	}
+
+	excuses, err := s.Habits.GetExcuses(habitID, query.Get("start_date"), query.Get("end_date"))
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	if excuses == nil {
+		excuses = []models.Excuse{}
+	}
+	return http.StatusOK, excuses, nil
+}
+
+func (s *Server) createExcuse(r *http.Request) (int, interface{}, error) {
+	var input models.NewExcuseInput
+	if err := readJSON(r, &input); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	excuse, err := s.Habits.CreateExcuse(input)
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	return http.StatusCreated, excuse, nil
+}
+
+func (s *Server) deleteExcuse(r *http.Request) (int, interface{}, error) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	if _, err := s.Habits.Repo.GetExcuse(id); err != nil {
+		return http.StatusNotFound, nil, errors.New("día justificado no encontrado")
+	}
+
+	if err := s.Habits.DeleteExcuse(id); err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusNoContent, nil, nil
+}
+
+// habitFromPath obtiene el hábito indicado en la ruta, con el código de error adecuado
+func (s *Server) habitFromPath(r *http.Request) (models.Habit, int, error) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		return models.Habit{}, http.StatusBadRequest, err
+	}
+
+	habit, err := s.Habits.GetHabit(id)
+	if err != nil {
+		return models.Habit{}, http.StatusNotFound, err
+	}
+
+	return habit, http.StatusOK, nil
+}
+
+// ==================== ESTADO DE ÁNIMO ====================
+
+func (s *Server) listMood(r *http.Request) (int, interface{}, error) {
+	query := r.URL.Query()
+	entries, err := s.Mood.GetAllMoodEntries(query.Get("start_date"), query.Get("end_date"))
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	if entries == nil {
+		entries = []models.MoodEntry{}
+	}
+	return http.StatusOK, entries, nil
+}
+
+func (s *Server) createMood(r *http.Request) (int, interface{}, error) {
+	var input models.NewMoodEntryInput
+	if err := readJSON(r, &input); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	entry, err := s.Mood.CreateMoodEntry(input)
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	return http.StatusCreated, entry, nil
+}
+
+func (s *Server) listMoodCheckIns(r *http.Request) (int, interface{}, error) {
+	entries, err := s.Mood.GetMoodCheckIns(r.URL.Query().Get("date"))
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	return http.StatusOK, entries, nil
+}
+
+func (s *Server) createMoodCheckIn(r *http.Request) (int, interface{}, error) {
+	var input models.NewMoodEntryInput
+	if err := readJSON(r, &input); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	entry, err := s.Mood.CreateMoodCheckIn(input)
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	return http.StatusCreated, entry, nil
+}
+
+func (s *Server) listDailyMoods(r *http.Request) (int, interface{}, error) {
+	query := r.URL.Query()
+	days, err := s.Mood.GetDailyMoods(query.Get("start_date"), query.Get("end_date"))
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	return http.StatusOK, days, nil
+}
+
+func (s *Server) getMoodByDate(r *http.Request) (int, interface{}, error) {
+	entry, err := s.Mood.GetMoodEntryByDate(r.PathValue("date"))
+	if err != nil {
+		return http.StatusNotFound, nil, err
+	}
+	return http.StatusOK, entry, nil
+}
+
+func (s *Server) getMood(r *http.Request) (int, interface{}, error) {
+	entry, status, err := s.moodFromPath(r)
+	if err != nil {
+		return status, nil, err
+	}
+	return http.StatusOK, entry, nil
+}
+
+func (s *Server) updateMood(r *http.Request) (int, interface{}, error) {
+	entry, status, err := s.moodFromPath(r)
+	if err != nil {
+		return status, nil, err
+	}
+
+	var input models.UpdateMoodEntryInput
+	if err := readJSON(r, &input); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	updated, err := s.Mood.UpdateMoodEntry(entry.ID, input)
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	return http.StatusOK, updated, nil
+}
+
+func (s *Server) deleteMood(r *http.Request) (int, interface{}, error) {
+	entry, status, err := s.moodFromPath(r)
+	if err != nil {
+		return status, nil, err
+	}
+
+	if err := s.Mood.DeleteMoodEntry(entry.ID); err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusNoContent, nil, nil
+}
+
+// moodFromPath obtiene el registro de estado de ánimo indicado en la ruta
+func (s *Server) moodFromPath(r *http.Request) (models.MoodEntry, int, error) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		return models.MoodEntry{}, http.StatusBadRequest, err
+	}
+
+	entry, err := s.Mood.GetMoodEntry(id)
+	if err != nil {
+		return models.MoodEntry{}, http.StatusNotFound, err
+	}
+
+	return entry, http.StatusOK, nil
+}
+
+// ==================== CAFEÍNA ====================
+
+func (s *Server) listBeverages(r *http.Request) (int, interface{}, error) {
+	beverages, err := s.Caffeine.GetAllCaffeineBeverages(r.URL.Query().Get("include_inactive") == "true")
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusOK, beverages, nil
+}
+
+func (s *Server) createBeverage(r *http.Request) (int, interface{}, error) {
+	var input models.NewCaffeineBeverageInput
+	if err := readJSON(r, &input); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	beverage, err := s.Caffeine.CreateCaffeineBeverage(input)
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	return http.StatusCreated, beverage, nil
+}
+
+func (s *Server) getBeverage(r *http.Request) (int, interface{}, error) {
+	beverage, status, err := s.beverageFromPath(r)
+	if err != nil {
+		return status, nil, err
+	}
+	return http.StatusOK, beverage, nil
+}
+
+func (s *Server) updateBeverage(r *http.Request) (int, interface{}, error) {
+	beverage, status, err := s.beverageFromPath(r)
+	if err != nil {
+		return status, nil, err
+	}
+
+	var input models.UpdateCaffeineBeverageInput
+	if err := readJSON(r, &input); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	updated, err := s.Caffeine.UpdateCaffeineBeverage(beverage.ID, input)
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	return http.StatusOK, updated, nil
+}
+
+func (s *Server) deleteBeverage(r *http.Request) (int, interface{}, error) {
+	beverage, status, err := s.beverageFromPath(r)
+	if err != nil {
+		return status, nil, err
+	}
+
+	if err := s.Caffeine.DeleteCaffeineBeverage(beverage.ID); err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusNoContent, nil, nil
+}
+
+// beverageFromPath obtiene la bebida indicada en la ruta
+func (s *Server) beverageFromPath(r *http.Request) (models.CaffeineBeverage, int, error) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		return models.CaffeineBeverage{}, http.StatusBadRequest, err
+	}
+
+	beverage, err := s.Caffeine.GetCaffeineBeverage(id)
+	if err != nil {
+		return models.CaffeineBeverage{}, http.StatusNotFound, err
+	}
+
+	return beverage, http.StatusOK, nil
+}
+
+func (s *Server) listIntake(r *http.Request) (int, interface{}, error) {
+	query := r.URL.Query()
+
+	var intakes []models.CaffeineIntake
+	var err error
+	if date := query.Get("date"); date != "" {
+		intakes, err = s.Caffeine.GetCaffeineIntakeByDay(date)
+	} else {
+		intakes, err = s.Caffeine.GetCaffeineIntakeRange(query.Get("start_date"), query.Get("end_date"))
+	}
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	if intakes == nil {
+		intakes = []models.CaffeineIntake{}
+	}
+	return http.StatusOK, intakes, nil
+}
+
+func (s *Server) createIntake(r *http.Request) (int, interface{}, error) {
+	var input models.NewCaffeineIntakeInput
+	if err := readJSON(r, &input); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	result, err := s.Caffeine.CreateCaffeineIntake(input)
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	return http.StatusCreated, result, nil
+}
+
+func (s *Server) getIntake(r *http.Request) (int, interface{}, error) {
+	intake, status, err := s.intakeFromPath(r)
+	if err != nil {
+		return status, nil, err
+	}
+	return http.StatusOK, intake, nil
+}
+
+func (s *Server) updateIntake(r *http.Request) (int, interface{}, error) {
+	intake, status, err := s.intakeFromPath(r)
+	if err != nil {
+		return status, nil, err
+	}
+
+	var input models.UpdateCaffeineIntakeInput
+	if err := readJSON(r, &input); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	result, err := s.Caffeine.UpdateCaffeineIntake(intake.ID, input)
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	return http.StatusOK, result, nil
+}
+
+func (s *Server) deleteIntake(r *http.Request) (int, interface{}, error) {
+	intake, status, err := s.intakeFromPath(r)
+	if err != nil {
+		return status, nil, err
+	}
+
+	if err := s.Caffeine.DeleteCaffeineIntake(intake.ID); err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusNoContent, nil, nil
+}
+
+// intakeFromPath obtiene el consumo de cafeína indicado en la ruta
+func (s *Server) intakeFromPath(r *http.Request) (models.CaffeineIntake, int, error) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		return models.CaffeineIntake{}, http.StatusBadRequest, err
+	}
+
+	intake, err := s.Caffeine.GetCaffeineIntake(id)
+	if err != nil {
+		return models.CaffeineIntake{}, http.StatusNotFound, err
+	}
+
+	return intake, http.StatusOK, nil
+}
+
+func (s *Server) dailyCaffeine(r *http.Request) (int, interface{}, error) {
+	date := r.URL.Query().Get("date")
+	if date == "" {
+		date = s.Caffeine.Repo.Today()
+	}
+
+	total, err := s.Caffeine.GetDailyCaffeineTotal(date)
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	return http.StatusOK, total, nil
+}
+
+func (s *Server) caffeineBudget(r *http.Request) (int, interface{}, error) {
+	budget, err := s.Caffeine.GetCaffeineBudget(r.URL.Query().Get("date"))
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	return http.StatusOK, budget, nil
+}
+
+func (s *Server) caffeineLevel(r *http.Request) (int, interface{}, error) {
+	level, err := s.Caffeine.GetCaffeineLevel(r.URL.Query().Get("timestamp"))
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	return http.StatusOK, level, nil
+}
+
+func (s *Server) bedtimeStatus(r *http.Request) (int, interface{}, error) {
+	status, err := s.Caffeine.GetBedtimeCaffeineStatus()
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusOK, status, nil
+}
+
+// ==================== ESTADÍSTICAS ====================
+
+func (s *Server) habitStats(r *http.Request) (int, interface{}, error) {
+	habit, status, err := s.habitFromPath(r)
+	if err != nil {
+		return status, nil, err
+	}
+
+	spec, err := s.queryPeriod(r)
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	stats, err := s.Stats.GetHabitStats(habit.ID, spec)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusOK, stats, nil
+}
+
+func (s *Server) habitStreaks(r *http.Request) (int, interface{}, error) {
+	habit, status, err := s.habitFromPath(r)
+	if err != nil {
+		return status, nil, err
+	}
+
+	streaks, err := s.Stats.GetHabitStreaks(habit.ID)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusOK, streaks, nil
+}
+
+func (s *Server) moodStats(r *http.Request) (int, interface{}, error) {
+	spec, err := s.queryPeriod(r)
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	stats, err := s.Stats.GetMoodStats(spec)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusOK, stats, nil
+}
+
+func (s *Server) caffeineStats(r *http.Request) (int, interface{}, error) {
+	spec, err := s.queryPeriod(r)
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	stats, err := s.Stats.GetCaffeineStats(spec)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusOK, stats, nil
+}
+
+func (s *Server) correlationStats(r *http.Request) (int, interface{}, error) {
+	windowDays, err := queryWindowDays(r)
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	stats, err := s.Stats.GetCorrelationStats(windowDays)
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	return http.StatusOK, stats, nil
+}
+
+func (s *Server) habitMoodStats(r *http.Request) (int, interface{}, error) {
+	windowDays, err := queryWindowDays(r)
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	analysis, err := s.Stats.GetHabitMoodCorrelations(windowDays)
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	return http.StatusOK, analysis, nil
+}
+
+func (s *Server) laggedCorrelationStats(r *http.Request) (int, interface{}, error) {
+	windowDays, err := queryWindowDays(r)
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	options := models.LaggedCorrelationOptions{WindowDays: windowDays}
+	for name, target := range map[string]**int{"max_lag": &options.MaxLag, "cutoff_hour": &options.CutoffHour} {
+		if value := r.URL.Query().Get(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return http.StatusBadRequest, nil, fmt.Errorf("%s inválido: %s", name, value)
+			}
+			*target = &parsed
+		}
+	}
+
+	matrix, err := s.Stats.GetLaggedCorrelations(options)
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	return http.StatusOK, matrix, nil
+}
+
+// queryPeriod lee el período de las estadísticas: el parámetro period o el par start/end
+// (YYYY-MM-DD), y lo valida
+func (s *Server) queryPeriod(r *http.Request) (string, error) {
+	query := r.URL.Query()
+
+	spec := query.Get("period")
+	if start, end := query.Get("start"), query.Get("end"); start != "" || end != "" {
+		if spec != "" || start == "" || end == "" {
+			return "", errors.New("indica period o bien start y end")
+		}
+		spec = start + ".." + end
+	}
+
+	if _, err := s.Stats.ResolvePeriod(spec); err != nil {
+		return "", err
+	}
+	return spec, nil
+}
+
+// queryWindowDays lee el parámetro opcional window_days (0 = valor por defecto)
+func queryWindowDays(r *http.Request) (int, error) {
+	value := r.URL.Query().Get("window_days")
+	if value == "" {
+		return 0, nil
+	}
+
+	windowDays, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("window_days inválido: %s", value)
+	}
+	return windowDays, nil
+}
@@ -0,0 +1,177 @@
+// Package server expone los controladores de la aplicación como una API REST JSON local
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/api"
+)
+
+// Controllers agrupa los controladores que expone el servidor
+type Controllers struct {
+	Habits   *api.HabitController
+	Mood     *api.MoodController
+	Caffeine *api.CaffeineController
+	Stats    *api.StatsController
+}
+
+// Server es un servidor HTTP que publica los controladores bajo /api
+type Server struct {
+	Controllers
+	Address string
+	Token   string
+
+	mu       sync.Mutex
+	http     *http.Server
+	listener net.Listener
+}
+
+// New crea un servidor REST. El token es obligatorio para todas las rutas salvo /api/health.
+func New(controllers Controllers, address, token string) *Server {
+	return &Server{
+		Controllers: controllers,
+		Address:     address,
+		Token:       token,
+	}
+}
+
+// Start abre el puerto y atiende peticiones en segundo plano
+func (s *Server) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.http != nil {
+		return errors.New("el servidor ya está en marcha")
+	}
+
+	if s.Token == "" {
+		return errors.New("el servidor necesita un token de acceso")
+	}
+
+	listener, err := net.Listen("tcp", s.Address)
+	if err != nil {
+		return fmt.Errorf("error al abrir %s: %w", s.Address, err)
+	}
+
+	s.listener = listener
+	s.http = &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func(srv *http.Server) {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Error en el servidor REST: %v", err)
+		}
+	}(s.http)
+
+	log.Printf("Servidor REST escuchando en http://%s/api", listener.Addr())
+	return nil
+}
+
+// Addr devuelve la dirección real en la que escucha el servidor (útil con el puerto 0)
+func (s *Server) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener == nil {
+		return s.Address
+	}
+	return s.listener.Addr().String()
+}
+
+// Stop cierra el servidor esperando a que terminen las peticiones en curso
+func (s *Server) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	srv := s.http
+	s.http = nil
+	s.listener = nil
+	s.mu.Unlock()
+
+	if srv == nil {
+		return nil
+	}
+
+	return srv.Shutdown(ctx)
+}
+
+// Handler devuelve el manejador HTTP con todas las rutas y la autenticación
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	s.routes(mux)
+
+	return s.authenticate(mux)
+}
+
+// authenticate exige el token en la cabecera "Authorization: Bearer <token>"
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/health" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="habit-tracker"`)
+			writeError(w, http.StatusUnauthorized, errors.New("token de acceso inválido o ausente"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ==================== UTILIDADES HTTP ====================
+
+// errorResponse es el cuerpo de las respuestas de error
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeJSON escribe una respuesta JSON con el código indicado
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Error al escribir respuesta: %v", err)
+	}
+}
+
+// writeError escribe un error como JSON
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+// readJSON decodifica el cuerpo de la petición rechazando campos desconocidos
+func readJSON(r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, 1<<20))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("cuerpo JSON inválido: %w", err)
+	}
+
+	return nil
+}
+
+// pathID obtiene un ID numérico de la ruta
+func pathID(r *http.Request, name string) (int, error) {
+	id, err := strconv.Atoi(r.PathValue(name))
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("ID inválido: %s", r.PathValue(name))
+	}
+	return id, nil
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthenticate(t *testing.T) {
+	s := New(Controllers{}, "127.0.0.1:0", "s3cret")
+	handler := s.Handler()
+
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		wantStatus    int
+	}{
+		{name: "health sin token", path: "/api/health", wantStatus: http.StatusOK},
+		{name: "health con un token inválido", path: "/api/health", authorization: "Bearer otro", wantStatus: http.StatusOK},
+		{name: "sin cabecera", path: "/api/habits", wantStatus: http.StatusUnauthorized},
+		{name: "token incorrecto", path: "/api/habits", authorization: "Bearer otro", wantStatus: http.StatusUnauthorized},
+		{name: "prefijo del token", path: "/api/habits", authorization: "Bearer s3c", wantStatus: http.StatusUnauthorized},
+		{name: "token vacío", path: "/api/habits", authorization: "Bearer ", wantStatus: http.StatusUnauthorized},
+		{name: "sin el esquema Bearer", path: "/api/habits", authorization: "s3cret", wantStatus: http.StatusUnauthorized},
+		{name: "otro esquema", path: "/api/habits", authorization: "Basic s3cret", wantStatus: http.StatusUnauthorized},
+		// Con el token la petición llega al enrutador, que responde 404 a una ruta desconocida
+		{name: "token correcto", path: "/api/desconocida", authorization: "Bearer s3cret", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, se esperaba %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusUnauthorized {
+				return
+			}
+
+			if got := rec.Header().Get("WWW-Authenticate"); got == "" {
+				t.Error("falta la cabecera WWW-Authenticate")
+			}
+			var body errorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || body.Error == "" {
+				t.Errorf("cuerpo = %+v (%v), se esperaba un error JSON", body, err)
+			}
+		})
+	}
+}
+
+func TestStartRequiresToken(t *testing.T) {
+	s := New(Controllers{}, "127.0.0.1:0", "")
+	if err := s.Start(); err == nil {
+		s.Stop(context.Background())
+		t.Fatal("Start debería fallar sin token")
+	}
+}
@@ -0,0 +1,1169 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+// errUsage indica que el comando no existe o le faltan argumentos
+var errUsage = errors.New("uso incorrecto")
+
+// dispatch ejecuta el comando de un grupo
+func (c *cli) dispatch(group, command string, args []string) error {
+	handlers := map[string]map[string]func([]string) error{
+		"habit": {
+			"list":       c.habitList,
+			"add":        c.habitAdd,
+			"complete":   c.habitComplete,
+			"uncomplete": c.habitUncomplete,
+			"log":        c.habitLog,
+			"logs":       c.habitLogs,
+			"slip":       c.habitSlip,
+			"slips":      c.habitSlips,
+			"due":        c.habitDue,
+			"goal":       c.habitGoal,
+			"goals":      c.habitGoals,
+		},
+		"excuse": {
+			"add":    c.excuseAdd,
+			"list":   c.excuseList,
+			"delete": c.excuseDelete,
+		},
+		"mood": {
+			"add":   c.moodAdd,
+			"list":  c.moodList,
+			"daily": c.moodDaily,
+		},
+		"caffeine": {
+			"beverages": c.caffeineBeverages,
+			"add":       c.caffeineAdd,
+			"list":      c.caffeineList,
+		},
+		"stats": {
+			"habit":    c.statsHabit,
+			"streaks":  c.statsStreaks,
+			"mood":     c.statsMood,
+			"caffeine": c.statsCaffeine,
+			"impact":   c.statsImpact,
+			"lagged":   c.statsLagged,
+		},
+		"settings": {
+			"timezone": c.settingsTimezone,
+		},
+		"server": {
+			"start": c.serverStart,
+			"token": c.serverToken,
+		},
+	}
+
+	handler, ok := handlers[group][command]
+	if !ok {
+		return errUsage
+	}
+
+	return handler(args)
+}
+
+// newFlags crea el conjunto de opciones de un comando
+func newFlags(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	return flags
+}
+
+// parseFlags analiza las opciones permitiendo que aparezcan antes o después de los
+// argumentos posicionales, y devuelve estos últimos
+func parseFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, fmt.Errorf("%s: %w", flags.Name(), err)
+		}
+
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// ==================== HÁBITOS ====================
+
+// habitList lista los hábitos
+func (c *cli) habitList(args []string) error {
+	if _, err := parseFlags(newFlags("habit list"), args); err != nil {
+		return err
+	}
+
+	habits, err := c.habits.GetAllHabits()
+	if err != nil {
+		return err
+	}
+
+	return c.output(habits, func() error {
+		rows := make([][]string, 0, len(habits))
+		for _, h := range habits {
+			rows = append(rows, []string{
+				strconv.Itoa(h.ID), h.Name, h.Category, habitSchedule(h), habitGoal(h), yesNo(h.Active),
+			})
+		}
+		return c.printTable([]string{"ID", "NOMBRE", "CATEGORÍA", "CALENDARIO", "META", "ACTIVO"}, rows)
+	})
+}
+
+// habitAdd crea un hábito
+func (c *cli) habitAdd(args []string) error {
+	flags := newFlags("habit add")
+	frequency := flags.String("frequency", models.FrequencyDaily, "frecuencia: daily, weekly o monthly")
+	goal := flags.Int("goal", 1, "meta por período")
+	category := flags.String("category", "", "categoría")
+	description := flags.String("description", "", "descripción")
+	measure := flags.String("measure", models.MeasureBoolean, "medición: boolean, count, quantity o duration")
+	unit := flags.String("unit", "", "unidad de los valores (quantity)")
+	target := flags.Float64("target", 0, "objetivo diario en la unidad (quantity y duration)")
+	avoid := flags.Bool("avoid", false, "hábito a evitar: se registran recaídas")
+	scheduleType := flags.String("schedule", "", "calendario: daily, weekdays, interval, weekly o monthly")
+	days := flags.String("days", "", "días de la semana (weekdays), p. ej. mon,wed,fri")
+	every := flags.Int("every", 0, "cada cuántos días (interval)")
+	anchor := flags.String("anchor", "", "primer día del intervalo (YYYY-MM-DD), hoy por defecto")
+	times := flags.Int("times", 0, "días por semana o por mes (weekly y monthly)")
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errUsage
+	}
+
+	// Sin -schedule el calendario se deriva de la frecuencia
+	var schedule *models.HabitSchedule
+	if *scheduleType != "" {
+		weekdays, err := parseWeekdays(*days)
+		if err != nil {
+			return err
+		}
+		schedule = &models.HabitSchedule{
+			Type:     *scheduleType,
+			Weekdays: weekdays,
+			Interval: *every,
+			Anchor:   *anchor,
+			Times:    *times,
+		}
+	}
+
+	habit, err := c.habits.CreateHabit(models.NewHabitInput{
+		Name:        positional[0],
+		Description: *description,
+		Category:    *category,
+		Frequency:   *frequency,
+		Goal:        *goal,
+		Schedule:    schedule,
+		Measure:     *measure,
+		Unit:        *unit,
+		Target:      *target,
+		Avoid:       *avoid,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.output(habit, func() error {
+		return c.printTable([]string{"ID", "NOMBRE", "CALENDARIO", "META"}, [][]string{
+			{strconv.Itoa(habit.ID), habit.Name, habitSchedule(habit), habitGoal(habit)},
+		})
+	})
+}
+
+// habitComplete marca un hábito como completado
+func (c *cli) habitComplete(args []string) error {
+	return c.setHabitCompleted(args, true)
+}
+
+// habitUncomplete marca un hábito como no completado
+func (c *cli) habitUncomplete(args []string) error {
+	return c.setHabitCompleted(args, false)
+}
+
+// setHabitCompleted marca o desmarca un hábito en una fecha
+func (c *cli) setHabitCompleted(args []string, completed bool) error {
+	flags := newFlags("habit complete")
+	date := flags.String("date", "", "fecha (YYYY-MM-DD), hoy por defecto")
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errUsage
+	}
+
+	habit, err := c.resolveHabit(positional[0])
+	if err != nil {
+		return err
+	}
+
+	if completed {
+		err = c.habits.CompleteHabit(habit.ID, *date)
+	} else {
+		err = c.habits.UncompleteHabit(habit.ID, *date)
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.printHabitDay(habit, *date)
+}
+
+// habitLog registra una entrada de un hábito
+func (c *cli) habitLog(args []string) error {
+	flags := newFlags("habit log")
+	date := flags.String("date", "", "fecha (YYYY-MM-DD), hoy por defecto")
+	count := flags.Int("count", 0, "número de repeticiones")
+	value := flags.Float64("value", 0, "valor en la unidad del hábito (quantity y duration)")
+	done := flags.Bool("done", false, "marcar como completado")
+	notes := flags.String("notes", "", "notas")
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errUsage
+	}
+
+	habit, err := c.resolveHabit(positional[0])
+	if err != nil {
+		return err
+	}
+
+	input := models.NewHabitLogInput{
+		Date:      *date,
+		Completed: *done || (habit.Goal > 0 && *count >= habit.Goal),
+		Count:     *count,
+		Value:     *value,
+		Notes:     *notes,
+	}
+	if err := c.habits.LogHabit(habit.ID, input); err != nil {
+		return err
+	}
+
+	return c.printHabitDay(habit, *date)
+}
+
+// printHabitDay muestra el registro de un hábito en una fecha tras modificarlo
+func (c *cli) printHabitDay(habit models.Habit, date string) error {
+	if date == "" {
+		date = c.habits.Repo.Today()
+	}
+
+	logs, err := c.habits.GetHabitLogs(habit.ID, date, date)
+	if err != nil {
+		return err
+	}
+
+	return c.printHabitLogs(habit, logs)
+}
+
+// habitLogs lista los registros de un hábito
+func (c *cli) habitLogs(args []string) error {
+	flags := newFlags("habit logs")
+	from := flags.String("from", "", "fecha inicial (YYYY-MM-DD), hace 30 días por defecto")
+	to := flags.String("to", "", "fecha final (YYYY-MM-DD), hoy por defecto")
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errUsage
+	}
+
+	habit, err := c.resolveHabit(positional[0])
+	if err != nil {
+		return err
+	}
+
+	logs, err := c.habits.GetHabitLogs(habit.ID, *from, *to)
+	if err != nil {
+		return err
+	}
+
+	return c.printHabitLogs(habit, logs)
+}
+
+// printHabitLogs muestra registros de un hábito
+func (c *cli) printHabitLogs(habit models.Habit, logs []models.HabitLog) error {
+	if logs == nil {
+		logs = []models.HabitLog{}
+	}
+
+	return c.output(logs, func() error {
+		rows := make([][]string, 0, len(logs))
+		for _, l := range logs {
+			rows = append(rows, []string{
+				l.Date.Format("2006-01-02"), habit.Name, yesNo(l.Completed), habitAmount(habit, l), l.Notes,
+			})
+		}
+		return c.printTable([]string{"FECHA", "HÁBITO", "COMPLETADO", "CANTIDAD", "NOTAS"}, rows)
+	})
+}
+
+// habitSlip registra una recaída en un hábito a evitar
+func (c *cli) habitSlip(args []string) error {
+	flags := newFlags("habit slip")
+	at := flags.String("at", "", "instante RFC 3339, ahora por defecto")
+	notes := flags.String("notes", "", "notas")
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errUsage
+	}
+
+	habit, err := c.resolveHabit(positional[0])
+	if err != nil {
+		return err
+	}
+
+	slip, err := c.habits.RecordSlip(habit.ID, models.NewHabitSlipInput{
+		Timestamp: *at,
+		Notes:     *notes,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.output(slip, func() error {
+		return c.printHabitSlips(habit, []models.HabitSlip{slip})
+	})
+}
+
+// habitSlips lista las recaídas de un hábito a evitar
+func (c *cli) habitSlips(args []string) error {
+	flags := newFlags("habit slips")
+	from := flags.String("from", "", "fecha inicial (YYYY-MM-DD), hace 30 días por defecto")
+	to := flags.String("to", "", "fecha final (YYYY-MM-DD), hoy por defecto")
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errUsage
+	}
+
+	habit, err := c.resolveHabit(positional[0])
+	if err != nil {
+		return err
+	}
+
+	slips, err := c.habits.GetHabitSlips(habit.ID, *from, *to)
+	if err != nil {
+		return err
+	}
+	if slips == nil {
+		slips = []models.HabitSlip{}
+	}
+
+	return c.output(slips, func() error {
+		return c.printHabitSlips(habit, slips)
+	})
+}
+
+// printHabitSlips muestra recaídas de un hábito
+func (c *cli) printHabitSlips(habit models.Habit, slips []models.HabitSlip) error {
+	rows := make([][]string, 0, len(slips))
+	for _, s := range slips {
+		rows = append(rows, []string{
+			strconv.Itoa(s.ID), s.Date, s.Timestamp.Format("15:04"), habit.Name, s.Notes,
+		})
+	}
+	return c.printTable([]string{"ID", "FECHA", "HORA", "HÁBITO", "NOTAS"}, rows)
+}
+
+// habitDue lista los hábitos que tocan en un día según su calendario
+func (c *cli) habitDue(args []string) error {
+	flags := newFlags("habit due")
+	date := flags.String("date", "", "fecha (YYYY-MM-DD), hoy por defecto")
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return errUsage
+	}
+
+	due, err := c.habits.GetDueHabits(*date)
+	if err != nil {
+		return err
+	}
+
+	return c.output(due, func() error {
+		rows := make([][]string, 0, len(due))
+		for _, d := range due {
+			amount, progress := "", ""
+			if d.Log != nil {
+				amount = habitAmount(d.Habit, *d.Log)
+			}
+			if d.PeriodTarget > 0 {
+				progress = fmt.Sprintf("%d/%d", d.PeriodDone, d.PeriodTarget)
+			}
+			rows = append(rows, []string{
+				strconv.Itoa(d.Habit.ID), d.Habit.Name, habitSchedule(d.Habit), yesNo(d.Completed), amount, progress,
+			})
+		}
+		return c.printTable([]string{"ID", "HÁBITO", "CALENDARIO", "CUMPLIDO", "CANTIDAD", "PERÍODO"}, rows)
+	})
+}
+
+// habitGoal cambia la meta de un hábito desde una fecha; los días anteriores conservan la suya
+func (c *cli) habitGoal(args []string) error {
+	flags := newFlags("habit goal")
+	frequency := flags.String("frequency", "", "nueva frecuencia: daily, weekly o monthly")
+	goal := flags.Int("goal", 0, "nueva meta por período")
+	target := flags.Float64("target", 0, "nuevo objetivo diario en la unidad (quantity y duration)")
+	from := flags.String("from", "", "fecha desde la que rige (YYYY-MM-DD), hoy por defecto")
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 || (*frequency == "" && *goal == 0 && *target == 0) {
+		return errUsage
+	}
+
+	habit, err := c.resolveHabit(positional[0])
+	if err != nil {
+		return err
+	}
+
+	if _, err := c.habits.UpdateHabit(habit.ID, models.UpdateHabitInput{
+		Frequency:     *frequency,
+		Goal:          *goal,
+		Target:        *target,
+		EffectiveDate: *from,
+	}); err != nil {
+		return err
+	}
+
+	return c.habitGoals([]string{strconv.Itoa(habit.ID)})
+}
+
+// habitGoals lista el historial de metas de un hábito
+func (c *cli) habitGoals(args []string) error {
+	positional, err := parseFlags(newFlags("habit goals"), args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errUsage
+	}
+
+	habit, err := c.resolveHabit(positional[0])
+	if err != nil {
+		return err
+	}
+
+	versions, err := c.habits.GetHabitGoalHistory(habit.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.output(versions, func() error {
+		rows := make([][]string, 0, len(versions))
+		for _, v := range versions {
+			version := habit.WithGoal(v)
+			rows = append(rows, []string{v.EffectiveDate, habitSchedule(version), habitGoal(version)})
+		}
+		return c.printTable([]string{"DESDE", "CALENDARIO", "META"}, rows)
+	})
+}
+
+// weekdayNames son los nombres cortos de los días de la semana, en el orden de time.Weekday
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// parseWeekdays convierte una lista de días (mon,wed,fri) en la máscara 1<<time.Weekday
+func parseWeekdays(value string) (int, error) {
+	mask := 0
+	for _, name := range splitList(value) {
+		index := -1
+		for i, weekday := range weekdayNames {
+			if strings.EqualFold(name, weekday) {
+				index = i
+			}
+		}
+		if index < 0 {
+			return 0, fmt.Errorf("día de la semana inválido: %s. Usar mon, tue, wed, thu, fri, sat o sun", name)
+		}
+		mask |= 1 << index
+	}
+	return mask, nil
+}
+
+// habitSchedule describe el calendario de un hábito: daily, weekdays mon,thu, every 3d o weekly
+func habitSchedule(habit models.Habit) string {
+	switch habit.Schedule.Type {
+	case models.ScheduleWeekdays:
+		var days []string
+		for i, name := range weekdayNames {
+			if habit.Schedule.Weekdays&(1<<i) != 0 {
+				days = append(days, name)
+			}
+		}
+		return "weekdays " + strings.Join(days, ",")
+	case models.ScheduleInterval:
+		return fmt.Sprintf("every %dd", habit.Schedule.Interval)
+	case "":
+		return habit.Frequency
+	default:
+		return habit.Schedule.Type
+	}
+}
+
+// habitGoal muestra el objetivo de un hábito: veces por período o cantidad diaria con su unidad
+func habitGoal(habit models.Habit) string {
+	if habit.Avoid {
+		return "evitar"
+	}
+	if habit.Quantitative() {
+		return strconv.FormatFloat(habit.Target, 'f', -1, 64) + " " + habit.Unit
+	}
+	return strconv.Itoa(habit.Goal)
+}
+
+// habitAmount muestra lo registrado en un día: el valor con su unidad o el número de veces
+func habitAmount(habit models.Habit, log models.HabitLog) string {
+	if habit.Quantitative() {
+		return strconv.FormatFloat(log.Value, 'f', -1, 64) + " " + habit.Unit
+	}
+	return strconv.Itoa(log.Count)
+}
+
+// resolveHabit busca un hábito por ID o por nombre (sin distinguir mayúsculas)
+func (c *cli) resolveHabit(ref string) (models.Habit, error) {
+	if id, err := strconv.Atoi(ref); err == nil {
+		return c.habits.GetHabit(id)
+	}
+
+	habits, err := c.habits.GetAllHabits()
+	if err != nil {
+		return models.Habit{}, err
+	}
+
+	for _, h := range habits {
+		if strings.EqualFold(h.Name, ref) {
+			return h, nil
+		}
+	}
+
+	return models.Habit{}, fmt.Errorf("hábito no encontrado: %s", ref)
+}
+
+// ==================== DÍAS JUSTIFICADOS ====================
+
+// excuseAdd justifica un día o un rango de días, para un hábito o para todos
+func (c *cli) excuseAdd(args []string) error {
+	flags := newFlags("excuse add")
+	habitRef := flags.String("habit", "", "hábito (todos por defecto)")
+	from := flags.String("from", "", "primer día (YYYY-MM-DD), hoy por defecto")
+	to := flags.String("to", "", "último día (YYYY-MM-DD), el primero por defecto")
+	reason := flags.String("reason", models.ExcuseOther, "motivo: vacation, sick, rest u other")
+	notes := flags.String("notes", "", "notas")
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return errUsage
+	}
+
+	input := models.NewExcuseInput{
+		StartDate: *from,
+		EndDate:   *to,
+		Reason:    *reason,
+		Notes:     *notes,
+	}
+	if input.StartDate == "" {
+		input.StartDate = c.habits.Repo.Today()
+	}
+	if *habitRef != "" {
+		habit, err := c.resolveHabit(*habitRef)
+		if err != nil {
+			return err
+		}
+		input.HabitID = &habit.ID
+	}
+
+	excuse, err := c.habits.CreateExcuse(input)
+	if err != nil {
+		return err
+	}
+
+	return c.output(excuse, func() error {
+		return c.printExcuses([]models.Excuse{excuse})
+	})
+}
+
+// excuseList lista los días justificados de un rango
+func (c *cli) excuseList(args []string) error {
+	flags := newFlags("excuse list")
+	habitRef := flags.String("habit", "", "hábito (todos por defecto)")
+	from := flags.String("from", "", "fecha inicial (YYYY-MM-DD), hace 30 días por defecto")
+	to := flags.String("to", "", "fecha final (YYYY-MM-DD), dentro de 30 días por defecto")
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return errUsage
+	}
+
+	habitID := 0
+	if *habitRef != "" {
+		habit, err := c.resolveHabit(*habitRef)
+		if err != nil {
+			return err
+		}
+		habitID = habit.ID
+	}
+
+	excuses, err := c.habits.GetExcuses(habitID, *from, *to)
+	if err != nil {
+		return err
+	}
+	if excuses == nil {
+		excuses = []models.Excuse{}
+	}
+
+	return c.output(excuses, func() error {
+		return c.printExcuses(excuses)
+	})
+}
+
+// excuseDelete elimina un día justificado
+func (c *cli) excuseDelete(args []string) error {
+	positional, err := parseFlags(newFlags("excuse delete"), args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errUsage
+	}
+
+	id, err := strconv.Atoi(positional[0])
+	if err != nil {
+		return fmt.Errorf("ID inválido: %s", positional[0])
+	}
+
+	if err := c.habits.DeleteExcuse(id); err != nil {
+		return err
+	}
+
+	return c.output(map[string]int{"deleted": id}, func() error {
+		_, err := fmt.Fprintf(c.out, "Día justificado %d eliminado\n", id)
+		return err
+	})
+}
+
+// printExcuses muestra días justificados con el hábito al que afectan
+func (c *cli) printExcuses(excuses []models.Excuse) error {
+	rows := make([][]string, 0, len(excuses))
+	for _, e := range excuses {
+		habit := "todos"
+		if e.HabitID != nil {
+			habit = strconv.Itoa(*e.HabitID)
+			if h, err := c.habits.GetHabit(*e.HabitID); err == nil {
+				habit = h.Name
+			}
+		}
+		rows = append(rows, []string{
+			strconv.Itoa(e.ID), e.StartDate, e.EndDate, habit, e.Reason, e.Notes,
+		})
+	}
+	return c.printTable([]string{"ID", "DESDE", "HASTA", "HÁBITO", "MOTIVO", "NOTAS"}, rows)
+}
+
+// ==================== ESTADO DE ÁNIMO ====================
+
+// moodAdd registra el estado de ánimo de un día
+func (c *cli) moodAdd(args []string) error {
+	flags := newFlags("mood add")
+	date := flags.String("date", "", "fecha (YYYY-MM-DD), hoy por defecto")
+	at := flags.String("at", "", "momento del registro (RFC 3339); la fecha es la de su día")
+	slot := flags.String("slot", "", "franja del día: morning, afternoon, evening o night")
+	score := flags.Int("score", 0, "puntuación de 1 a 10")
+	energy := flags.Int("energy", 0, "nivel de energía de 1 a 10")
+	anxiety := flags.Int("anxiety", 0, "nivel de ansiedad de 1 a 10")
+	stress := flags.Int("stress", 0, "nivel de estrés de 1 a 10")
+	sleep := flags.Float64("sleep", 0, "horas de sueño")
+	notes := flags.String("notes", "", "notas")
+	tags := flags.String("tags", "", "etiquetas separadas por comas")
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return errUsage
+	}
+
+	input := models.NewMoodEntryInput{
+		Date:         *date,
+		Timestamp:    *at,
+		Slot:         *slot,
+		MoodScore:    *score,
+		EnergyLevel:  *energy,
+		AnxietyLevel: *anxiety,
+		StressLevel:  *stress,
+		SleepHours:   *sleep,
+		Notes:        *notes,
+		Tags:         splitList(*tags),
+	}
+
+	entry, err := c.mood.CreateMoodEntry(input)
+	if err != nil {
+		return err
+	}
+
+	return c.printMoodEntries([]models.MoodEntry{entry})
+}
+
+// moodList lista los registros de estado de ánimo
+func (c *cli) moodList(args []string) error {
+	flags := newFlags("mood list")
+	from := flags.String("from", "", "fecha inicial (YYYY-MM-DD), hace 30 días por defecto")
+	to := flags.String("to", "", "fecha final (YYYY-MM-DD), hoy por defecto")
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return errUsage
+	}
+
+	entries, err := c.mood.GetAllMoodEntries(*from, *to)
+	if err != nil {
+		return err
+	}
+
+	return c.printMoodEntries(entries)
+}
+
+// moodDaily muestra el estado de ánimo agregado de cada día
+func (c *cli) moodDaily(args []string) error {
+	flags := newFlags("mood daily")
+	from := flags.String("from", "", "fecha inicial (YYYY-MM-DD), hace 30 días por defecto")
+	to := flags.String("to", "", "fecha final (YYYY-MM-DD), hoy por defecto")
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return errUsage
+	}
+
+	days, err := c.mood.GetDailyMoods(*from, *to)
+	if err != nil {
+		return err
+	}
+
+	return c.output(days, func() error {
+		rows := make([][]string, 0, len(days))
+		for _, d := range days {
+			rows = append(rows, []string{
+				d.Date, strconv.Itoa(d.Entries), formatValue(d.MoodScore.Mean), formatValue(d.MoodScore.Min),
+				formatValue(d.MoodScore.Max), formatValue(d.MoodScore.Last), formatAggregateMean(d.EnergyLevel),
+				formatAggregateMean(d.AnxietyLevel), formatAggregateMean(d.StressLevel), formatValue(d.SleepHours),
+			})
+		}
+		return c.printTable([]string{"FECHA", "REGISTROS", "ÁNIMO", "MÍN", "MÁX", "ÚLTIMO", "ENERGÍA", "ANSIEDAD", "ESTRÉS", "SUEÑO"}, rows)
+	})
+}
+
+// formatAggregateMean muestra la media de una dimensión opcional, o nada si no se registró
+func formatAggregateMean(aggregate *models.MoodAggregate) string {
+	if aggregate == nil {
+		return ""
+	}
+	return formatValue(aggregate.Mean)
+}
+
+// printMoodEntries muestra registros de estado de ánimo
+func (c *cli) printMoodEntries(entries []models.MoodEntry) error {
+	if entries == nil {
+		entries = []models.MoodEntry{}
+	}
+
+	return c.output(entries, func() error {
+		rows := make([][]string, 0, len(entries))
+		for _, m := range entries {
+			rows = append(rows, []string{
+				m.Date.Format("2006-01-02"), moodMoment(m), strconv.Itoa(m.MoodScore), strconv.Itoa(m.EnergyLevel),
+				strconv.Itoa(m.AnxietyLevel), strconv.Itoa(m.StressLevel), formatValue(m.SleepHours),
+				strings.Join(m.Tags, ","), m.Notes,
+			})
+		}
+		return c.printTable([]string{"FECHA", "MOMENTO", "ÁNIMO", "ENERGÍA", "ANSIEDAD", "ESTRÉS", "SUEÑO", "ETIQUETAS", "NOTAS"}, rows)
+	})
+}
+
+// moodMoment muestra la hora o la franja de un registro de estado de ánimo
+func moodMoment(m models.MoodEntry) string {
+	if m.Timestamp != nil {
+		return m.Timestamp.Format("15:04")
+	}
+	return m.Slot
+}
+
+// ==================== CAFEÍNA ====================
+
+// caffeineBeverages lista las bebidas con cafeína activas
+func (c *cli) caffeineBeverages(args []string) error {
+	flags := newFlags("caffeine beverages")
+	all := flags.Bool("all", false, "incluir bebidas inactivas")
+
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	beverages, err := c.caffeine.GetAllCaffeineBeverages(*all)
+	if err != nil {
+		return err
+	}
+
+	return c.output(beverages, func() error {
+		rows := make([][]string, 0, len(beverages))
+		for _, b := range beverages {
+			rows = append(rows, []string{
+				strconv.Itoa(b.ID), b.Name, b.Category, formatValue(b.CaffeineContent), b.StandardUnit,
+			})
+		}
+		return c.printTable([]string{"ID", "NOMBRE", "CATEGORÍA", "MG/UNIDAD", "UNIDAD"}, rows)
+	})
+}
+
+// caffeineAdd registra un consumo de cafeína
+func (c *cli) caffeineAdd(args []string) error {
+	flags := newFlags("caffeine add")
+	amount := flags.Float64("amount", 1, "cantidad en unidades estándar de la bebida")
+	unit := flags.String("unit", "", "unidad, la estándar de la bebida por defecto")
+	at := flags.String("at", "", "instante RFC 3339, ahora por defecto")
+	notes := flags.String("notes", "", "notas")
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errUsage
+	}
+
+	beverage, err := c.resolveBeverage(positional[0])
+	if err != nil {
+		return err
+	}
+
+	if *unit == "" {
+		*unit = beverage.StandardUnit
+	}
+
+	result, err := c.caffeine.CreateCaffeineIntake(models.NewCaffeineIntakeInput{
+		Timestamp:  *at,
+		BeverageID: beverage.ID,
+		Amount:     *amount,
+		Unit:       *unit,
+		Notes:      *notes,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.output(result, func() error {
+		if err := c.printIntakes([]models.CaffeineIntake{result.CaffeineIntake}); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(c.out, "\nTotal del día: %.0f mg", result.Budget.DailyTotal)
+		if result.Budget.Limits.DailyLimit > 0 {
+			fmt.Fprintf(c.out, " (quedan %.0f mg)", result.Budget.DailyRemaining)
+		}
+		fmt.Fprintln(c.out)
+
+		for _, limit := range result.LimitsCrossed {
+			fmt.Fprintf(c.out, "Atención: se ha superado el límite %s\n", limit)
+		}
+		return nil
+	})
+}
+
+// caffeineList lista los consumos de cafeína
+func (c *cli) caffeineList(args []string) error {
+	flags := newFlags("caffeine list")
+	today := c.habits.Repo.Today()
+	from := flags.String("from", today, "fecha inicial (YYYY-MM-DD)")
+	to := flags.String("to", today, "fecha final (YYYY-MM-DD)")
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return errUsage
+	}
+
+	intakes, err := c.caffeine.GetCaffeineIntakeRange(*from, *to)
+	if err != nil {
+		return err
+	}
+
+	return c.output(intakes, func() error {
+		return c.printIntakes(intakes)
+	})
+}
+
+// printIntakes muestra consumos de cafeína como tabla
+func (c *cli) printIntakes(intakes []models.CaffeineIntake) error {
+	rows := make([][]string, 0, len(intakes))
+	for _, i := range intakes {
+		rows = append(rows, []string{
+			strconv.Itoa(i.ID), i.Timestamp.Format("2006-01-02 15:04"), i.BeverageName,
+			formatValue(i.Amount) + " " + i.Unit, formatValue(i.TotalCaffeine), i.Notes,
+		})
+	}
+	return c.printTable([]string{"ID", "MOMENTO", "BEBIDA", "CANTIDAD", "MG", "NOTAS"}, rows)
+}
+
+// resolveBeverage busca una bebida por ID o por nombre (sin distinguir mayúsculas)
+func (c *cli) resolveBeverage(ref string) (models.CaffeineBeverage, error) {
+	if id, err := strconv.Atoi(ref); err == nil {
+		return c.caffeine.GetCaffeineBeverage(id)
+	}
+
+	beverages, err := c.caffeine.GetAllCaffeineBeverages(false)
+	if err != nil {
+		return models.CaffeineBeverage{}, err
+	}
+
+	for _, b := range beverages {
+		if strings.EqualFold(b.Name, ref) {
+			return b, nil
+		}
+	}
+
+	return models.CaffeineBeverage{}, fmt.Errorf("bebida no encontrada: %s", ref)
+}
+
+// ==================== ESTADÍSTICAS ====================
+
+// statsHabit muestra las estadísticas de un hábito
+func (c *cli) statsHabit(args []string) error {
+	flags := newFlags("stats habit")
+	period := flags.String("period", "month", "período: month, 30d, last-month, 2025-Q3, 2025-01-01..2025-03-31...")
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errUsage
+	}
+
+	habit, err := c.resolveHabit(positional[0])
+	if err != nil {
+		return err
+	}
+
+	stats, err := c.stats.GetHabitStats(habit.ID, *period)
+	if err != nil {
+		return err
+	}
+
+	return c.output(stats, func() error { return c.printFields(stats) })
+}
+
+// statsStreaks muestra las rachas de un hábito
+func (c *cli) statsStreaks(args []string) error {
+	positional, err := parseFlags(newFlags("stats streaks"), args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errUsage
+	}
+
+	habit, err := c.resolveHabit(positional[0])
+	if err != nil {
+		return err
+	}
+
+	streaks, err := c.stats.GetHabitStreaks(habit.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.output(streaks, func() error {
+		fmt.Fprintf(c.out, "%s: racha actual %d, racha más larga %d (%s)\n\n",
+			streaks.HabitName, streaks.CurrentStreak, streaks.LongestStreak, streaks.Unit)
+
+		rows := make([][]string, 0, len(streaks.Runs))
+		for _, run := range streaks.Runs {
+			rows = append(rows, []string{run.StartDate, run.EndDate, strconv.Itoa(run.Length)})
+		}
+		return c.printTable([]string{"INICIO", "FIN", "DURACIÓN"}, rows)
+	})
+}
+
+// statsMood muestra las estadísticas del estado de ánimo
+func (c *cli) statsMood(args []string) error {
+	return c.periodStats("stats mood", args, func(period string) (interface{}, error) {
+		return c.stats.GetMoodStats(period)
+	})
+}
+
+// statsCaffeine muestra las estadísticas del consumo de cafeína
+func (c *cli) statsCaffeine(args []string) error {
+	return c.periodStats("stats caffeine", args, func(period string) (interface{}, error) {
+		return c.stats.GetCaffeineStats(period)
+	})
+}
+
+// statsImpact muestra el efecto de cada hábito activo sobre el estado de ánimo
+func (c *cli) statsImpact(args []string) error {
+	flags := newFlags("stats impact")
+	days := flags.Int("days", 0, "ventana de análisis en días (90 por defecto)")
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return errUsage
+	}
+
+	analysis, err := c.stats.GetHabitMoodCorrelations(*days)
+	if err != nil {
+		return err
+	}
+
+	return c.output(analysis, func() error {
+		fmt.Fprintf(c.out, "Del %s al %s\n\n", analysis.StartDate, analysis.EndDate)
+
+		var rows [][]string
+		for _, habit := range analysis.Habits {
+			for _, effect := range habit.Effects {
+				if effect.Confidence == models.ConfidenceInsufficient {
+					continue
+				}
+				rows = append(rows, []string{
+					habit.HabitName,
+					effect.Dimension,
+					fmt.Sprintf("%d/%d", effect.CompletedDays, effect.NotCompletedDays),
+					fmt.Sprintf("%+.2f", effect.MeanDifference),
+					fmt.Sprintf("[%.2f, %.2f]", effect.CILow, effect.CIHigh),
+					fmt.Sprintf("%+.2f", effect.EffectSize),
+					fmt.Sprintf("%.3f", effect.PValue),
+					effect.Confidence,
+				})
+			}
+		}
+		return c.printTable([]string{"HÁBITO", "DIMENSIÓN", "DÍAS (SÍ/NO)", "DIFERENCIA", "IC 95%", "D", "P", "CONFIANZA"}, rows)
+	})
+}
+
+// statsLagged muestra la matriz de correlaciones (Pearson) con desfase
+func (c *cli) statsLagged(args []string) error {
+	flags := newFlags("stats lagged")
+	days := flags.Int("days", 0, "ventana de análisis en días (90 por defecto)")
+	maxLag := flags.Int("lag", models.DefaultCorrelationMaxLag, "desfase máximo en días")
+	cutoff := flags.Int("cutoff", models.DefaultCaffeineCutoff, "hora a partir de la cual la cafeína es tardía")
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return errUsage
+	}
+
+	matrix, err := c.stats.GetLaggedCorrelations(models.LaggedCorrelationOptions{
+		WindowDays: *days,
+		MaxLag:     maxLag,
+		CutoffHour: cutoff,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.output(matrix, func() error {
+		fmt.Fprintf(c.out, "Del %s al %s\n\n", matrix.StartDate, matrix.EndDate)
+
+		header := []string{"VARIABLE", "DIMENSIÓN"}
+		for lag := 0; lag <= matrix.MaxLag; lag++ {
+			header = append(header, fmt.Sprintf("D+%d", lag))
+		}
+
+		rows := make([][]string, 0, len(matrix.Rows))
+		for _, row := range matrix.Rows {
+			cells := []string{row.InputLabel, row.Outcome}
+			for _, lag := range row.Lags {
+				if lag.Pearson == nil {
+					cells = append(cells, "-")
+					continue
+				}
+				cells = append(cells, fmt.Sprintf("%+.2f (n=%d)", *lag.Pearson, lag.SampleSize))
+			}
+			rows = append(rows, cells)
+		}
+		return c.printTable(header, rows)
+	})
+}
+
+// periodStats muestra unas estadísticas que solo dependen del período
+func (c *cli) periodStats(name string, args []string, get func(period string) (interface{}, error)) error {
+	flags := newFlags(name)
+	period := flags.String("period", "month", "período: month, 30d, last-month, 2025-Q3, 2025-01-01..2025-03-31...")
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return errUsage
+	}
+
+	stats, err := get(*period)
+	if err != nil {
+		return err
+	}
+
+	return c.output(stats, func() error { return c.printFields(stats) })
+}
+
+// splitList separa una lista de valores separados por comas, ignorando los vacíos
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,168 @@
+// Command habitctl es un cliente de línea de comandos para la base de datos de Habit Tracker.
+// Permite registrar hábitos, estado de ánimo y cafeína, y consultar datos y estadísticas sin
+// abrir la interfaz gráfica.
+//
+// Uso:
+//
+//	habitctl [-db ruta] [-json] <grupo> <comando> [opciones] [argumentos]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/kubaliski/habit-tracker/backend/api"
+	"github.com/kubaliski/habit-tracker/backend/database"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const usage = `Uso: habitctl [-db ruta] [-json] [-v] <grupo> <comando> [opciones] [argumentos]
+
+Hábitos:
+  habit list                                 Lista los hábitos
+  habit add <nombre> [-frequency F] [-goal N] [-category C] [-description T]
+            [-measure M] [-unit U] [-target X] [-avoid]
+            [-schedule S] [-days mon,thu] [-every N] [-anchor D] [-times N]
+                                             Crea un hábito (M es boolean, count, quantity
+                                             o duration; X es el objetivo diario en U;
+                                             -avoid para un hábito a evitar; S es daily,
+                                             weekdays, interval, weekly o monthly)
+  habit complete <hábito> [-date D]          Marca un hábito como completado
+  habit uncomplete <hábito> [-date D]        Marca un hábito como no completado
+  habit log <hábito> [-date D] [-count N | -value X] [-done] [-notes T]
+                                             Registra una entrada de un hábito
+  habit logs <hábito> [-from D] [-to D]      Lista los registros de un hábito
+  habit slip <hábito> [-at T] [-notes T]     Registra una recaída en un hábito a evitar
+  habit slips <hábito> [-from D] [-to D]     Lista las recaídas de un hábito a evitar
+  habit due [-date D]                        Lista los hábitos que tocan en un día
+  habit goal <hábito> [-goal N] [-target X] [-frequency F] [-from D]
+                                             Cambia la meta desde una fecha (hoy por
+                                             defecto); los días anteriores conservan la suya
+  habit goals <hábito>                       Historial de metas de un hábito
+
+Días justificados (no rompen rachas ni cuentan en el cumplimiento):
+  excuse add [-habit H] [-from D] [-to D] [-reason R] [-notes T]
+                                             Justifica uno o varios días, de un hábito o de
+                                             todos (R es vacation, sick, rest u other)
+  excuse list [-habit H] [-from D] [-to D]   Lista los días justificados
+  excuse delete <id>                         Elimina un día justificado
+
+Estado de ánimo:
+  mood add -score N [-date D | -at T] [-slot S] [-energy N] [-anxiety N] [-stress N] [-sleep H]
+           [-notes T] [-tags a,b]           Registra el estado de ánimo (se admiten varios por día;
+                                             S es morning, afternoon, evening o night)
+  mood list [-from D] [-to D]                Lista los registros de estado de ánimo
+  mood daily [-from D] [-to D]               Media, mínimo, máximo y último valor de cada día
+
+Cafeína:
+  caffeine beverages                         Lista las bebidas con cafeína
+  caffeine add <bebida> [-amount N] [-unit U] [-at T] [-notes T]
+                                             Registra un consumo de cafeína
+  caffeine list [-from D] [-to D]            Lista los consumos de cafeína
+
+Estadísticas:
+  stats habit <hábito> [-period P]           Estadísticas de un hábito
+  stats streaks <hábito>                     Rachas de un hábito
+  stats mood [-period P]                     Estadísticas del estado de ánimo
+  stats caffeine [-period P]                 Estadísticas del consumo de cafeína
+  stats impact [-days N]                     Efecto de cada hábito sobre el estado de ánimo
+  stats lagged [-days N] [-lag N] [-cutoff H]
+                                             Correlaciones con el estado de ánimo de días posteriores
+
+Ajustes:
+  settings timezone [zona] [-rollover H]     Muestra o cambia la zona horaria (IANA, p. ej.
+                                             Europe/Madrid; "system" usa la del sistema) y la
+                                             hora a la que empieza el día (0 = medianoche)
+
+Servidor REST:
+  server start [-addr host:puerto]           Arranca el servidor REST local hasta recibir Ctrl+C
+  server token [-regenerate]                 Muestra (o regenera) el token de acceso
+
+Los hábitos y bebidas se indican por ID o por nombre. Las fechas usan YYYY-MM-DD y los
+instantes RFC 3339 (2006-01-02T15:04:05+01:00). Los instantes se asignan al día según la
+zona horaria y la hora de cambio de día configuradas.
+
+Los períodos de las estadísticas pueden ser ventanas móviles (week, month, year, 30d, 12w),
+períodos naturales (this-week, last-month, this-quarter, last-year, 2025, 2025-Q3, Q3,
+2025-03, 2025-W07) o rangos explícitos (2025-01-01..2025-03-31).
+`
+
+// cli agrupa los controladores y las opciones globales
+type cli struct {
+	habits   *api.HabitController
+	mood     *api.MoodController
+	caffeine *api.CaffeineController
+	stats    *api.StatsController
+	server   *api.ServerController
+	settings *api.SettingsController
+	out      io.Writer
+	json     bool
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run ejecuta habitctl y devuelve el código de salida
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("habitctl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { fmt.Fprint(stderr, usage) }
+
+	dbPath := flags.String("db", defaultDBPath(), "ruta de la base de datos")
+	jsonOutput := flags.Bool("json", false, "salida en formato JSON")
+	verbose := flags.Bool("v", false, "mostrar mensajes de la base de datos")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() < 2 {
+		flags.Usage()
+		return 2
+	}
+
+	// El repositorio informa de migraciones e inicialización por el log estándar
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	repo, err := database.NewSQLiteRepo(*dbPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "habitctl: %v\n", err)
+		return 1
+	}
+	defer repo.Close()
+
+	c := &cli{
+		habits:   api.NewHabitController(repo),
+		mood:     api.NewMoodController(repo),
+		caffeine: api.NewCaffeineController(repo),
+		stats:    api.NewStatsController(repo),
+		server:   api.NewServerController(repo),
+		settings: api.NewSettingsController(repo),
+		out:      stdout,
+		json:     *jsonOutput,
+	}
+
+	if err := c.dispatch(flags.Arg(0), flags.Arg(1), flags.Args()[2:]); err != nil {
+		fmt.Fprintf(stderr, "habitctl: %v\n", err)
+		if err == errUsage {
+			fmt.Fprint(stderr, usage)
+			return 2
+		}
+		return 1
+	}
+
+	return 0
+}
+
+// defaultDBPath devuelve la misma ruta que usa la aplicación de escritorio
+func defaultDBPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".habit-tracker", "habits.db")
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// printJSON escribe un valor como JSON indentado
+func (c *cli) printJSON(v interface{}) error {
+	encoder := json.NewEncoder(c.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// printTable escribe filas alineadas en columnas, con una cabecera
+func (c *cli) printTable(header []string, rows [][]string) error {
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// printFields escribe unas estadísticas como tabla clave-valor ordenada, usando los
+// nombres de campo JSON
+func (c *cli) printFields(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	rows := make([][]string, 0, len(keys))
+	for _, k := range keys {
+		rows = append(rows, []string{k, formatValue(m[k])})
+	}
+
+	return c.printTable([]string{"CLAVE", "VALOR"}, rows)
+}
+
+// output escribe v como JSON o, en modo tabla, llama a table
+func (c *cli) output(v interface{}, table func() error) error {
+	if c.json {
+		return c.printJSON(v)
+	}
+	return table()
+}
+
+// formatValue convierte un valor de estadísticas en texto; los valores compuestos se
+// muestran como JSON compacto
+func formatValue(v interface{}) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case float64:
+		if value == math.Trunc(value) {
+			return fmt.Sprintf("%.0f", value)
+		}
+		return fmt.Sprintf("%.2f", value)
+	case int, int64, bool:
+		return fmt.Sprint(value)
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return string(data)
+	}
+}
+
+// yesNo formatea un booleano para las tablas
+func yesNo(b bool) string {
+	if b {
+		return "sí"
+	}
+	return "no"
+}
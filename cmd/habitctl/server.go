@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/kubaliski/habit-tracker/backend/models"
+	"github.com/kubaliski/habit-tracker/backend/server"
+)
+
+// serverStart arranca el servidor REST sin interfaz gráfica hasta recibir una señal
+func (c *cli) serverStart(args []string) error {
+	flags := newFlags("server start")
+	addr := flags.String("addr", "", "dirección host:puerto, la configurada por defecto")
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return errUsage
+	}
+
+	settings, err := c.server.GetServerSettings()
+	if err != nil {
+		return err
+	}
+	if *addr != "" {
+		settings.Address = *addr
+	}
+
+	restServer := server.New(server.Controllers{
+		Habits:   c.habits,
+		Mood:     c.mood,
+		Caffeine: c.caffeine,
+		Stats:    c.stats,
+	}, settings.Address, settings.Token)
+
+	if err := restServer.Start(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "Servidor REST en http://%s/api\n", restServer.Addr())
+	fmt.Fprintf(c.out, "Cabecera de autenticación: Authorization: Bearer %s\n", settings.Token)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	<-signals
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return restServer.Stop(ctx)
+}
+
+// serverToken muestra el token de acceso del servidor REST, regenerándolo si se pide
+func (c *cli) serverToken(args []string) error {
+	flags := newFlags("server token")
+	regenerate := flags.Bool("regenerate", false, "invalidar el token actual y crear uno nuevo")
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return errUsage
+	}
+
+	var settings models.ServerSettings
+	if *regenerate {
+		settings, err = c.server.UpdateServerSettings(models.UpdateServerSettingsInput{RegenerateToken: true})
+	} else {
+		settings, err = c.server.GetServerSettings()
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.output(settings, func() error {
+		_, err := fmt.Fprintln(c.out, settings.Token)
+		return err
+	})
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"github.com/kubaliski/habit-tracker/backend/models"
+)
+
+// settingsTimezone muestra la zona horaria y la hora de cambio de día con las que se asignan
+// los registros a días, o las cambia
+func (c *cli) settingsTimezone(args []string) error {
+	flags := newFlags("settings timezone")
+	rollover := flags.Int("rollover", -1, "hora local a la que empieza el día (0 = medianoche)")
+
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 1 {
+		return errUsage
+	}
+
+	var input models.UpdateTimeSettingsInput
+	if len(positional) == 1 {
+		name := positional[0]
+		if name == "system" {
+			name = ""
+		}
+		input.Timezone = &name
+	}
+	if *rollover >= 0 {
+		input.RolloverHour = rollover
+	}
+
+	var settings models.TimeSettings
+	if input.Timezone != nil || input.RolloverHour != nil {
+		settings, err = c.settings.UpdateTimeSettings(input)
+	} else {
+		settings, err = c.settings.GetTimeSettings()
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.printFields(settings)
+}
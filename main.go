@@ -33,6 +33,10 @@ func main() {
 			app.moodAPI,
 			app.caffeineAPI,
 			app.statsAPI,
+			app.exportAPI,
+			app.backupAPI,
+			app.serverAPI,
+			app.settingsAPI,
 		},
 	})
 